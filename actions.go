@@ -0,0 +1,124 @@
+package gwda
+
+import "strconv"
+
+// The W3C codes of the special keys, for WDAActionOptionKey
+const (
+	WDAKeyBackspace  = "\uE003"
+	WDAKeyTab        = "\uE004"
+	WDAKeyReturn     = "\uE006"
+	WDAKeyEnter      = "\uE007"
+	WDAKeyShift      = "\uE008"
+	WDAKeyControl    = "\uE009"
+	WDAKeyAlt        = "\uE00A" // Option
+	WDAKeyEscape     = "\uE00C"
+	WDAKeyArrowLeft  = "\uE012"
+	WDAKeyArrowUp    = "\uE013"
+	WDAKeyArrowRight = "\uE014"
+	WDAKeyArrowDown  = "\uE015"
+	WDAKeyDelete     = "\uE017"
+	WDAKeyMeta       = "\uE03D" // Command
+)
+
+// WDAActionOptionKey
+//
+// The steps of a key input source, e.g. a shortcut of a hardware keyboard on iPad which SendKeys can't type.
+//
+//	keys := NewWDAActionOptionKey().Chord("a", WDAKeyMeta).Pause(0.2).Press(WDAKeyBackspace)
+//	err := s.PerformActions(NewWDAActions().KeyActionOption(keys))
+type WDAActionOptionKey []wdaBody
+
+func NewWDAActionOptionKey(cap ...int) *WDAActionOptionKey {
+	if len(cap) == 0 || cap[0] <= 0 {
+		cap = []int{8}
+	}
+	tmp := make(WDAActionOptionKey, 0, cap[0])
+	return &tmp
+}
+
+// Down holds `key`, one character or a WDAKey constant
+func (aok *WDAActionOptionKey) Down(key string) *WDAActionOptionKey {
+	*aok = append(*aok, newWdaBody().set("type", "keyDown").set("value", key))
+	return aok
+}
+
+func (aok *WDAActionOptionKey) Up(key string) *WDAActionOptionKey {
+	*aok = append(*aok, newWdaBody().set("type", "keyUp").set("value", key))
+	return aok
+}
+
+// Press Down and Up
+func (aok *WDAActionOptionKey) Press(key string) *WDAActionOptionKey {
+	return aok.Down(key).Up(key)
+}
+
+// Type presses each character of `text`
+func (aok *WDAActionOptionKey) Type(text string) *WDAActionOptionKey {
+	for _, r := range text {
+		aok.Press(string(r))
+	}
+	return aok
+}
+
+// Chord
+//
+// types `text` while the `modifiers` (e.g. WDAKeyShift, WDAKeyMeta) are held, they are released in reverse order
+func (aok *WDAActionOptionKey) Chord(text string, modifiers ...string) *WDAActionOptionKey {
+	for _, modifier := range modifiers {
+		aok.Down(modifier)
+	}
+	aok.Type(text)
+	for i := len(modifiers) - 1; i >= 0; i-- {
+		aok.Up(modifiers[i])
+	}
+	return aok
+}
+
+// Pause in seconds, like WDAActionOptionFinger.Pause
+func (aok *WDAActionOptionKey) Pause(duration ...float64) *WDAActionOptionKey {
+	if len(duration) == 0 || duration[0] < 0 {
+		duration = []float64{0.5}
+	}
+	*aok = append(*aok, newWdaBody().set("type", "pause").set("duration", duration[0]*1000))
+	return aok
+}
+
+// KeyActionOption
+//
+// adds a key input source, SendKeys is a shortcut for the plain text
+func (act *WDAActions) KeyActionOption(actOptKey *WDAActionOptionKey) *WDAActions {
+	keyboard := act._newTypeForKeyboard()
+	keyboard.set("actions", *actOptKey)
+	*act = append(*act, keyboard)
+	return act
+}
+
+// PauseActionOption
+//
+// Adds an input source doing nothing for the given `durations` (in seconds), one per tick.
+// The sources advance tick by tick together: its pauses delay the steps of the other sources at the same ticks.
+func (act *WDAActions) PauseActionOption(durations ...float64) *WDAActions {
+	source := newWdaBody().set("type", "none")
+	source.set("id", "none"+strconv.FormatInt(int64(len(*act)+1), 10))
+	pauses := make([]wdaBody, 0, len(durations))
+	for _, duration := range durations {
+		pauses = append(pauses, newWdaBody().set("type", "pause").set("duration", duration*1000))
+	}
+	source.set("actions", pauses)
+	*act = append(*act, source)
+	return act
+}
+
+// SetOriginViewport
+//
+// the coordinates are relative to the screen, the default
+func (ofm WDAActionOptionFingerMove) SetOriginViewport() WDAActionOptionFingerMove {
+	return WDAActionOptionFingerMove(wdaBody(ofm).set("origin", "viewport"))
+}
+
+// SetOriginPointer
+//
+// the coordinates are an offset from the current position of the pointer, e.g. to drag by a distance
+func (ofm WDAActionOptionFingerMove) SetOriginPointer() WDAActionOptionFingerMove {
+	return WDAActionOptionFingerMove(wdaBody(ofm).set("origin", "pointer"))
+}
@@ -0,0 +1,35 @@
+package gwda
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWDAActions_KeyActionOption(t *testing.T) {
+	keys := NewWDAActionOptionKey().Chord("a", WDAKeyMeta).Pause(0.2)
+	finger := NewWDAActionOptionFinger().
+		Move(NewWWDAActionOptionFingerMove().SetXY(10, 0).SetOriginPointer()).
+		Down().
+		Up()
+	actions := NewWDAActions().KeyActionOption(keys).FingerActionOption(finger).PauseActionOption(0.1, 0.3)
+
+	bs, err := json.Marshal(actions)
+	checkErr(t, err)
+	want := `[{"actions":[{"type":"keyDown","value":"META"},{"type":"keyDown","value":"a"},{"type":"keyUp","value":"a"},{"type":"keyUp","value":"META"},{"duration":200,"type":"pause"}],"id":"keyboard1","type":"key"},` +
+		`{"actions":[{"origin":"pointer","type":"pointerMove","x":10,"y":0},{"type":"pointerDown"},{"type":"pointerUp"}],"id":"finger2","parameters":{"pointerType":"touch"},"type":"pointer"},` +
+		`{"actions":[{"duration":100,"type":"pause"},{"duration":300,"type":"pause"}],"id":"none3","type":"none"}]`
+	if want = strings.Replace(want, "META", WDAKeyMeta, -1); string(bs) != want {
+		t.Fatal(string(bs))
+	}
+}
+
+func TestSession_PerformActions_keys(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+
+	keys := NewWDAActionOptionKey().Type("gwda").Pause(0.2).Chord("a", WDAKeyMeta).Press(WDAKeyBackspace)
+	checkErr(t, s.PerformActions(NewWDAActions().KeyActionOption(keys)))
+}
@@ -0,0 +1,458 @@
+package gwda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrAppNotInstalled returned (wrapped) by AppLaunch, AppLaunchSafe and NewSession
+var ErrAppNotInstalled = errors.New("app not installed")
+
+// appNotInstalledError wraps ErrAppNotInstalled into the launch errors of WDA saying so
+func appNotInstalledError(bundleId string, err error) error {
+	if err == nil || errors.Is(err, ErrAppNotInstalled) {
+		return err
+	}
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "not installed") || strings.Contains(msg, "correct bundle identifier") ||
+		strings.Contains(msg, "unable to find the application") {
+		return fmt.Errorf("launch '%s': %w: %v", bundleId, ErrAppNotInstalled, err)
+	}
+	return err
+}
+
+// DefaultAppLaunchTimeout how long AppLaunchSafe waits for the launch
+var DefaultAppLaunchTimeout = 60 * time.Second
+
+// AppLaunchSafe
+//
+// AppLaunch guarded further against the bundle ids which wedge WDA (e.g. a typo): the bundle id must be well-formed,
+// and like with AppLaunch the application installed (its AppState is not WDAAppStateUnknown).
+// If the launch fails or takes longer than DefaultAppLaunchTimeout and the session does not answer afterwards,
+// a new session is created for this Session, like SetAutoRecover does, so the next steps of the run can go on.
+func (s *Session) AppLaunchSafe(bundleId string, opt ...WDAAppLaunchOption) (err error) {
+	if !reBundleID.MatchString(bundleId) {
+		return fmt.Errorf("invalid bundle id '%s'", bundleId)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultAppLaunchTimeout)
+	defer cancel()
+	if err = s.appLaunch(ctx, bundleId, opt...); err == nil || errors.Is(err, ErrSessionClosed) || errors.Is(err, ErrAppNotInstalled) {
+		return
+	}
+	if s.Ping() == nil {
+		return err
+	}
+	if s.client == nil || s.client.Ping() != nil {
+		return fmt.Errorf("%w (WDA does not answer anymore)", err)
+	}
+	if _, errRecover := s.recover(s.ID()); errRecover != nil {
+		return fmt.Errorf("%w (failed to recover session: %v)", err, errRecover)
+	}
+	return fmt.Errorf("%w (session recovered as %s)", err, s.ID())
+}
+
+// AssertAppTransitions
+//
+// Polls the state of the application (every DefaultWaitInterval) for up to `within`, and succeeds as soon as
+// the observed changes contain `expected` in a row, e.g. `WDAAppRunningFront, WDAAppRunningBack, WDAAppRunningFront`
+// for a backgrounding/resume test. The first observation is the current state.
+// A transition shorter than the interval may be missed, the error lists what was observed.
+//
+//	go func() {
+//		_ = s.AppDeactivate(2)
+//	}()
+//	err := s.AssertAppTransitions(bundleId, []WDAAppRunState{WDAAppRunningFront, WDAAppRunningBack, WDAAppRunningFront}, 10*time.Second)
+func (s *Session) AssertAppTransitions(bundleId string, expected []WDAAppRunState, within time.Duration) (err error) {
+	if len(expected) == 0 {
+		return nil
+	}
+	var observed []WDAAppRunState
+	deadline := time.Now().Add(within)
+	for {
+		var state WDAAppRunState
+		if state, err = s.AppState(bundleId); err != nil {
+			return fmt.Errorf("app transitions of '%s': %w", bundleId, err)
+		}
+		if len(observed) == 0 || observed[len(observed)-1] != state {
+			observed = append(observed, state)
+			if endsWithAppTransitions(observed, expected) {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("app transitions of '%s': expected %s, observed %s within %s",
+				bundleId, formatAppTransitions(expected), formatAppTransitions(observed), within)
+		}
+		time.Sleep(waitInterval())
+	}
+}
+
+// endsWithAppTransitions checked after every new observation, so the earlier ones were checked before
+func endsWithAppTransitions(observed, expected []WDAAppRunState) bool {
+	if len(observed) < len(expected) {
+		return false
+	}
+	tail := observed[len(observed)-len(expected):]
+	for i := range expected {
+		if tail[i] != expected[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func formatAppTransitions(states []WDAAppRunState) string {
+	names := make([]string, len(states))
+	for i := range states {
+		names[i] = states[i].String()
+	}
+	return "[" + strings.Join(names, " → ") + "]"
+}
+
+// DefaultOpenURLTimeout how long OpenURL waits for the application to come to the foreground
+var DefaultOpenURLTimeout = 10 * time.Second
+
+// OpenURL
+//
+// Opens a deep link (or a web page) with `/url`, then waits up to DefaultOpenURLTimeout for the application
+// with `bundleId` to be in the foreground. When omitted, it waits for another application than the one
+// in the foreground before, so `bundleId` is needed for the links handled by the foreground application.
+// The WDA builds which don't support `/url`, or fail to open it (before iOS 16.4 WDA relies on Siri,
+// which may be disabled), get the Siri voice command `Open {url}` as a fallback.
+//
+//	err := s.OpenURL("prefs:root=General", "com.apple.Preferences")
+func (s *Session) OpenURL(rawURL string, bundleId ...string) (err error) {
+	if rawURL == "" {
+		return errors.New("'url' is empty")
+	}
+	var before WDAActiveAppInfo
+	if len(bundleId) == 0 {
+		if before, err = s.ActiveAppInfo(); err != nil {
+			return fmt.Errorf("open url '%s': %w", rawURL, err)
+		}
+	}
+	body := newWdaBody().set("url", rawURL)
+	if _, err = executePost(s.client, "OpenURL", urlJoin(s.sessionURL(), "/url"), body); err != nil {
+		if !isOpenURLFailure(err) {
+			return fmt.Errorf("open url '%s': %w", rawURL, err)
+		}
+		if errSiri := s.SiriActivate(fmt.Sprintf("Open {%s}", rawURL)); errSiri != nil {
+			return fmt.Errorf("open url '%s': %w (siri: %v)", rawURL, err, errSiri)
+		}
+	}
+
+	var active WDAActiveAppInfo
+	condition := func(s *Session) (bool, error) {
+		if len(bundleId) != 0 {
+			state, err := s.AppState(bundleId[0])
+			return state == WDAAppRunningFront, err
+		}
+		var err error
+		active, err = s.ActiveAppInfo()
+		return active.BundleID != "" && active.BundleID != "com.apple.springboard" && active.BundleID != before.BundleID, err
+	}
+	if err = s._waitWithTimeoutAndInterval(condition, DefaultOpenURLTimeout, waitInterval()); err != nil {
+		target := "another application than '" + before.BundleID + "'"
+		if len(bundleId) != 0 {
+			target = "'" + bundleId[0] + "'"
+		}
+		return fmt.Errorf("open url '%s': %s is not in the foreground: %w", rawURL, target, err)
+	}
+	return nil
+}
+
+// isOpenURLFailure WDA can't open the url itself, as opposed to the session or the connection failing
+func isOpenURLFailure(err error) bool {
+	if errors.Is(err, ErrSessionClosed) || errors.Is(err, ErrSessionSuspended) {
+		return false
+	}
+	if isUnsupportedCommand(err) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "failed to open") || strings.Contains(msg, "cannot open") ||
+		strings.Contains(msg, "could not be opened") || strings.Contains(msg, "unable to open")
+}
+
+// AppStateCleaner
+//
+// Clears the data an application keeps across launches, which WDA can't, e.g. through a debug backdoor
+// of the app under test or a device management tool. `udid` is Client.UDID.
+type AppStateCleaner interface {
+	ClearKeychain(udid, bundleId string) error
+	ClearUserDefaults(udid, bundleId string) error
+}
+
+// WDAAppResetOption
+//
+//	opt := NewWDAAppResetOption().
+//		SetClearUserDefaults(true).
+//		SetStateCleaner(cleaner).
+//		SetLaunchOption(NewWDAAppLaunchOption().SetArguments([]string{"-UITests"}))
+type WDAAppResetOption struct {
+	launchOption      WDAAppLaunchOption
+	clearKeychain     bool
+	clearUserDefaults bool
+	stateCleaner      AppStateCleaner
+}
+
+func NewWDAAppResetOption() *WDAAppResetOption {
+	return new(WDAAppResetOption)
+}
+
+// SetLaunchOption of the relaunch, default waits for quiescence
+func (o *WDAAppResetOption) SetLaunchOption(opt WDAAppLaunchOption) *WDAAppResetOption {
+	o.launchOption = opt
+	return o
+}
+
+// SetClearKeychain needs SetStateCleaner
+func (o *WDAAppResetOption) SetClearKeychain(b bool) *WDAAppResetOption {
+	o.clearKeychain = b
+	return o
+}
+
+// SetClearUserDefaults needs SetStateCleaner
+func (o *WDAAppResetOption) SetClearUserDefaults(b bool) *WDAAppResetOption {
+	o.clearUserDefaults = b
+	return o
+}
+
+func (o *WDAAppResetOption) SetStateCleaner(cleaner AppStateCleaner) *WDAAppResetOption {
+	o.stateCleaner = cleaner
+	return o
+}
+
+// AppReset
+//
+// Terminates the application, clears its state as asked and launches it again, `opt` can be `nil`.
+// The arguments and the environment of the launch option only apply because the application was terminated.
+func (s *Session) AppReset(bundleId string, opt *WDAAppResetOption) (err error) {
+	if opt == nil {
+		opt = NewWDAAppResetOption()
+	}
+	if (opt.clearKeychain || opt.clearUserDefaults) && opt.stateCleaner == nil {
+		return errors.New("app reset: no state cleaner to clear the keychain or the user defaults")
+	}
+	var udid string
+	if s.client != nil {
+		udid = s.client.UDID()
+	}
+
+	if err = s.AppTerminate(bundleId); err != nil {
+		return fmt.Errorf("app reset: terminate '%s': %w", bundleId, err)
+	}
+	if opt.clearKeychain {
+		if err = opt.stateCleaner.ClearKeychain(udid, bundleId); err != nil {
+			return fmt.Errorf("app reset: clear keychain of '%s': %w", bundleId, err)
+		}
+	}
+	if opt.clearUserDefaults {
+		if err = opt.stateCleaner.ClearUserDefaults(udid, bundleId); err != nil {
+			return fmt.Errorf("app reset: clear user defaults of '%s': %w", bundleId, err)
+		}
+	}
+
+	var launchOption []WDAAppLaunchOption
+	if opt.launchOption != nil {
+		launchOption = append(launchOption, opt.launchOption)
+	}
+	if err = s.AppLaunch(bundleId, launchOption...); err != nil {
+		return fmt.Errorf("app reset: launch '%s': %w", bundleId, err)
+	}
+	return
+}
+
+// WDAAppInfo
+//
+// what is known about an application, the fields which could not be obtained are left empty
+type WDAAppInfo struct {
+	BundleID string
+	State    WDAAppRunState
+	Pid      int    // of the active applications
+	Name     string // of the foreground application, or the display name when installed apps can be listed
+	Version  string // CFBundleVersion, when the AppInstaller of the Client is an AppLister
+	// of the foreground application
+	ProcessArguments []interface{}
+	ProcessEnv       interface{}
+}
+
+// AppInfo
+//
+// Gathers the state, the process (ActiveAppInfo, or `/wda/apps/list` for the other active applications)
+// and the version of an application, e.g. to log which build a test exercised.
+// The version is only looked up when the AppInstaller set with WDAClientOption.SetAppInstaller is an AppLister,
+// DefaultAppInstaller would run `ideviceinstaller` on every call. When the lookup fails the error is returned
+// with the info gathered so far.
+func (s *Session) AppInfo(bundleId string) (info WDAAppInfo, err error) {
+	info.BundleID = bundleId
+	if info.State, err = s.AppState(bundleId); err != nil {
+		return WDAAppInfo{}, err
+	}
+
+	if active, errActive := s.ActiveAppInfo(); errActive == nil && active.BundleID == bundleId {
+		info.Pid, info.Name = active.Pid, active.Name
+		info.ProcessArguments, info.ProcessEnv = active.ProcessArguments.Args, active.ProcessArguments.Env
+	} else if apps, errList := s.activeApps(); errList == nil {
+		for _, app := range apps {
+			if app.BundleID == bundleId {
+				info.Pid = app.Pid
+				break
+			}
+		}
+	}
+
+	if s.client == nil {
+		return
+	}
+	if _, ok := s.client.appInstaller.(AppLister); !ok {
+		return
+	}
+	var apps []WDAInstalledApp
+	if apps, err = s.client.InstalledApps(); err != nil {
+		return info, fmt.Errorf("version of '%s': %w", bundleId, err)
+	}
+	for _, app := range apps {
+		if app.BundleID == bundleId {
+			info.Version = app.Version
+			if info.Name == "" {
+				info.Name = app.Name
+			}
+			break
+		}
+	}
+	return
+}
+
+// activeApps `/wda/apps/list`, not available on every WDA build
+func (s *Session) activeApps() (apps []WDAAppBaseInfo, err error) {
+	var wdaResp wdaResponse
+	if wdaResp, err = executeGet(s.client, "ActiveApps", urlJoin(s.sessionURL(), "/wda/apps/list")); err != nil {
+		return nil, err
+	}
+	_, err = wdaResp.unmarshalValue(&apps)
+	return
+}
+
+// WDAAppStateTimeoutError returned by WaitForAppState
+type WDAAppStateTimeoutError struct {
+	BundleID string
+	Desired  WDAAppRunState
+	Last     WDAAppRunState // last observed state
+	Timeout  time.Duration
+}
+
+func (e *WDAAppStateTimeoutError) Error() string {
+	return fmt.Sprintf("app '%s' is still %s instead of %s after %v", e.BundleID, e.Last, e.Desired, e.Timeout)
+}
+
+// WaitForAppState
+//
+// Polls AppState every `interval` (DefaultWaitInterval when `<= 0`) until the application is in `desired`,
+// or fails with a WDAAppStateTimeoutError after `timeout`.
+//
+//	err := s.WaitForAppState(bundleId, WDAAppRunningBack, 5*time.Second, 0)
+//	var errTimeout *WDAAppStateTimeoutError
+//	if errors.As(err, &errTimeout) {
+//	}
+func (s *Session) WaitForAppState(bundleId string, desired WDAAppRunState, timeout, interval time.Duration) (err error) {
+	if interval <= 0 {
+		interval = waitInterval()
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		var state WDAAppRunState
+		if state, err = s.AppState(bundleId); err != nil {
+			return err
+		}
+		if state == desired {
+			return nil
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return &WDAAppStateTimeoutError{BundleID: bundleId, Desired: desired, Last: state, Timeout: timeout}
+		}
+		time.Sleep(interval)
+	}
+}
+
+// DefaultBackgroundAppRetries how many times BackgroundApp activates the application again
+var DefaultBackgroundAppRetries = 2
+
+// BackgroundApp
+//
+// Sends the application to the background for `duration` (see AppDeactivate) and verifies it came back to the foreground,
+// iOS sometimes keeps it suspended, then it is activated again up to DefaultBackgroundAppRetries times.
+func (s *Session) BackgroundApp(bundleId string, duration time.Duration) (err error) {
+	var state WDAAppRunState
+	if state, err = s.AppState(bundleId); err != nil {
+		return err
+	}
+	switch state {
+	case WDAAppStateUnknown:
+		return fmt.Errorf("background '%s': %w", bundleId, ErrAppNotInstalled)
+	case WDAAppRunningFront:
+	default:
+		// `deactivateApp` acts on the frontmost application
+		if err = s.AppActivate(bundleId); err != nil {
+			return err
+		}
+	}
+	if err = s.AppDeactivate(duration.Seconds()); err != nil {
+		return err
+	}
+
+	const verifyTimeout = 5 * time.Second
+	for retry := 0; ; retry++ {
+		if err = s.WaitForAppState(bundleId, WDAAppRunningFront, verifyTimeout, 0); err == nil {
+			return nil
+		}
+		var errTimeout *WDAAppStateTimeoutError
+		if !errors.As(err, &errTimeout) || retry >= DefaultBackgroundAppRetries {
+			return fmt.Errorf("background '%s': %w", bundleId, err)
+		}
+		debugLog(fmt.Sprintf("background '%s': still %s, activating it again", bundleId, errTimeout.Last))
+		if err = s.AppActivate(bundleId); err != nil {
+			return err
+		}
+	}
+}
+
+// IsAppInstalled
+//
+// Whether the application is installed, running or not. The AppInstaller of the Client is asked first when it is
+// an AppLister and the UDID is known, so WDA is not involved, it only lists the user applications though,
+// the others (e.g. `com.apple.Preferences`) are checked with AppState.
+//
+//	if installed, _ := s.IsAppInstalled(bundleId); !installed {
+//		err = s.InstallApp("build/Demo.ipa")
+//	}
+func (s *Session) IsAppInstalled(bundleId string) (installed bool, err error) {
+	if !reBundleID.MatchString(bundleId) {
+		return false, fmt.Errorf("invalid bundle id '%s'", bundleId)
+	}
+	if s.client != nil {
+		if installer, _, errInstaller := s.client.installer(); errInstaller == nil {
+			if _, ok := installer.(AppLister); ok {
+				var apps []WDAInstalledApp
+				if apps, err = s.client.InstalledApps(); err == nil {
+					for i := range apps {
+						if apps[i].BundleID == bundleId {
+							return true, nil
+						}
+					}
+				} else {
+					debugLog(fmt.Sprintf("installed apps: %s", err))
+				}
+			}
+		}
+	}
+	var state WDAAppRunState
+	if state, err = s.AppState(bundleId); err != nil {
+		return false, err
+	}
+	return state != WDAAppStateUnknown, nil
+}
@@ -0,0 +1,199 @@
+package gwda
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func Test_endsWithAppTransitions(t *testing.T) {
+	expected := []WDAAppRunState{WDAAppRunningFront, WDAAppRunningBack, WDAAppRunningFront}
+	for _, tc := range []struct {
+		observed []WDAAppRunState
+		want     bool
+	}{
+		{[]WDAAppRunState{WDAAppRunningFront, WDAAppRunningBack}, false},
+		{[]WDAAppRunState{WDAAppRunningFront, WDAAppRunningBack, WDAAppRunningFront}, true},
+		{[]WDAAppRunState{WDAAppNotRunning, WDAAppRunningFront, WDAAppRunningBack, WDAAppRunningFront}, true},
+		{[]WDAAppRunState{WDAAppRunningFront, WDAAppNotRunning, WDAAppRunningFront}, false},
+		{[]WDAAppRunState{WDAAppRunningFront, WDAAppRunningBack, WDAAppRunningFront, WDAAppNotRunning}, false},
+	} {
+		if got := endsWithAppTransitions(tc.observed, expected); got != tc.want {
+			t.Errorf("%s: got %v", formatAppTransitions(tc.observed), got)
+		}
+	}
+}
+
+func TestSession_AssertAppTransitions(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	checkErr(t, s.AppLaunch(bundleId))
+
+	go func() {
+		time.Sleep(time.Second)
+		_ = s.AppDeactivate(3)
+	}()
+	err = s.AssertAppTransitions(bundleId, []WDAAppRunState{WDAAppRunningFront, WDAAppRunningBack, WDAAppRunningFront}, 15*time.Second)
+	checkErr(t, err)
+}
+
+func Test_reBundleID(t *testing.T) {
+	for bundleId, want := range map[string]bool{
+		"com.apple.Preferences":   true,
+		"com.example.my-app.beta": true,
+		"Preferences":             false,
+		"com.apple.Preferences ":  false,
+		"com..apple":              false,
+		"":                        false,
+	} {
+		if got := reBundleID.MatchString(bundleId); got != want {
+			t.Errorf("%q: got %v, want %v", bundleId, got, want)
+		}
+	}
+}
+
+func TestSession_AppLaunchSafe(t *testing.T) {
+	client, err := NewClient(deviceURL)
+	checkErr(t, err)
+	session, err := client.NewSession()
+	checkErr(t, err)
+
+	if err = session.AppLaunchSafe("com.apple.Prefrences"); !errors.Is(err, ErrAppNotInstalled) {
+		t.Fatalf("expected %v, got %v", ErrAppNotInstalled, err)
+	}
+	checkErr(t, session.AppLaunchSafe("com.apple.Preferences"))
+}
+
+func TestSession_OpenURL(t *testing.T) {
+	client, err := NewClient(deviceURL)
+	checkErr(t, err)
+	session, err := client.NewSession()
+	checkErr(t, err)
+
+	checkErr(t, session.OpenURL("https://example.com", "com.apple.mobilesafari"))
+}
+
+func Test_isOpenURLFailure(t *testing.T) {
+	for _, tc := range []struct {
+		err      error
+		expected bool
+	}{
+		{errors.New("OpenURL: unknown command: Unhandled endpoint: /session/S1/url"), true},
+		{errors.New("OpenURL: unknown error: Failed to open the url prefs:root=General"), true},
+		{errors.New("OpenURL: unknown error: The url 'demo://home' could not be opened"), true},
+		{fmt.Errorf("OpenURL: %w", ErrSessionClosed), false},
+		{fmt.Errorf("OpenURL: %w", ErrSessionSuspended), false},
+		{errors.New(`Post "http://localhost:8100/session/S1/url": dial tcp [::1]:8100: connect: connection refused`), false},
+	} {
+		if actual := isOpenURLFailure(tc.err); actual != tc.expected {
+			t.Errorf("%v: expected %v, got %v", tc.err, tc.expected, actual)
+		}
+	}
+}
+
+type recordingCleaner struct {
+	cleared []string
+}
+
+func (rc *recordingCleaner) ClearKeychain(udid, bundleId string) error {
+	rc.cleared = append(rc.cleared, "keychain "+bundleId)
+	return nil
+}
+
+func (rc *recordingCleaner) ClearUserDefaults(udid, bundleId string) error {
+	rc.cleared = append(rc.cleared, "defaults "+bundleId)
+	return nil
+}
+
+func TestSession_AppReset(t *testing.T) {
+	client, err := NewClient(deviceURL)
+	checkErr(t, err)
+	session, err := client.NewSession()
+	checkErr(t, err)
+
+	if err = session.AppReset(bundleId, NewWDAAppResetOption().SetClearKeychain(true)); err == nil {
+		t.Fatal("expected an error without state cleaner")
+	}
+
+	cleaner := new(recordingCleaner)
+	opt := NewWDAAppResetOption().SetClearUserDefaults(true).SetStateCleaner(cleaner).
+		SetLaunchOption(NewWDAAppLaunchOption().SetArguments([]string{"-AppleLanguages", "(en)"}))
+	checkErr(t, session.AppReset(bundleId, opt))
+	if len(cleaner.cleared) != 1 || cleaner.cleared[0] != "defaults "+bundleId {
+		t.Fatalf("unexpected cleanup: %q", cleaner.cleared)
+	}
+	if state, _ := session.AppState(bundleId); state != WDAAppRunningFront {
+		t.Fatalf("app state: %s", state)
+	}
+}
+
+func TestSession_AppInfo(t *testing.T) {
+	client, err := NewClient(deviceURL)
+	checkErr(t, err)
+	session, err := client.NewSession()
+	checkErr(t, err)
+
+	checkErr(t, session.AppLaunch(bundleId))
+	info, err := session.AppInfo(bundleId)
+	checkErr(t, err)
+	t.Logf("%+v", info)
+	if info.State != WDAAppRunningFront || info.Pid == 0 {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+}
+
+func TestSession_WaitForAppState(t *testing.T) {
+	client, err := NewClient(deviceURL)
+	checkErr(t, err)
+	session, err := client.NewSession()
+	checkErr(t, err)
+
+	checkErr(t, session.AppLaunch(bundleId))
+	checkErr(t, session.WaitForAppState(bundleId, WDAAppRunningFront, 5*time.Second, 0))
+
+	err = session.WaitForAppState(bundleId, WDAAppNotRunning, time.Second, 0)
+	var errTimeout *WDAAppStateTimeoutError
+	if !errors.As(err, &errTimeout) || errTimeout.Last != WDAAppRunningFront {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+}
+
+func Test_appNotInstalledError(t *testing.T) {
+	errLaunch := errors.New("AppLaunch: unknown error: Cannot launch com.example.typo application. Make sure the correct bundle identifier has been provided")
+	if err := appNotInstalledError("com.example.typo", errLaunch); !errors.Is(err, ErrAppNotInstalled) {
+		t.Fatalf("expected %v, got %v", ErrAppNotInstalled, err)
+	}
+	errOther := errors.New("AppLaunch: failed to send request")
+	if err := appNotInstalledError("com.example.app", errOther); err != errOther {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSession_BackgroundApp(t *testing.T) {
+	client, err := NewClient(deviceURL)
+	checkErr(t, err)
+	session, err := client.NewSession()
+	checkErr(t, err)
+
+	checkErr(t, session.AppLaunch(bundleId))
+	checkErr(t, session.BackgroundApp(bundleId, 2*time.Second))
+}
+
+func TestSession_IsAppInstalled(t *testing.T) {
+	client, err := NewClient(deviceURL)
+	checkErr(t, err)
+	session, err := client.NewSession()
+	checkErr(t, err)
+
+	installed, err := session.IsAppInstalled("com.apple.Preferences")
+	checkErr(t, err)
+	if !installed {
+		t.Fatal("expected com.apple.Preferences to be installed")
+	}
+	if installed, err = session.IsAppInstalled("com.example.not.installed"); err != nil || installed {
+		t.Fatalf("expected com.example.not.installed to be missing, got %v, %v", installed, err)
+	}
+}
@@ -0,0 +1,235 @@
+package gwda
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// AudioCapturer
+//
+// records the audio output of the device, e.g. CommandAudioCapturer or a capturer of the device farm
+type AudioCapturer interface {
+	Start() error
+	Stop() (clip AudioClip, err error)
+}
+
+// AudioClip mono PCM, the samples are in range [-1.0, 1.0]
+type AudioClip struct {
+	SampleRate int
+	Samples    []float64
+}
+
+func (ac AudioClip) Duration() time.Duration {
+	if ac.SampleRate <= 0 {
+		return 0
+	}
+	return time.Duration(len(ac.Samples)) * time.Second / time.Duration(ac.SampleRate)
+}
+
+// RMS
+//
+// loudness of the samples between `from` and `to`, `0` is silence
+func (ac AudioClip) RMS(from, to time.Duration) float64 {
+	if ac.SampleRate <= 0 {
+		return 0
+	}
+	i, j := ac.index(from), ac.index(to)
+	if i >= j {
+		return 0
+	}
+	var sum float64
+	for _, v := range ac.Samples[i:j] {
+		sum += v * v
+	}
+	return math.Sqrt(sum / float64(j-i))
+}
+
+func (ac AudioClip) index(d time.Duration) int {
+	i := int(d * time.Duration(ac.SampleRate) / time.Second)
+	if i < 0 {
+		return 0
+	}
+	if i > len(ac.Samples) {
+		return len(ac.Samples)
+	}
+	return i
+}
+
+// AudioStep a request sent while the audio was captured
+type AudioStep struct {
+	Action   string
+	Offset   time.Duration // since the capture started
+	Duration time.Duration
+}
+
+// AudioCapture
+//
+// The audio captured together with the requests of the Client sent in the meantime,
+// the offsets are as precise as the start latency of the AudioCapturer.
+//
+//	capture, err := s.StartAudioCapture(NewFFmpegAudioCapturer("0", 16000))
+//	err = playButton.Click()
+//	err = capture.Stop()
+//	steps := capture.Steps()
+//	rms := capture.RMSAfter(steps[len(steps)-1], 2*time.Second)
+type AudioCapture struct {
+	client   *Client
+	capturer AudioCapturer
+	started  time.Time
+
+	mutex sync.Mutex
+	steps []AudioStep
+	clip  AudioClip
+}
+
+// StartAudioCapture
+//
+// only one capture can be active per Client
+func (s *Session) StartAudioCapture(capturer AudioCapturer) (capture *AudioCapture, err error) {
+	if s.client == nil {
+		return nil, errors.New("audio capture requires a session created by a Client")
+	}
+	capture = &AudioCapture{client: s.client, capturer: capturer}
+
+	s.client.audioCaptureMutex.Lock()
+	defer s.client.audioCaptureMutex.Unlock()
+	if s.client.audioCapture != nil {
+		return nil, errors.New("audio capture is already active")
+	}
+	if err = capturer.Start(); err != nil {
+		return nil, fmt.Errorf("audio capture: %w", err)
+	}
+	capture.started = time.Now()
+	s.client.audioCapture = capture
+	return
+}
+
+// Stop
+//
+// stops the AudioCapturer, the clip and the steps stay available
+func (ac *AudioCapture) Stop() (err error) {
+	ac.client.audioCaptureMutex.Lock()
+	if ac.client.audioCapture == ac {
+		ac.client.audioCapture = nil
+	}
+	ac.client.audioCaptureMutex.Unlock()
+
+	var clip AudioClip
+	if clip, err = ac.capturer.Stop(); err != nil {
+		return fmt.Errorf("audio capture: %w", err)
+	}
+	ac.mutex.Lock()
+	ac.clip = clip
+	ac.mutex.Unlock()
+	return
+}
+
+// Clip the captured audio, empty until Stop
+func (ac *AudioCapture) Clip() AudioClip {
+	ac.mutex.Lock()
+	defer ac.mutex.Unlock()
+	return ac.clip
+}
+
+// Steps the requests sent while capturing, in the order they finished
+func (ac *AudioCapture) Steps() []AudioStep {
+	ac.mutex.Lock()
+	defer ac.mutex.Unlock()
+	return append([]AudioStep(nil), ac.steps...)
+}
+
+// RMSAfter
+//
+// loudness of the `window` following the response of `step`, e.g. after tapping a play button
+func (ac *AudioCapture) RMSAfter(step AudioStep, window time.Duration) float64 {
+	from := step.Offset + step.Duration
+	return ac.Clip().RMS(from, from+window)
+}
+
+func (ac *AudioCapture) addStep(call *wdaCall, duration time.Duration) {
+	ac.mutex.Lock()
+	defer ac.mutex.Unlock()
+	ac.steps = append(ac.steps, AudioStep{Action: call.actionName, Offset: call.start.Sub(ac.started), Duration: duration})
+}
+
+func (c *Client) activeAudioCapture() *AudioCapture {
+	c.audioCaptureMutex.Lock()
+	defer c.audioCaptureMutex.Unlock()
+	return c.audioCapture
+}
+
+// CommandAudioCapturer
+//
+// Runs a command which writes signed 16-bit little-endian mono PCM to stdout until it is interrupted.
+type CommandAudioCapturer struct {
+	Name       string
+	Args       []string
+	SampleRate int
+
+	cmd    *exec.Cmd
+	stdout bytes.Buffer
+	stderr bytes.Buffer
+}
+
+// NewFFmpegAudioCapturer
+//
+// Captures an AVFoundation audio input of the host Mac with `ffmpeg`,
+// the iPhone shows up as one once "Screen Capture Devices" of CoreMediaIO are allowed (as QuickTime does).
+// `inputDevice` is the index or name listed by `ffmpeg -f avfoundation -list_devices true -i ""`.
+func NewFFmpegAudioCapturer(inputDevice string, sampleRate int) *CommandAudioCapturer {
+	return &CommandAudioCapturer{
+		Name: "ffmpeg",
+		Args: []string{"-loglevel", "error", "-f", "avfoundation", "-i", ":" + inputDevice,
+			"-f", "s16le", "-ac", "1", "-ar", strconv.Itoa(sampleRate), "-"},
+		SampleRate: sampleRate,
+	}
+}
+
+func (cc *CommandAudioCapturer) Start() error {
+	if cc.cmd != nil {
+		return errors.New("already started")
+	}
+	cc.stdout.Reset()
+	cc.stderr.Reset()
+	cc.cmd = exec.Command(cc.Name, cc.Args...)
+	cc.cmd.Stdout = &cc.stdout
+	cc.cmd.Stderr = &cc.stderr
+	if err := cc.cmd.Start(); err != nil {
+		cc.cmd = nil
+		return err
+	}
+	return nil
+}
+
+func (cc *CommandAudioCapturer) Stop() (clip AudioClip, err error) {
+	if cc.cmd == nil {
+		return AudioClip{}, errors.New("not started")
+	}
+	cmd := cc.cmd
+	cc.cmd = nil
+	if err = cmd.Process.Signal(os.Interrupt); err != nil {
+		// e.g. Windows
+		_ = cmd.Process.Kill()
+	}
+	// interrupted commands rarely exit with 0, only fail without any output
+	if errWait := cmd.Wait(); errWait != nil && cc.stdout.Len() == 0 {
+		return AudioClip{}, fmt.Errorf("%s: %w %s", cc.Name, errWait, bytes.TrimSpace(cc.stderr.Bytes()))
+	}
+	return AudioClip{SampleRate: cc.SampleRate, Samples: decodePCM16LE(cc.stdout.Bytes())}, nil
+}
+
+func decodePCM16LE(raw []byte) []float64 {
+	samples := make([]float64, len(raw)/2)
+	for i := range samples {
+		samples[i] = float64(int16(binary.LittleEndian.Uint16(raw[i*2:]))) / 32768
+	}
+	return samples
+}
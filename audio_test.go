@@ -0,0 +1,50 @@
+package gwda
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAudioClip_RMS(t *testing.T) {
+	// 1s of silence followed by 1s of a full scale square wave
+	raw := make([]byte, 0, 8000*2)
+	for i := 0; i < 4000; i++ {
+		raw = append(raw, 0, 0)
+	}
+	for i := 0; i < 4000; i++ {
+		if i%2 == 0 {
+			raw = append(raw, 0xff, 0x7f)
+		} else {
+			raw = append(raw, 0x00, 0x80)
+		}
+	}
+	clip := AudioClip{SampleRate: 4000, Samples: decodePCM16LE(raw)}
+
+	if clip.Duration() != 2*time.Second {
+		t.Fatalf("duration %v", clip.Duration())
+	}
+	if rms := clip.RMS(0, time.Second); rms != 0 {
+		t.Errorf("silence rms %f", rms)
+	}
+	if rms := clip.RMS(time.Second, 3*time.Second); math.Abs(rms-1) > 0.001 {
+		t.Errorf("square wave rms %f", rms)
+	}
+}
+
+func TestSession_StartAudioCapture(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+
+	capture, err := s.StartAudioCapture(NewFFmpegAudioCapturer("0", 16000))
+	checkErr(t, err)
+	checkErr(t, s.PressVolumeUpButton())
+	time.Sleep(time.Second)
+	checkErr(t, capture.Stop())
+
+	steps := capture.Steps()
+	t.Log(steps)
+	t.Log(capture.Clip().Duration(), capture.RMSAfter(steps[0], time.Second))
+}
@@ -0,0 +1,134 @@
+package gwda
+
+import "sync"
+
+// infoCache
+//
+// static information of the device, see Session.SetInfoCache
+type infoCache struct {
+	mutex   sync.Mutex
+	enabled bool
+
+	deviceInfo *WDADeviceInfo
+	screen     *WDAScreen
+}
+
+// SetInfoCache
+//
+// Keeps the result of DeviceInfo and Screen (so Scale and StatusBarSize too) after the first request,
+// which saves a round trip per call in tight loops.
+// The cache is dropped by SetOrientation, SetRotation and RefreshInfoCache,
+// call the latter when the device may have changed on its own, e.g. it was rotated by hand.
+//
+// Default is `false`
+func (s *Session) SetInfoCache(b bool) {
+	s.infoCache.mutex.Lock()
+	defer s.infoCache.mutex.Unlock()
+	s.infoCache.enabled = b
+	s.infoCache.deviceInfo, s.infoCache.screen = nil, nil
+}
+
+// RefreshInfoCache
+//
+// drops the cached information, the next calls request it again
+func (s *Session) RefreshInfoCache() {
+	s.infoCache.mutex.Lock()
+	defer s.infoCache.mutex.Unlock()
+	s.infoCache.deviceInfo, s.infoCache.screen = nil, nil
+}
+
+func (s *Session) cachedDeviceInfo() (wdaDeviceInfo WDADeviceInfo, err error) {
+	c := &s.infoCache
+	c.mutex.Lock()
+	enabled, cached := c.enabled, c.deviceInfo
+	c.mutex.Unlock()
+	if cached != nil {
+		return *cached, nil
+	}
+	if wdaDeviceInfo, err = deviceInfo(s.client, s.sessionURL()); err != nil || !enabled {
+		return
+	}
+	c.mutex.Lock()
+	if c.enabled {
+		c.deviceInfo = &wdaDeviceInfo
+	}
+	c.mutex.Unlock()
+	return
+}
+
+func (s *Session) cachedScreen() (wdaScreen WDAScreen, err error) {
+	c := &s.infoCache
+	c.mutex.Lock()
+	enabled, cached := c.enabled, c.screen
+	c.mutex.Unlock()
+	if cached != nil {
+		return *cached, nil
+	}
+	if wdaScreen, err = screen(s.client, s.sessionURL()); err != nil || !enabled {
+		return
+	}
+	c.mutex.Lock()
+	if c.enabled {
+		c.screen = &wdaScreen
+	}
+	c.mutex.Unlock()
+	return
+}
+
+type elementCache struct {
+	mutex    sync.Mutex
+	enabled  bool
+	elements map[string]*Element // by `using` and `value` of the locator
+}
+
+// SetElementCache
+//
+// Keeps the element found by FindElement for each locator, so page objects referencing the same controls
+// repeatedly request them only once. The cache is dropped by InvalidateCache, and on its own as soon as
+// a request of the session answers that an element is stale (ErrStaleElement).
+// Call InvalidateCache after a navigation which may leave the cached elements valid but no longer the ones you want.
+//
+// Default is `false`
+func (s *Session) SetElementCache(b bool) {
+	s.elementCache.mutex.Lock()
+	defer s.elementCache.mutex.Unlock()
+	s.elementCache.enabled = b
+	s.elementCache.elements = nil
+}
+
+// InvalidateCache
+//
+// drops the elements cached by SetElementCache, the next FindElement calls search again
+func (s *Session) InvalidateCache() {
+	s.elementCache.mutex.Lock()
+	defer s.elementCache.mutex.Unlock()
+	s.elementCache.elements = nil
+}
+
+func elementCacheKey(wdaLocator WDALocator) string {
+	using, value := wdaLocator.getUsingAndValue()
+	return using + "\x00" + value
+}
+
+func (s *Session) cachedElement(wdaLocator WDALocator) *Element {
+	c := &s.elementCache
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if !c.enabled {
+		return nil
+	}
+	return c.elements[elementCacheKey(wdaLocator)]
+}
+
+func (s *Session) cacheElement(wdaLocator WDALocator, element *Element) {
+	c := &s.elementCache
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if !c.enabled {
+		return
+	}
+	if c.elements == nil {
+		c.elements = make(map[string]*Element)
+	}
+	c.elements[elementCacheKey(wdaLocator)] = element
+}
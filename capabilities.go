@@ -0,0 +1,189 @@
+package gwda
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// WDACapabilities
+//
+// typed capabilities of a new session, checked before they are sent
+//
+//	caps := NewWDACapabilities(bundleId).
+//		SetArguments("-AppleLanguages", "(en)").
+//		SetDefaultAlertAction(WDASessionAlertActionAccept)
+//	s, err := c.NewSessionWithCapabilities(caps)
+type WDACapabilities struct {
+	bundleId                  string
+	arguments                 []string
+	environment               map[string]string
+	shouldWaitForQuiescence   *bool
+	useTestManagerVisibility  *bool
+	shouldUseCompactResponses *bool
+	elementResponseAttributes []string
+	eventloopIdleDelaySec     *int
+	defaultAlertAction        WDASessionDefaultAlertAction
+}
+
+// NewWDACapabilities
+//
+// `bundleId` may be `""` for a session without an application, it then can't have arguments or environment
+func NewWDACapabilities(bundleId string) *WDACapabilities {
+	return &WDACapabilities{bundleId: bundleId}
+}
+
+// SetArguments of the launched application
+func (wc *WDACapabilities) SetArguments(args ...string) *WDACapabilities {
+	wc.arguments = append(wc.arguments, args...)
+	return wc
+}
+
+// SetEnvironment variable of the launched application
+func (wc *WDACapabilities) SetEnvironment(key, value string) *WDACapabilities {
+	if wc.environment == nil {
+		wc.environment = make(map[string]string)
+	}
+	wc.environment[key] = value
+	return wc
+}
+
+// SetShouldWaitForQuiescence
+//
+// Default is `true` when there is a bundle id, like NewWDASessionCapability
+func (wc *WDACapabilities) SetShouldWaitForQuiescence(b bool) *WDACapabilities {
+	wc.shouldWaitForQuiescence = &b
+	return wc
+}
+
+// SetShouldUseTestManagerForVisibilityDetection
+//
+// Default is `false`
+func (wc *WDACapabilities) SetShouldUseTestManagerForVisibilityDetection(b bool) *WDACapabilities {
+	wc.useTestManagerVisibility = &b
+	return wc
+}
+
+// SetShouldUseCompactResponses
+//
+// Default is `true`
+func (wc *WDACapabilities) SetShouldUseCompactResponses(b bool) *WDACapabilities {
+	wc.shouldUseCompactResponses = &b
+	return wc
+}
+
+// SetElementResponseAttributes
+//
+// attributes of the elements in responses when compact responses are disabled, e.g. `type`, `label`, `rect`
+//
+// Default is `type, label`
+func (wc *WDACapabilities) SetElementResponseAttributes(attributes ...string) *WDACapabilities {
+	wc.elementResponseAttributes = attributes
+	return wc
+}
+
+// SetEventloopIdleDelaySec
+//
+// `0` disables the delay
+func (wc *WDACapabilities) SetEventloopIdleDelaySec(seconds int) *WDACapabilities {
+	wc.eventloopIdleDelaySec = &seconds
+	return wc
+}
+
+// SetDefaultAlertAction
+//
+// Default is disabled
+func (wc *WDACapabilities) SetDefaultAlertAction(action WDASessionDefaultAlertAction) *WDACapabilities {
+	wc.defaultAlertAction = action
+	return wc
+}
+
+var reBundleID = regexp.MustCompile(`^[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)+$`)
+
+// wdaElementResponseAttributes supported by `elementResponseAttributes`
+var wdaElementResponseAttributes = map[string]bool{
+	"type": true, "label": true, "name": true, "value": true, "text": true, "rect": true,
+	"enabled": true, "displayed": true, "selected": true,
+}
+
+// Validate
+//
+// reports the first invalid setting
+func (wc *WDACapabilities) Validate() error {
+	if wc.bundleId == "" {
+		if len(wc.arguments) != 0 || len(wc.environment) != 0 {
+			return errors.New("capabilities: arguments and environment need a bundle id")
+		}
+	} else if !reBundleID.MatchString(wc.bundleId) {
+		return fmt.Errorf("capabilities: invalid bundle id '%s'", wc.bundleId)
+	}
+	for key := range wc.environment {
+		if key == "" || strings.Contains(key, "=") {
+			return fmt.Errorf("capabilities: invalid environment variable '%s'", key)
+		}
+	}
+	for _, attr := range wc.elementResponseAttributes {
+		if !wdaElementResponseAttributes[attr] && !strings.HasPrefix(attr, "attribute/") {
+			return fmt.Errorf("capabilities: unknown element response attribute '%s'", attr)
+		}
+	}
+	if wc.eventloopIdleDelaySec != nil && *wc.eventloopIdleDelaySec < 0 {
+		return fmt.Errorf("capabilities: negative eventloopIdleDelaySec %d", *wc.eventloopIdleDelaySec)
+	}
+	switch wc.defaultAlertAction {
+	case "", WDASessionAlertActionAccept, WDASessionAlertActionDismiss:
+	default:
+		return fmt.Errorf("capabilities: unknown default alert action '%s'", wc.defaultAlertAction)
+	}
+	return nil
+}
+
+// Build validates and converts them for Client.NewSession
+func (wc *WDACapabilities) Build() (capabilities WDASessionCapability, err error) {
+	if err = wc.Validate(); err != nil {
+		return nil, err
+	}
+	if wc.bundleId != "" {
+		capabilities = NewWDASessionCapability(wc.bundleId)
+	} else {
+		capabilities = NewWDASessionCapability()
+	}
+	body := wdaBody(capabilities)
+	if len(wc.arguments) != 0 {
+		body.set("arguments", wc.arguments)
+	}
+	if len(wc.environment) != 0 {
+		body.set("environment", wc.environment)
+	}
+	if wc.shouldWaitForQuiescence != nil {
+		body.set("shouldWaitForQuiescence", *wc.shouldWaitForQuiescence)
+	}
+	if wc.useTestManagerVisibility != nil {
+		capabilities.SetShouldUseTestManagerForVisibilityDetection(*wc.useTestManagerVisibility)
+	}
+	if wc.shouldUseCompactResponses != nil {
+		capabilities.SetShouldUseCompactResponses(*wc.shouldUseCompactResponses)
+	}
+	if len(wc.elementResponseAttributes) != 0 {
+		capabilities.SetElementResponseAttributes(strings.Join(wc.elementResponseAttributes, ","))
+	}
+	if wc.eventloopIdleDelaySec != nil {
+		capabilities.SetEventloopIdleDelaySec(*wc.eventloopIdleDelaySec)
+	}
+	if wc.defaultAlertAction != "" {
+		capabilities.SetDefaultAlertAction(wc.defaultAlertAction)
+	}
+	return
+}
+
+// NewSessionWithCapabilities
+//
+// same as NewSession, but an invalid `capabilities` is reported without sending it
+func (c *Client) NewSessionWithCapabilities(capabilities *WDACapabilities) (s *Session, err error) {
+	var caps WDASessionCapability
+	if caps, err = capabilities.Build(); err != nil {
+		return nil, err
+	}
+	return c.NewSession(caps)
+}
@@ -0,0 +1,48 @@
+package gwda
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestWDACapabilities_Build(t *testing.T) {
+	caps, err := NewWDACapabilities("com.apple.Preferences").
+		SetArguments("-AppleLanguages", "(en)").
+		SetEnvironment("DEBUG", "1").
+		SetShouldUseCompactResponses(false).
+		SetElementResponseAttributes("type", "label", "rect").
+		SetEventloopIdleDelaySec(0).
+		SetDefaultAlertAction(WDASessionAlertActionAccept).
+		Build()
+	checkErr(t, err)
+	bs, _ := json.Marshal(caps)
+	want := `{"arguments":["-AppleLanguages","(en)"],"bundleId":"com.apple.Preferences","defaultAlertAction":"accept",` +
+		`"elementResponseAttributes":"type,label,rect","environment":{"DEBUG":"1"},"eventloopIdleDelaySec":0,` +
+		`"shouldUseCompactResponses":false,"shouldWaitForQuiescence":true}`
+	if string(bs) != want {
+		t.Errorf("got  %s\nwant %s", bs, want)
+	}
+
+	for _, caps := range []*WDACapabilities{
+		NewWDACapabilities("Preferences"),
+		NewWDACapabilities("").SetArguments("-a"),
+		NewWDACapabilities("com.apple.Preferences").SetEnvironment("A=B", "1"),
+		NewWDACapabilities("com.apple.Preferences").SetElementResponseAttributes("frame"),
+		NewWDACapabilities("com.apple.Preferences").SetEventloopIdleDelaySec(-1),
+		NewWDACapabilities("com.apple.Preferences").SetDefaultAlertAction("ignore"),
+	} {
+		if _, err = caps.Build(); err == nil {
+			t.Errorf("%+v: expected an error", caps)
+		} else {
+			t.Log(err)
+		}
+	}
+}
+
+func TestClient_NewSessionWithCapabilities(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSessionWithCapabilities(NewWDACapabilities(bundleId).SetArguments("-AppleLanguages", "(en)"))
+	checkErr(t, err)
+	t.Log(s.ID())
+}
@@ -0,0 +1,92 @@
+package gwda
+
+// DefaultPageDistance the fraction of a scroll view scrolled by ScrollPage, less than a page so that no cell is skipped
+var DefaultPageDistance = 0.9
+
+// DefaultMaxPages used by CollectCellLabels when `maxPages <= 0`
+const DefaultMaxPages = 20
+
+// VisibleCells
+//
+// the cells of this table or collection view currently on the screen, in their order
+func (e *Element) VisibleCells() (cells []*Element, err error) {
+	cells, err = e.FindElements(ByClassChain(NewWDAClassChain().
+		Child(WDAElementType{Cell: true}, NewWDAPredicate().Is(WDAPredicateVisible, true))))
+	if err != nil && isNoSuchElement(err) {
+		return nil, nil
+	}
+	return
+}
+
+// ScrollPage
+//
+// scrolls this scroll view by DefaultPageDistance of its size, `direction` is the one of the content, e.g. WDASwipeDirectionDown for the next page
+func (e *Element) ScrollPage(direction WDASwipeDirection) (err error) {
+	return e._scrollDirection(direction, DefaultPageDistance)
+}
+
+// CollectCellLabels
+//
+// Collects the text of the visible cells (their label, or the label of their first static text) page by page,
+// scrolling in `direction` until a page brings no new text or after `maxPages` (DefaultMaxPages when `<= 0`).
+// The texts are de-duplicated, in the order they were seen. Each page reads the source of this element once.
+//
+//	labels, err := elemTable.CollectCellLabels(WDASwipeDirectionDown, 0)
+func (e *Element) CollectCellLabels(direction WDASwipeDirection, maxPages int) (labels []string, err error) {
+	if maxPages <= 0 {
+		maxPages = DefaultMaxPages
+	}
+	seen := make(map[string]bool)
+	for page := 0; page < maxPages; page++ {
+		if page != 0 {
+			if err = e.ScrollPage(direction); err != nil {
+				return labels, err
+			}
+		}
+		var node *WDASourceNode
+		if node, err = e.SourceTree(); err != nil {
+			return labels, err
+		}
+		added := 0
+		for _, label := range visibleCellTexts(node) {
+			if !seen[label] {
+				seen[label] = true
+				labels = append(labels, label)
+				added++
+			}
+		}
+		if page != 0 && added == 0 {
+			break
+		}
+	}
+	return labels, nil
+}
+
+// visibleCellTexts the texts of the visible cells among the children of `container`
+func visibleCellTexts(container *WDASourceNode) (texts []string) {
+	for _, child := range container.Children {
+		if child.Type != "Cell" || !child.IsVisible {
+			continue
+		}
+		if text := cellText(child); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return
+}
+
+func cellText(cell *WDASourceNode) (text string) {
+	if cell.Label != "" {
+		return cell.Label
+	}
+	cell.Walk(func(node *WDASourceNode) bool {
+		if text != "" {
+			return false
+		}
+		if node.Type == "StaticText" && node.Label != "" {
+			text = node.Label
+		}
+		return true
+	})
+	return
+}
@@ -0,0 +1,37 @@
+package gwda
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func Test_visibleCellTexts(t *testing.T) {
+	table := new(WDASourceNode)
+	checkErr(t, json.Unmarshal([]byte(`{"type":"Table","isVisible":"1","children":[
+		{"type":"Cell","label":"Wi-Fi","isVisible":"1"},
+		{"type":"Cell","isVisible":"1","children":[{"type":"Image","label":"icon"},{"type":"StaticText","label":"Bluetooth"}]},
+		{"type":"Other","label":"Header","isVisible":"1"},
+		{"type":"Cell","label":"General","isVisible":"0"},
+		{"type":"Cell","isVisible":"1"}]}`), table))
+	if texts := visibleCellTexts(table); !reflect.DeepEqual(texts, []string{"Wi-Fi", "Bluetooth"}) {
+		t.Fatal(texts)
+	}
+}
+
+func TestElement_CollectCellLabels(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	checkErr(t, s.AppLaunch("com.apple.Preferences"))
+
+	table, err := s.FindElement(WDALocator{ClassName: WDAElementType{Table: true}})
+	checkErr(t, err)
+	cells, err := table.VisibleCells()
+	checkErr(t, err)
+	t.Log(len(cells))
+	labels, err := table.CollectCellLabels(WDASwipeDirectionDown, 5)
+	checkErr(t, err)
+	t.Log(labels)
+}
@@ -0,0 +1,205 @@
+package gwda
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+)
+
+// WDAConfig
+//
+// Defaults shared by every Client, loaded with LoadConfig. The zero values keep the built-in defaults.
+//
+//	{
+//		"waitTimeout": "30s",
+//		"pingTimeout": 3,
+//		"autoRecover": true,
+//		"defaultAlertAction": "accept",
+//		"shard": {"retries": 2, "artifactDir": "build/artifacts"},
+//		"mjpeg": {"framerate": 15, "screenshotQuality": 50}
+//	}
+type WDAConfig struct {
+	Debug        *bool             `json:"debug,omitempty"`
+	WaitTimeout  WDAConfigDuration `json:"waitTimeout,omitempty"`  // DefaultWaitTimeout
+	WaitInterval WDAConfigDuration `json:"waitInterval,omitempty"` // DefaultWaitInterval
+	PingTimeout  WDAConfigDuration `json:"pingTimeout,omitempty"`  // DefaultPingTimeout
+
+	// of the new sessions
+	AutoRecover        *bool                        `json:"autoRecover,omitempty"`
+	DefaultAlertAction WDASessionDefaultAlertAction `json:"defaultAlertAction,omitempty"`
+	MJPEG              struct {
+		Framerate         int     `json:"framerate,omitempty"`
+		ScreenshotQuality int     `json:"screenshotQuality,omitempty"`
+		ScalingFactor     float64 `json:"scalingFactor,omitempty"`
+	} `json:"mjpeg"`
+
+	DumpDir string `json:"dumpDir,omitempty"` // of NewWDAClientOption
+
+	// of NewShardOption
+	Shard struct {
+		Retries     *int   `json:"retries,omitempty"`
+		ArtifactDir string `json:"artifactDir,omitempty"`
+	} `json:"shard"`
+}
+
+// WDAConfigDuration a duration such as `"1m30s"`, or a number of seconds
+type WDAConfigDuration time.Duration
+
+func (d *WDAConfigDuration) UnmarshalJSON(data []byte) (err error) {
+	var v interface{}
+	if err = json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case float64:
+		*d = WDAConfigDuration(v * float64(time.Second))
+	case string:
+		var parsed time.Duration
+		if parsed, err = time.ParseDuration(v); err != nil {
+			return err
+		}
+		*d = WDAConfigDuration(parsed)
+	default:
+		return fmt.Errorf("invalid duration: %s", data)
+	}
+	return
+}
+
+func (d WDAConfigDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+var wdaConfig *WDAConfig
+var wdaConfigMutex sync.RWMutex
+
+// LoadConfig
+//
+// Reads a JSON file and applies it with SetConfig.
+func LoadConfig(path string) (cfg *WDAConfig, err error) {
+	var data []byte
+	if data, err = ioutil.ReadFile(path); err != nil {
+		return nil, err
+	}
+
+	cfg = new(WDAConfig)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err = SetConfig(cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return
+}
+
+// SetConfig
+//
+// Sets the defaults used instead of DefaultWaitTimeout, DefaultWaitInterval and DefaultPingTimeout,
+// and the ones of the new clients and sessions. `nil` goes back to the built-in defaults,
+// except for `debug` which is applied once with WDADebug.
+func SetConfig(cfg *WDAConfig) error {
+	if cfg != nil {
+		if err := cfg.validate(); err != nil {
+			return err
+		}
+		if cfg.Debug != nil {
+			WDADebug(*cfg.Debug)
+		}
+	}
+	wdaConfigMutex.Lock()
+	wdaConfig = cfg
+	wdaConfigMutex.Unlock()
+	return nil
+}
+
+func currentConfig() *WDAConfig {
+	wdaConfigMutex.RLock()
+	defer wdaConfigMutex.RUnlock()
+	return wdaConfig
+}
+
+// waitTimeout the configured one, or DefaultWaitTimeout
+func waitTimeout() time.Duration {
+	if cfg := currentConfig(); cfg != nil && cfg.WaitTimeout > 0 {
+		return time.Duration(cfg.WaitTimeout)
+	}
+	return DefaultWaitTimeout
+}
+
+// waitInterval the configured one, or DefaultWaitInterval
+func waitInterval() time.Duration {
+	if cfg := currentConfig(); cfg != nil && cfg.WaitInterval > 0 {
+		return time.Duration(cfg.WaitInterval)
+	}
+	return DefaultWaitInterval
+}
+
+// pingTimeout the configured one, or DefaultPingTimeout
+func pingTimeout() time.Duration {
+	if cfg := currentConfig(); cfg != nil && cfg.PingTimeout > 0 {
+		return time.Duration(cfg.PingTimeout)
+	}
+	return DefaultPingTimeout
+}
+
+func (cfg *WDAConfig) validate() error {
+	switch cfg.DefaultAlertAction {
+	case "", WDASessionAlertActionAccept, WDASessionAlertActionDismiss:
+	default:
+		return fmt.Errorf("unknown default alert action '%s'", cfg.DefaultAlertAction)
+	}
+	if cfg.Shard.Retries != nil && *cfg.Shard.Retries < 0 {
+		return errors.New("'shard.retries' must not be negative")
+	}
+	if q := cfg.MJPEG.ScreenshotQuality; q < 0 || q > 100 {
+		return errors.New("'mjpeg.screenshotQuality' must be in [1, 100]")
+	}
+	if f := cfg.MJPEG.ScalingFactor; f < 0 || f > 100 {
+		return errors.New("'mjpeg.scalingFactor' must be in [1, 100]")
+	}
+	return nil
+}
+
+// sessionCapabilities adds the configured ones the caller did not set
+func (cfg *WDAConfig) sessionCapabilities(capabilities []WDASessionCapability) []WDASessionCapability {
+	if cfg == nil || cfg.DefaultAlertAction == "" {
+		return capabilities
+	}
+	caps := newWdaBody()
+	if len(capabilities) != 0 {
+		if _, ok := capabilities[0]["defaultAlertAction"]; ok {
+			return capabilities
+		}
+		for k, v := range capabilities[0] {
+			caps[k] = v
+		}
+	}
+	caps.set("defaultAlertAction", cfg.DefaultAlertAction)
+	return []WDASessionCapability{WDASessionCapability(caps)}
+}
+
+// applyToSession the settings of a new session
+func (cfg *WDAConfig) applyToSession(s *Session) error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.AutoRecover != nil {
+		s.SetAutoRecover(*cfg.AutoRecover)
+	}
+	settings := NewWDASettings()
+	if cfg.MJPEG.Framerate > 0 {
+		settings.SetMjpegServerFramerate(cfg.MJPEG.Framerate)
+	}
+	if cfg.MJPEG.ScreenshotQuality > 0 {
+		settings.SetMjpegServerScreenshotQuality(cfg.MJPEG.ScreenshotQuality)
+	}
+	if cfg.MJPEG.ScalingFactor > 0 {
+		settings.SetMjpegScalingFactor(cfg.MJPEG.ScalingFactor)
+	}
+	return s.ApplySettings(settings)
+}
@@ -0,0 +1,50 @@
+package gwda
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	defer func() { _ = SetConfig(nil) }()
+
+	dir, err := ioutil.TempDir("", "gwda")
+	checkErr(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	filename := filepath.Join(dir, "gwda.json")
+	checkErr(t, ioutil.WriteFile(filename, []byte(`{
+	"waitTimeout": "30s",
+	"pingTimeout": 2,
+	"defaultAlertAction": "accept",
+	"shard": {"retries": 3, "artifactDir": "build"}
+}`), 0644))
+	cfg, err := LoadConfig(filename)
+	checkErr(t, err)
+	if waitTimeout() != 30*time.Second || pingTimeout() != 2*time.Second || waitInterval() != DefaultWaitInterval {
+		t.Errorf("defaults: %v %v %v", waitTimeout(), pingTimeout(), waitInterval())
+	}
+	if so := NewShardOption(); so.retries != 3 || so.artifactDir != "build" {
+		t.Errorf("shard option: %d %s", so.retries, so.artifactDir)
+	}
+	caps := cfg.sessionCapabilities([]WDASessionCapability{NewWDASessionCapability("com.apple.Preferences")})
+	if caps[0]["defaultAlertAction"] != WDASessionAlertActionAccept || caps[0]["bundleId"] != "com.apple.Preferences" {
+		t.Errorf("capabilities: %v", caps)
+	}
+
+	checkErr(t, ioutil.WriteFile(filename, []byte(`{"defaultAlertAction": "ignore"}`), 0644))
+	if _, err = LoadConfig(filename); err == nil {
+		t.Error("expected an error for an unknown alert action")
+	}
+	checkErr(t, ioutil.WriteFile(filename, []byte(`{"waitTimout": "1s"}`), 0644))
+	if _, err = LoadConfig(filename); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+
+	checkErr(t, SetConfig(nil))
+	if waitTimeout() != DefaultWaitTimeout || pingTimeout() != DefaultPingTimeout {
+		t.Errorf("the built-in defaults must be restored: %v %v", waitTimeout(), pingTimeout())
+	}
+}
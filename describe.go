@@ -0,0 +1,177 @@
+package gwda
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+)
+
+// DescribeInteractableTypes element types (short names) listed by DescribeScreen
+var DescribeInteractableTypes = map[string]bool{
+	"Button": true, "Cell": true, "Link": true, "Switch": true, "Slider": true, "Stepper": true,
+	"TextField": true, "SecureTextField": true, "SearchField": true, "TextView": true,
+	"SegmentedControl": true, "PickerWheel": true, "Tab": true,
+}
+
+// WDAScreenDescription
+type WDAScreenDescription struct {
+	Time time.Time
+	App  WDAActiveAppInfo
+	// Fingerprint of the structure (types and identifiers of the visible elements), the same screen with other texts keeps it
+	Fingerprint string
+	Texts       []string // visible static texts, in order
+	Elements    []WDADescribedElement
+	Screenshot  []byte // PNG
+}
+
+// WDADescribedElement a visible element one can interact with
+type WDADescribedElement struct {
+	Type      string // e.g. `XCUIElementTypeButton`
+	Label     string
+	Value     string
+	Rect      WDARect
+	IsEnabled bool
+	// Locator suggested to find it, the most readable one which is unique on this screen
+	Locator WDALocator
+}
+
+// DescribeScreen
+//
+// a human readable summary of the screen, see WDAScreenDescription.Markdown and WDAScreenDescription.HTML
+func (s *Session) DescribeScreen() (description WDAScreenDescription, err error) {
+	description.Time = time.Now()
+	if description.App, err = s.ActiveAppInfo(); err != nil {
+		return WDAScreenDescription{}, err
+	}
+	var root *WDASourceNode
+	if root, err = s.SourceTree(); err != nil {
+		return WDAScreenDescription{}, err
+	}
+	var raw *bytes.Buffer
+	if raw, err = s.Screenshot(); err != nil {
+		return WDAScreenDescription{}, err
+	}
+	description.Screenshot = raw.Bytes()
+	description.describe(root)
+	return
+}
+
+func (d *WDAScreenDescription) describe(root *WDASourceNode) {
+	var all []*WDASourceNode
+	root.Walk(func(node *WDASourceNode) bool {
+		all = append(all, node)
+		return true
+	})
+	names := make(map[string]int)
+	labels := make(map[string]int)
+	for _, node := range all {
+		names[node.Name]++
+		labels[node.Type+"\x00"+node.Label]++
+	}
+
+	hash := sha1.New()
+	seenTexts := make(map[string]bool)
+	typeIndex := make(map[string]int)
+	for _, node := range all {
+		typeIndex[node.Type]++
+		if !node.IsVisible {
+			continue
+		}
+		_, _ = fmt.Fprintf(hash, "%s:%s;", node.Type, node.Name)
+
+		if node.Type == "StaticText" && node.Label != "" && !seenTexts[node.Label] {
+			seenTexts[node.Label] = true
+			d.Texts = append(d.Texts, node.Label)
+		}
+		if !DescribeInteractableTypes[node.Type] {
+			continue
+		}
+		elem := WDADescribedElement{Type: node.ElementType(), Label: node.Label, Value: node.Value, Rect: node.Rect, IsEnabled: node.IsEnabled}
+		switch {
+		case node.Name != "" && names[node.Name] == 1:
+			elem.Locator = WDALocator{AccessibilityId: node.Name}
+		case node.Label != "" && labels[node.Type+"\x00"+node.Label] == 1:
+			elem.Locator = WDALocator{Predicate: fmt.Sprintf("type == '%s' AND label == %s", node.ElementType(), predicateString(node.Label))}
+		default:
+			elem.Locator = WDALocator{ClassChain: fmt.Sprintf("**/%s[%d]", node.ElementType(), typeIndex[node.Type])}
+		}
+		d.Elements = append(d.Elements, elem)
+	}
+	d.Fingerprint = hex.EncodeToString(hash.Sum(nil))[:12]
+}
+
+func (de WDADescribedElement) LocatorString() string {
+	using, value := de.Locator.getUsingAndValue()
+	return using + ": " + value
+}
+
+// Markdown
+//
+// `screenshotLink` where the caller saved the screenshot, `""` embeds it as a data URI
+func (d WDAScreenDescription) Markdown(screenshotLink string) string {
+	buf := new(bytes.Buffer)
+	_, _ = fmt.Fprintf(buf, "# %s (`%s`)\n\n", d.App.Name, d.App.BundleID)
+	_, _ = fmt.Fprintf(buf, "- Time: %s\n- Fingerprint: `%s`\n\n", d.Time.Format(time.RFC3339), d.Fingerprint)
+	if screenshotLink == "" {
+		screenshotLink = d.screenshotDataURI()
+	}
+	if screenshotLink != "" {
+		_, _ = fmt.Fprintf(buf, "![screenshot](%s)\n\n", screenshotLink)
+	}
+	buf.WriteString("## Texts\n\n")
+	for _, text := range d.Texts {
+		_, _ = fmt.Fprintf(buf, "- %s\n", markdownEscaper.Replace(text))
+	}
+	buf.WriteString("\n## Elements\n\n| Type | Label | Value | Rect | Enabled | Locator |\n| --- | --- | --- | --- | --- | --- |\n")
+	for _, elem := range d.Elements {
+		_, _ = fmt.Fprintf(buf, "| %s | %s | %s | %d,%d %dx%d | %t | `%s` |\n",
+			strings.TrimPrefix(elem.Type, "XCUIElementType"), markdownEscaper.Replace(elem.Label), markdownEscaper.Replace(elem.Value),
+			elem.Rect.X, elem.Rect.Y, elem.Rect.Width, elem.Rect.Height, elem.IsEnabled,
+			strings.Replace(elem.LocatorString(), "|", `\|`, -1))
+	}
+	return buf.String()
+}
+
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\n", " ", "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`)
+
+func (d WDAScreenDescription) screenshotDataURI() string {
+	if len(d.Screenshot) == 0 {
+		return ""
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(d.Screenshot)
+}
+
+var describeHTMLTemplate = template.Must(template.New("screen").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.App.Name}}</title></head>
+<body>
+<h1>{{.App.Name}} <code>{{.App.BundleID}}</code></h1>
+<p>Time: {{.Time.Format "2006-01-02T15:04:05Z07:00"}}<br>Fingerprint: <code>{{.Fingerprint}}</code></p>
+{{with .ScreenshotURI}}<img src="{{.}}" alt="screenshot" style="max-height: 640px">{{end}}
+<h2>Texts</h2>
+<ul>{{range .Texts}}<li>{{.}}</li>{{end}}</ul>
+<h2>Elements</h2>
+<table border="1">
+<tr><th>Type</th><th>Label</th><th>Value</th><th>Rect</th><th>Enabled</th><th>Locator</th></tr>
+{{range .Elements}}<tr><td>{{.Type}}</td><td>{{.Label}}</td><td>{{.Value}}</td><td>{{.Rect.X}},{{.Rect.Y}} {{.Rect.Width}}x{{.Rect.Height}}</td><td>{{.IsEnabled}}</td><td><code>{{.LocatorString}}</code></td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// HTML
+//
+// a standalone page, the screenshot is embedded
+func (d WDAScreenDescription) HTML() string {
+	buf := new(bytes.Buffer)
+	_ = describeHTMLTemplate.Execute(buf, struct {
+		WDAScreenDescription
+		ScreenshotURI template.URL
+	}{d, template.URL(d.screenshotDataURI())})
+	return buf.String()
+}
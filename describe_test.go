@@ -0,0 +1,72 @@
+package gwda
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const testSourceJSON = `{"type":"Application","name":"Settings","label":"Settings","isEnabled":"1","isVisible":"1","rect":{"x":0,"y":0,"width":375,"height":812},
+"children":[
+ {"type":"StaticText","name":null,"label":"Settings","value":null,"isEnabled":"1","isVisible":"1","rect":{"x":16,"y":96,"width":120,"height":40}},
+ {"type":"Button","name":"General","label":"General","isEnabled":"1","isVisible":"1","rect":{"x":0,"y":200,"width":375,"height":44}},
+ {"type":"Cell","name":null,"label":"Wi-Fi","value":1,"isEnabled":"1","isVisible":"1","rect":{"x":0,"y":244,"width":375,"height":44}},
+ {"type":"Cell","name":null,"label":null,"isEnabled":"0","isVisible":"1","rect":{"x":0,"y":288,"width":375,"height":44}},
+ {"type":"Button","name":"Hidden","label":"Hidden","isEnabled":"1","isVisible":"0","rect":{"x":0,"y":0,"width":0,"height":0}}
+]}`
+
+func TestWDASourceNode_UnmarshalJSON(t *testing.T) {
+	root := new(WDASourceNode)
+	checkErr(t, json.Unmarshal([]byte(testSourceJSON), root))
+	if len(root.Children) != 5 || !root.IsVisible {
+		t.Fatalf("%+v", root)
+	}
+	cell := root.Children[2]
+	if cell.Value != "1" || cell.Name != "" || !cell.IsEnabled || cell.Rect.Y != 244 {
+		t.Errorf("%+v", cell)
+	}
+	if root.Children[3].IsEnabled || root.Children[4].IsVisible {
+		t.Error("flags")
+	}
+}
+
+func TestWDAScreenDescription_Markdown(t *testing.T) {
+	root := new(WDASourceNode)
+	checkErr(t, json.Unmarshal([]byte(testSourceJSON), root))
+	var d WDAScreenDescription
+	d.describe(root)
+
+	if len(d.Texts) != 1 || d.Texts[0] != "Settings" {
+		t.Error("texts:", d.Texts)
+	}
+	if len(d.Elements) != 3 {
+		t.Fatalf("%+v", d.Elements)
+	}
+	for i, want := range []string{
+		"accessibility id: General",
+		"predicate string: type == 'XCUIElementTypeCell' AND label == 'Wi-Fi'",
+		"class chain: **/XCUIElementTypeCell[2]",
+	} {
+		if got := d.Elements[i].LocatorString(); got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+	md := d.Markdown("screenshot.png")
+	if !strings.Contains(md, "![screenshot](screenshot.png)") || !strings.Contains(md, "| Cell | Wi-Fi | 1 |") {
+		t.Error(md)
+	}
+	if html := d.HTML(); !strings.Contains(html, "<td>XCUIElementTypeButton</td><td>General</td>") {
+		t.Error(html)
+	}
+}
+
+func TestSession_DescribeScreen(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+
+	description, err := s.DescribeScreen()
+	checkErr(t, err)
+	t.Log(description.Markdown("screenshot.png"))
+}
@@ -1,6 +1,13 @@
 package gwda
 
-import goUSBMux "github.com/electricbubble/go-usbmuxd-device"
+import (
+	"context"
+	"net"
+	"net/http"
+	"regexp"
+
+	goUSBMux "github.com/electricbubble/go-usbmuxd-device"
+)
 
 type Device struct {
 	deviceID                         int
@@ -35,3 +42,30 @@ func (d Device) DeviceID() int {
 func (d Device) SerialNumber() string {
 	return d.serialNumber
 }
+
+// dialUSB
+//
+// opens a new usbmuxd tunnel to `port` on the device, no `iproxy` required
+func (d Device) dialUSB(port int) (net.Conn, error) {
+	return goUSBMux.NewUSBHub().CreateConnect(d.deviceID, port)
+}
+
+// newUSBHTTPClient
+//
+// Every dial creates a fresh usbmuxd connection, so keep-alive and reconnects behave like a normal TCP transport.
+func newUSBHTTPClient(dev Device, port int) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(_ context.Context, _, _ string) (net.Conn, error) {
+				return dev.dialUSB(port)
+			},
+		},
+	}
+}
+
+// 40 hex characters (before iPhone XS), or `00008020-001234567890002E`
+var reUDID = regexp.MustCompile(`^([0-9a-fA-F]{40}|[0-9a-fA-F]{8}-[0-9a-fA-F]{16})$`)
+
+func isUDID(s string) bool {
+	return reUDID.MatchString(s)
+}
@@ -0,0 +1,42 @@
+package gwda
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DeviceManager
+//
+// holds one Client per device, for driving several devices in parallel
+type DeviceManager struct {
+	clients []*Client
+}
+
+// NewDeviceManager
+//
+// connects to every device, `opt` can be `nil`
+func NewDeviceManager(opt *WDAClientOption, deviceURLs ...string) (dm *DeviceManager, err error) {
+	if len(deviceURLs) == 0 {
+		return nil, errors.New("no device")
+	}
+	dm = &DeviceManager{clients: make([]*Client, len(deviceURLs))}
+	for i := range deviceURLs {
+		if dm.clients[i], err = NewClientWithOption(deviceURLs[i], opt); err != nil {
+			return nil, fmt.Errorf("device %s: %w", deviceURLs[i], err)
+		}
+	}
+	return
+}
+
+// Clients in the order of the device URLs
+func (dm *DeviceManager) Clients() []*Client {
+	clients := make([]*Client, len(dm.clients))
+	copy(clients, dm.clients)
+	return clients
+}
+
+// deviceName usable as a directory name, e.g. `192.168.1.2_8100` or the UDID
+func deviceName(c *Client) string {
+	return strings.Replace(c.deviceURL.Host, ":", "_", -1)
+}
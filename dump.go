@@ -0,0 +1,64 @@
+package gwda
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// wdaCallDump one file written to the dump directory of a Client
+type wdaCallDump struct {
+	Time         time.Time       `json:"time"`
+	Action       string          `json:"action"`
+	Method       string          `json:"method"`
+	URL          string          `json:"url"`
+	RequestBody  json.RawMessage `json:"requestBody,omitempty"`
+	StatusCode   int             `json:"statusCode"`
+	Duration     string          `json:"duration"`
+	ResponseBody json.RawMessage `json:"responseBody,omitempty"`
+	// ResponseText the response body when it is not valid JSON
+	ResponseText string `json:"responseText,omitempty"`
+	Streamed     bool   `json:"streamed,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// dumpCall writes `<seq>_<action>.json`, failures are only logged
+func (c *Client) dumpCall(call *wdaCall, statusCode int, duration time.Duration, respBody []byte, err error) {
+	dump := wdaCallDump{
+		Time:        call.start,
+		Action:      call.actionName,
+		Method:      call.method,
+		URL:         call.logURL,
+		RequestBody: call.reqBody,
+		StatusCode:  statusCode,
+		Duration:    duration.String(),
+	}
+	switch {
+	case respBody == nil && statusCode != 0:
+		dump.Streamed = true
+	case json.Valid(respBody):
+		dump.ResponseBody = respBody
+	default:
+		dump.ResponseText = string(respBody)
+	}
+	if err != nil {
+		dump.Error = err.Error()
+	}
+
+	bs, errMarshal := json.MarshalIndent(dump, "", "  ")
+	if errMarshal != nil {
+		log.Printf("[ERROR]↩︎\n[dump] %s %s\n", call.actionName, errMarshal.Error())
+		return
+	}
+	seq := atomic.AddUint32(&c.dumpSeq, 1)
+	filename := filepath.Join(c.dumpDir, fmt.Sprintf("%s_%06d_%s.json",
+		call.start.Format("20060102T150405"), seq, strings.Replace(call.actionName, string(filepath.Separator), "_", -1)))
+	if errWrite := ioutil.WriteFile(filename, bs, 0644); errWrite != nil {
+		log.Printf("[ERROR]↩︎\n[dump] %s %s\n", call.actionName, errWrite.Error())
+	}
+}
@@ -11,20 +11,55 @@ import (
 	"path"
 	"reflect"
 	"strconv"
+
+	"github.com/tidwall/gjson"
+)
+
+// The element errors of WDA, wrapped in the returned errors so that a retry can be chosen with errors.Is:
+// re-find after ErrStaleElement, scroll after ErrElementNotVisible, wait for an overlay to go after ErrElementNotInteractable.
+var (
+	// ErrNoSuchElement no element matches the locator
+	ErrNoSuchElement = errors.New("no such element")
+	// ErrStaleElement returned by the requests on an Element which no longer exists, e.g. after the UI was refreshed
+	ErrStaleElement = errors.New("stale element reference")
+	// ErrElementNotVisible the element exists but is not on the screen
+	ErrElementNotVisible = errors.New("element not visible")
+	// ErrElementNotInteractable the element is visible but can not be interacted with, e.g. covered or disabled
+	ErrElementNotInteractable = errors.New("element not interactable")
 )
 
 type Element struct {
+	client   *Client
 	endpoint *url.URL
 	UID      string
+
+	attributes map[string]gjson.Result // returned inline by the find, see Query.WithAttributes
 }
 
-func newElement(endpoint *url.URL, elemUID string) (elem *Element) {
+func newElement(c *Client, endpoint *url.URL, elemUID string) (elem *Element) {
 	elem = new(Element)
+	elem.client = c
 	elem.endpoint = endpoint
 	elem.UID = elemUID
 	return
 }
 
+// ElementID the id WDA gives an element, `ELEMENT` in its responses
+type ElementID string
+
+// ID of the element, its UID
+func (e *Element) ID() ElementID {
+	return ElementID(e.UID)
+}
+
+// NewElement
+//
+// Element of the session by its UID, e.g. an `ELEMENT` returned by a raw WDA call or kept by another tool.
+// Nothing is requested, the element may no longer exist.
+func NewElement(s *Session, elemUID string) *Element {
+	return newElement(s.client, s.sessionURL(), elemUID)
+}
+
 // /element/:uuid
 func (e *Element) _withFormat(elem ...string) string {
 	return path.Join(append([]string{"element", e.UID}, elem...)...)
@@ -38,18 +73,33 @@ func (e *Element) _withFormatToUrl(elem ...string) *url.URL {
 }
 
 func (e *Element) Tap(x, y int) error {
-	return tap(e.endpoint, x, y, e.UID)
+	return tap(e.client, e.endpoint, x, y, e.UID)
 }
 
 func (e *Element) TapFloat(x, y float64) error {
-	return tap(e.endpoint, x, y, e.UID)
+	return tap(e.client, e.endpoint, x, y, e.UID)
+}
+
+// TapWithOffset
+//
+// Taps at a fraction of the size of the element from its top-left corner, `(0.5, 0.5)` is the center,
+// e.g. `(0.9, 0.5)` for the right end of a custom slider. Tap and TapFloat take offsets in points.
+func (e *Element) TapWithOffset(dx, dy float64) (err error) {
+	if dx < 0 || dx > 1 || dy < 0 || dy > 1 {
+		return errors.New("'dx' and 'dy' must be in [0, 1]")
+	}
+	var rect WDARect
+	if rect, err = e.Rect(); err != nil {
+		return err
+	}
+	return e.TapFloat(float64(rect.Width)*dx, float64(rect.Height)*dy)
 }
 
 // DoubleTap
 //
 // Sends a double tap event to a hittable point computed for the element.
 func (e *Element) DoubleTap() error {
-	return doubleTap(e.endpoint, -1, -1, e._withFormat())
+	return doubleTap(e.client, e.endpoint, -1, -1, e._withFormat())
 }
 
 // TwoFingerTap
@@ -57,7 +107,7 @@ func (e *Element) DoubleTap() error {
 // Sends a two finger tap event to a hittable point computed for the element.
 func (e *Element) TwoFingerTap() (err error) {
 	// [FBRoute POST:@"/wda/element/:uuid/twoFingerTap"]
-	_, err = executePost("TwoFingerTap", urlJoin(e.endpoint, e._withFormat("/twoFingerTap"), true), nil)
+	_, err = executePost(e.client, "TwoFingerTap", urlJoin(e.endpoint, e._withFormat("/twoFingerTap"), true), nil)
 	return
 }
 
@@ -79,7 +129,7 @@ func (e *Element) TapWithNumberOfTaps(numberOfTaps, numberOfTouches int) (err er
 	}
 	body := newWdaBody().set("numberOfTaps", numberOfTaps).set("numberOfTouches", numberOfTouches)
 	// [FBRoute POST:@"/wda/element/:uuid/tapWithNumberOfTaps"]
-	_, err = executePost("TapWithNumberOfTaps", urlJoin(e.endpoint, e._withFormat("/tapWithNumberOfTaps"), true), body)
+	_, err = executePost(e.client, "TapWithNumberOfTaps", urlJoin(e.endpoint, e._withFormat("/tapWithNumberOfTaps"), true), body)
 	return
 }
 
@@ -90,14 +140,14 @@ func (e *Element) TouchAndHold(duration ...int) (err error) {
 	if len(duration) == 0 {
 		duration = []int{1}
 	}
-	return touchAndHold(e.endpoint, -1, -1, duration[0], e._withFormat())
+	return touchAndHold(e.client, e.endpoint, -1, -1, duration[0], e._withFormat())
 }
 
 func (e *Element) TouchAndHoldFloat(duration ...float64) (err error) {
 	if len(duration) == 0 {
 		duration = []float64{1.0}
 	}
-	return touchAndHold(e.endpoint, -1, -1, duration[0], e._withFormat())
+	return touchAndHold(e.client, e.endpoint, -1, -1, duration[0], e._withFormat())
 }
 
 func (e *Element) _forceTouch(wdaCoordinate WDACoordinate, pressure float64, duration ...float64) (err error) {
@@ -105,23 +155,30 @@ func (e *Element) _forceTouch(wdaCoordinate WDACoordinate, pressure float64, dur
 	if wdaCoordinate.X != -1 && wdaCoordinate.Y != -1 {
 		body.setXY(wdaCoordinate.X, wdaCoordinate.Y)
 	}
-	body.set("pressure", pressure)
 	if len(duration) == 0 {
 		duration = []float64{1.0}
 	}
+	if err = validateForceTouch(pressure, duration[0]); err != nil {
+		return err
+	}
+	body.set("pressure", pressure)
 	body.set("duration", duration[0])
 	// [FBRoute POST:@"/wda/element/:uuid/forceTouch"]
-	_, err = executePost("ForceTouch", urlJoin(e.endpoint, e._withFormat("/forceTouch"), true), body)
+	_, err = executePost(e.client, "ForceTouch", urlJoin(e.endpoint, e._withFormat("/forceTouch"), true), body)
 	return
 }
 
 // ForceTouch
 //
-// 3D Touch
+// 3D Touch, `pressure` is relative to the one of a regular force touch (`1.0`), `duration` in seconds (default 1).
+// Only the devices supporting 3D Touch honour the pressure, the others receive a long press.
 func (e *Element) ForceTouch(pressure float64, duration ...float64) (err error) {
 	return e._forceTouch(WDACoordinate{X: -1, Y: -1}, pressure, duration...)
 }
 
+// ForceTouchCoordinate
+//
+// like ForceTouch, at a coordinate relative to the element
 func (e *Element) ForceTouchCoordinate(wdaCoordinate WDACoordinate, pressure float64, duration ...float64) (err error) {
 	return e._forceTouch(wdaCoordinate, pressure, duration...)
 }
@@ -152,14 +209,14 @@ func (e *Element) Drag(fromX, fromY, toX, toY int, pressForDuration ...int) (err
 	if len(pressForDuration) == 0 {
 		pressForDuration = []int{1}
 	}
-	return drag(e.endpoint, fromX, fromY, toX, toY, pressForDuration[0], e._withFormat())
+	return drag(e.client, e.endpoint, fromX, fromY, toX, toY, pressForDuration[0], e._withFormat())
 }
 
 func (e *Element) DragFloat(fromX, fromY, toX, toY float64, pressForDuration ...float64) (err error) {
 	if len(pressForDuration) == 0 {
 		pressForDuration = []float64{1}
 	}
-	return drag(e.endpoint, fromX, fromY, toX, toY, pressForDuration[0], e._withFormat())
+	return drag(e.client, e.endpoint, fromX, fromY, toX, toY, pressForDuration[0], e._withFormat())
 }
 
 type WDASwipeDirection string
@@ -178,11 +235,11 @@ const (
 // 	element.frame.origin.x + [request.arguments[@"toX"] doubleValue]
 //	element.frame.origin.y + [request.arguments[@"toY"] doubleValue]
 func (e *Element) Swipe(fromX, fromY, toX, toY int) (err error) {
-	return drag(e.endpoint, fromX, fromY, toX, toY, 0, e._withFormat())
+	return drag(e.client, e.endpoint, fromX, fromY, toX, toY, 0, e._withFormat())
 }
 
 func (e *Element) SwipeFloat(fromX, fromY, toX, toY float64) (err error) {
-	return drag(e.endpoint, fromX, fromY, toX, toY, 0, e._withFormat())
+	return drag(e.client, e.endpoint, fromX, fromY, toX, toY, 0, e._withFormat())
 }
 
 // SwipeDirection
@@ -191,7 +248,21 @@ func (e *Element) SwipeFloat(fromX, fromY, toX, toY float64) (err error) {
 func (e *Element) SwipeDirection(direction WDASwipeDirection) (err error) {
 	body := newWdaBody().set("direction", direction)
 	// [FBRoute POST:@"/wda/element/:uuid/swipe"]
-	_, err = executePost("SwipeDirection", urlJoin(e.endpoint, e._withFormat("/swipe"), true), body)
+	_, err = executePost(e.client, "SwipeDirection", urlJoin(e.endpoint, e._withFormat("/swipe"), true), body)
+	return
+}
+
+// SwipeDirectionWithVelocity
+//
+// Like SwipeDirection, `velocity` in pixels per second (Xcode 11.4+), e.g. a slow swipe panning a map
+// instead of flinging it. WDA builds without support ignore it.
+func (e *Element) SwipeDirectionWithVelocity(direction WDASwipeDirection, velocity float64) (err error) {
+	if velocity <= 0 {
+		return errors.New("'velocity' must be greater than zero")
+	}
+	body := newWdaBody().set("direction", direction).set("velocity", velocity)
+	// [FBRoute POST:@"/wda/element/:uuid/swipe"]
+	_, err = executePost(e.client, "SwipeDirection", urlJoin(e.endpoint, e._withFormat("/swipe"), true), body)
 	return
 }
 
@@ -250,7 +321,7 @@ func (e *Element) Pinch(scale, velocity float64) (err error) {
 	}
 	body := newWdaBody().set("scale", scale).set("velocity", velocity)
 	// [FBRoute POST:@"/wda/element/:uuid/pinch"]
-	_, err = executePost("Pinch", urlJoin(e.endpoint, e._withFormat("/pinch"), true), body)
+	_, err = executePost(e.client, "Pinch", urlJoin(e.endpoint, e._withFormat("/pinch"), true), body)
 	return
 }
 
@@ -284,7 +355,7 @@ func (e *Element) PinchToZoomOutByActions(scale ...float64) (err error) {
 	offsetX, offsetY := float64(rect.Width)*r, float64(rect.Height)*r
 
 	actions := NewWDAActions().SwipeFloat(0-offsetX, 0-offsetY, 0, 0, e).SwipeFloat(offsetX, offsetY, 0, 0, e)
-	return performActions(e.endpoint, actions)
+	return performActions(e.client, e.endpoint, actions)
 }
 
 // Rotate
@@ -311,13 +382,13 @@ func (e *Element) Rotate(rotation float64, velocity ...float64) (err error) {
 	}
 	body := newWdaBody().set("rotation", rotation).set("velocity", velocity[0])
 	// [FBRoute POST:@"/wda/element/:uuid/rotate"]
-	_, err = executePost("Rotate", urlJoin(e.endpoint, e._withFormat("/rotate"), true), body)
+	_, err = executePost(e.client, "Rotate", urlJoin(e.endpoint, e._withFormat("/rotate"), true), body)
 	return
 }
 
 func (e *Element) _scroll(body wdaBody) (err error) {
 	// [FBRoute POST:@"/wda/element/:uuid/scroll"]
-	_, err = executePost("Scroll", urlJoin(e.endpoint, e._withFormat("/scroll"), true), body)
+	_, err = executePost(e.client, "Scroll", urlJoin(e.endpoint, e._withFormat("/scroll"), true), body)
 	return
 }
 
@@ -330,7 +401,16 @@ func (e *Element) ScrollElementByPredicate(predicate string) (err error) {
 	return e._scroll(newWdaBody().set("predicateString", predicate))
 }
 
+// ScrollToVisible
+//
+// Scrolls the closest scroll view until the element is visible, it must be a descendant of one,
+// e.g. a cell out of the screen before tapping it.
 func (e *Element) ScrollToVisible() (err error) {
+	// [FBRoute POST:@"/wda/element/:uuid/scrollTo"]
+	if _, err = executePost(e.client, "ScrollToVisible", urlJoin(e.endpoint, e._withFormat("/scrollTo"), true), nil); err == nil || !isUnsupportedCommand(err) {
+		return err
+	}
+	// older WDA builds
 	return e._scroll(newWdaBody().set("toVisible", true))
 }
 
@@ -373,7 +453,7 @@ func (e *Element) PickerWheelSelect(order WDAPickerWheelSelectOrder, offset ...i
 	}
 	body := newWdaBody().set("order", order).set("offset", float64(offset[0])*0.1)
 	// [FBRoute POST:@"/wda/pickerwheel/:uuid/select"]
-	_, err = executePost("PickerWheelSelect", urlJoin(e.endpoint, path.Join("/pickerwheel", e.UID, "/select"), true), body)
+	_, err = executePost(e.client, "PickerWheelSelect", urlJoin(e.endpoint, path.Join("/pickerwheel", e.UID, "/select"), true), body)
 	return
 }
 
@@ -393,18 +473,25 @@ func (e *Element) PickerWheelSelectPrevious(offset ...int) (err error) {
 
 func (e *Element) Click() (err error) {
 	// [FBRoute POST:@"/element/:uuid/click"]
-	_, err = executePost("Click", urlJoin(e.endpoint, e._withFormat("/click")), nil)
+	_, err = executePost(e.client, "Click", urlJoin(e.endpoint, e._withFormat("/click")), nil)
 	return
 }
 
+// SendKeys
+//
+// Types into the element, focusing it first. `typingFrequency` is the number of characters per second
+// (WDA defaults to 60), see SendKeysWithOption for long texts or a delay between the characters.
 func (e *Element) SendKeys(text string, typingFrequency ...int) error {
 	// [FBRoute POST:@"/element/:uuid/value"]
-	return sendKeys(urlJoin(e.endpoint, e._withFormat("/value")), text, typingFrequency...)
+	return sendKeys(e.client, urlJoin(e.endpoint, e._withFormat("/value")), text, typingFrequency...)
 }
 
+// Clear
+//
+// removes the text of a text field or view
 func (e *Element) Clear() (err error) {
 	// [FBRoute POST:@"/element/:uuid/clear"]
-	_, err = executePost("Clear", urlJoin(e.endpoint, e._withFormat("/clear")), nil)
+	_, err = executePost(e.client, "Clear", urlJoin(e.endpoint, e._withFormat("/clear")), nil)
 	return
 }
 
@@ -419,56 +506,113 @@ type WDARect struct {
 }
 
 func (e *Element) Rect() (wdaRect WDARect, err error) {
+	if v, ok := e.cachedAttribute("rect"); ok {
+		wdaRect.raw = json.RawMessage(v.Raw)
+		err = json.Unmarshal(wdaRect.raw, &wdaRect)
+		return
+	}
 	var wdaResp wdaResponse
 	// [FBRoute GET:@"/element/:uuid/rect"]
-	if wdaResp, err = executeGet("Rect", urlJoin(e.endpoint, e._withFormat("/rect"))); err != nil {
+	if wdaResp, err = executeGet(e.client, "Rect", urlJoin(e.endpoint, e._withFormat("/rect"))); err != nil {
 		return WDARect{}, err
 	}
-	wdaRect._string = wdaResp.getValue().String()
-	err = json.Unmarshal([]byte(wdaRect._string), &wdaRect)
+	wdaRect.raw, err = wdaResp.unmarshalValue(&wdaRect)
 	return
 }
 
 func (e *Element) IsEnabled() (isEnabled bool, err error) {
+	if v, ok := e.cachedAttribute("enabled"); ok {
+		return v.Bool(), nil
+	}
 	var wdaResp wdaResponse
 	// [FBRoute GET:@"/element/:uuid/enabled"]
-	if wdaResp, err = executeGet("IsEnabled", urlJoin(e.endpoint, e._withFormat("/enabled"))); err != nil {
+	if wdaResp, err = executeGet(e.client, "IsEnabled", urlJoin(e.endpoint, e._withFormat("/enabled"))); err != nil {
 		return false, err
 	}
-	return wdaResp.getValue().Bool(), nil
+	return wdaResp.valueBool(), nil
 }
 
 func (e *Element) IsDisplayed() (isDisplayed bool, err error) {
+	if v, ok := e.cachedAttribute("displayed"); ok {
+		return v.Bool(), nil
+	}
 	var wdaResp wdaResponse
 	// [FBRoute GET:@"/element/:uuid/displayed"]
-	if wdaResp, err = executeGet("IsDisplayed", urlJoin(e.endpoint, e._withFormat("/displayed"))); err != nil {
+	if wdaResp, err = executeGet(e.client, "IsDisplayed", urlJoin(e.endpoint, e._withFormat("/displayed"))); err != nil {
 		return false, err
 	}
-	return wdaResp.getValue().Bool(), nil
+	return wdaResp.valueBool(), nil
 }
 
 func (e *Element) IsSelected() (isSelected bool, err error) {
+	if v, ok := e.cachedAttribute("selected"); ok {
+		return v.Bool(), nil
+	}
 	var wdaResp wdaResponse
 	// [FBRoute GET:@"/element/:uuid/selected"]
-	if wdaResp, err = executeGet("IsSelected", urlJoin(e.endpoint, e._withFormat("/selected"))); err != nil {
+	if wdaResp, err = executeGet(e.client, "IsSelected", urlJoin(e.endpoint, e._withFormat("/selected"))); err != nil {
 		return false, err
 	}
-	return wdaResp.getValue().Bool(), nil
+	return wdaResp.valueBool(), nil
+}
+
+// IsStale
+//
+// Whether the element no longer exists. Any other failure to reach it is returned as an error.
+func (e *Element) IsStale() (isStale bool, err error) {
+	// [FBRoute GET:@"/element/:uuid/enabled"]
+	if _, err = executeGet(e.client, "IsStale", urlJoin(e.endpoint, e._withFormat("/enabled"))); err == nil {
+		return false, nil
+	}
+	if errors.Is(err, ErrStaleElement) {
+		return true, nil
+	}
+	return false, err
+}
+
+// IsVisible
+//
+// `visible` attribute, unlike IsDisplayed it is false for an element covered or scrolled out of the screen
+func (e *Element) IsVisible() (isVisible bool, err error) {
+	if v, ok := e.cachedAttribute("visible"); ok {
+		return v.Bool(), nil
+	}
+	var wdaResp wdaResponse
+	// [FBRoute GET:@"/element/:uuid/attribute/:name"]
+	if wdaResp, err = executeGet(e.client, "IsVisible", urlJoin(e.endpoint, e._withFormat("/attribute", "visible"))); err != nil {
+		return false, err
+	}
+	return wdaResp.valueBool(), nil
+}
+
+// IsHittable
+//
+// the element is visible and not covered by another one, a tap at its center reaches it
+func (e *Element) IsHittable() (isHittable bool, err error) {
+	if v, ok := e.cachedAttribute("hittable"); ok {
+		return v.Bool(), nil
+	}
+	var wdaResp wdaResponse
+	// [FBRoute GET:@"/element/:uuid/attribute/:name"]
+	if wdaResp, err = executeGet(e.client, "IsHittable", urlJoin(e.endpoint, e._withFormat("/attribute", "hittable"))); err != nil {
+		return false, err
+	}
+	return wdaResp.valueBool(), nil
 }
 
 func (e *Element) IsAccessible() (isAccessible bool, err error) {
 	var wdaResp wdaResponse
 	// [FBRoute GET:@"/wda/element/:uuid/accessible"]
-	if wdaResp, err = executeGet("IsAccessible", urlJoin(e.endpoint, e._withFormat("/accessible"), true)); err != nil {
+	if wdaResp, err = executeGet(e.client, "IsAccessible", urlJoin(e.endpoint, e._withFormat("/accessible"), true)); err != nil {
 		return false, err
 	}
-	return wdaResp.getValue().Bool(), nil
+	return wdaResp.valueBool(), nil
 }
 
 func (e *Element) IsAccessibilityContainer() (isAccessibilityContainer bool, err error) {
 	var wdaResp wdaResponse
 	// [FBRoute GET:@"/wda/element/:uuid/accessibilityContainer"]
-	if wdaResp, err = executeGet("IsAccessibilityContainer", urlJoin(e.endpoint, e._withFormat("/accessibilityContainer"), true)); err != nil {
+	if wdaResp, err = executeGet(e.client, "IsAccessibilityContainer", urlJoin(e.endpoint, e._withFormat("/accessibilityContainer"), true)); err != nil {
 		return false, err
 	}
 	return wdaResp.getValue().Bool(), nil
@@ -484,12 +628,15 @@ func (e *Element) GetAttribute(attr WDAElementAttribute) (value string, err erro
 	if attrName == "UNKNOWN" {
 		return "", errors.New("'WDAElementAttribute' does not have 'Attribute Name'")
 	}
+	if v, ok := e.cachedAttribute(attrName); ok {
+		return v.String(), nil
+	}
 	var wdaResp wdaResponse
 	// [FBRoute GET:@"/element/:uuid/attribute/:name"]
-	if wdaResp, err = executeGet("GetAttribute", urlJoin(e.endpoint, e._withFormat("/attribute", attrName))); err != nil {
+	if wdaResp, err = executeGet(e.client, "GetAttribute", urlJoin(e.endpoint, e._withFormat("/attribute", attrName))); err != nil {
 		return "", err
 	}
-	return wdaResp.getValue().String(), nil
+	return wdaResp.valueString()
 }
 
 func (e *Element) Name() (string, error) {
@@ -507,64 +654,81 @@ func (e *Element) Value() (string, error) {
 // Text
 // 	FBFirstNonEmptyValue(element.wdValue, element.wdLabel);
 func (e *Element) Text() (text string, err error) {
+	if v, ok := e.cachedAttribute("text"); ok {
+		return v.String(), nil
+	}
 	var wdaResp wdaResponse
 	// [FBRoute GET:@"/element/:uuid/text"]
-	if wdaResp, err = executeGet("Text", urlJoin(e.endpoint, e._withFormat("/text"))); err != nil {
+	if wdaResp, err = executeGet(e.client, "Text", urlJoin(e.endpoint, e._withFormat("/text"))); err != nil {
 		return "", err
 	}
-	return wdaResp.getValue().String(), nil
+	return wdaResp.valueString()
 }
 
 // Type
 //
 // Element's type ( WDAElementType )
 func (e *Element) Type() (elemType string, err error) {
+	if v, ok := e.cachedAttribute("type"); ok {
+		return v.String(), nil
+	}
 	var wdaResp wdaResponse
 	// [FBRoute GET:@"/element/:uuid/name"]
-	if wdaResp, err = executeGet("Type", urlJoin(e.endpoint, e._withFormat("/name"))); err != nil {
+	if wdaResp, err = executeGet(e.client, "Type", urlJoin(e.endpoint, e._withFormat("/name"))); err != nil {
 		return "", err
 	}
-	return wdaResp.getValue().String(), nil
+	return wdaResp.valueString()
 }
 
 // FindElement
+//
+// searches the descendants of this element only, e.g. a button of a cell
 func (e *Element) FindElement(wdaLocator WDALocator) (element *Element, err error) {
 	var elemUID string
 	// [FBRoute POST:@"/element/:uuid/element"]
-	if elemUID, err = findUidOfElement(e._withFormatToUrl(), wdaLocator); err != nil {
+	if elemUID, err = findUidOfElement(e.client, e._withFormatToUrl(), wdaLocator); err != nil {
 		return nil, err
 	}
-	return newElement(e.endpoint, elemUID), nil
+	return newElement(e.client, e.endpoint, elemUID), nil
 }
 
 // FindElements
+//
+// see FindElement
 func (e *Element) FindElements(wdaLocator WDALocator) (elements []*Element, err error) {
 	var elemUIDs []string
 	// [FBRoute POST:@"/element/:uuid/elements"]
-	if elemUIDs, err = findUidOfElements(e._withFormatToUrl(), wdaLocator); err != nil {
+	if elemUIDs, err = findUidOfElements(e.client, e._withFormatToUrl(), wdaLocator); err != nil {
 		return nil, err
 	}
 	elements = make([]*Element, len(elemUIDs))
 	for i := range elements {
-		elements[i] = newElement(e.endpoint, elemUIDs[i])
+		elements[i] = newElement(e.client, e.endpoint, elemUIDs[i])
 	}
 	return
 }
 
+// CountElements
+//
+// see Session.CountElements, among the descendants of this element
+func (e *Element) CountElements(wdaLocator WDALocator) (int, error) {
+	return countElements(e.client, e._withFormatToUrl(), wdaLocator)
+}
+
 // FindVisibleCells
 func (e *Element) FindVisibleCells() (elements []*Element, err error) {
 	var wdaResp wdaResponse
 	// [FBRoute GET:@"/wda/element/:uuid/getVisibleCells"]
-	if wdaResp, err = executeGet("FindVisibleCells", urlJoin(e.endpoint, e._withFormat("/getVisibleCells"), true)); err != nil {
+	if wdaResp, err = executeGet(e.client, "FindVisibleCells", urlJoin(e.endpoint, e._withFormat("/getVisibleCells"), true)); err != nil {
 		return nil, err
 	}
 	results := wdaResp.getValue().Array()
 	if len(results) == 0 {
-		return nil, errors.New("no such element: unable to find a cell element in this element")
+		return nil, fmt.Errorf("%w: unable to find a cell element in this element", ErrNoSuchElement)
 	}
 	elements = make([]*Element, len(results))
 	for i := range elements {
-		elements[i] = newElement(e.endpoint, results[i].Get("ELEMENT").String())
+		elements[i] = newElement(e.client, e.endpoint, elementUIDOf(results[i]))
 	}
 	return
 }
@@ -577,17 +741,17 @@ func (e *Element) FindVisibleCells() (elements []*Element, err error) {
 // Screenshot
 func (e *Element) Screenshot() (raw *bytes.Buffer, err error) {
 	// [FBRoute GET:@"/element/:uuid/screenshot"]
-	return screenshot(e._withFormatToUrl())
+	return screenshot(e.client, e._withFormatToUrl())
 }
 
 // ScreenshotToDisk
 func (e *Element) ScreenshotToDisk(filename string) (err error) {
-	return screenshotToDisk(e._withFormatToUrl(), filename)
+	return screenshotToDisk(e.client, e._withFormatToUrl(), filename)
 }
 
 // ScreenshotToImage
 func (e *Element) ScreenshotToImage() (img image.Image, format string, err error) {
-	return screenshotToImage(e._withFormatToUrl())
+	return screenshotToImage(e.client, e._withFormatToUrl())
 }
 
 func (e *Element) tttTmp() {
@@ -611,7 +775,7 @@ func (e *Element) tttTmp() {
 
 	var wdaResp wdaResponse
 	// [FBRoute POST:@"/wda/pickerwheel/:uuid/select"]
-	wdaResp, err = executePost("###############", urlJoin(e.endpoint, path.Join("/pickerwheel", e.UID, "/select"), true), body)
+	wdaResp, err = executePost(e.client, "###############", urlJoin(e.endpoint, path.Join("/pickerwheel", e.UID, "/select"), true), body)
 	_ = wdaResp
 	_ = err
 	// fmt.Println(err, wdaResp)
@@ -854,3 +1018,13 @@ type WDAElementType struct {
 	TouchBar           bool `json:"XCUIElementTypeTouchBar"`
 	StatusItem         bool `json:"XCUIElementTypeStatusItem"`
 }
+
+func validateForceTouch(pressure, duration float64) error {
+	if pressure <= 0 {
+		return errors.New("'pressure' must be greater than zero")
+	}
+	if duration <= 0 {
+		return errors.New("'duration' must be greater than zero")
+	}
+	return nil
+}
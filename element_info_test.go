@@ -0,0 +1,38 @@
+package gwda
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func Test_elementInfo(t *testing.T) {
+	var v map[string]json.RawMessage
+	checkErr(t, json.Unmarshal([]byte(`{"ELEMENT":"E1","type":"XCUIElementTypeCell","label":"Wi-Fi","rect":{"x":0,"y":100,"width":375,"height":44},
+		"enabled":true,"attribute/name":"wifi","attribute/value":"On","attribute/visible":"1"}`), &v))
+	elem := newElement(nil, nil, "E1")
+	elem.setAttributes(v)
+	if !hasElementInfo(elem) {
+		t.Fatal("expected the attributes to be returned with the element")
+	}
+
+	info, err := elementInfo(elem)
+	checkErr(t, err)
+	if info.Type != "XCUIElementTypeCell" || info.Name != "wifi" || info.Label != "Wi-Fi" || info.Value != "On" ||
+		info.Rect.Y != 100 || !info.IsEnabled || !info.IsVisible || info.Element != elem {
+		t.Fatalf("%+v", info)
+	}
+}
+
+func TestSession_ElementInfos(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	checkErr(t, s.AppLaunch("com.apple.Preferences"))
+
+	infos, err := s.ElementInfos(WDALocator{ClassName: WDAElementType{Cell: true}})
+	checkErr(t, err)
+	for _, info := range infos {
+		t.Log(info.Label, info.Value, info.Rect)
+	}
+}
@@ -0,0 +1,130 @@
+package gwda
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Source
+//
+// The source of the subtree rooted at this element, in the format of `srcOpt` (`xml` by default) like Session.Source.
+// When WDA has no scoped source, the whole JSON source is fetched and the subtree of the element
+// (same type and rect) is cut from it, in `json` or `xml` only.
+func (e *Element) Source(srcOpt ...WDASourceOption) (sTree string, err error) {
+	// [FBRoute GET:@"/element/:uuid/source"]
+	if sTree, err = source(e.client, e._withFormatToUrl(), srcOpt...); err == nil || !isUnsupportedCommand(err) {
+		return
+	}
+	var node *WDASourceNode
+	if node, err = e.SourceTree(); err != nil {
+		return "", err
+	}
+
+	format, excluded := "xml", ""
+	if len(srcOpt) != 0 {
+		if v, ok := srcOpt[0]["format"].(string); ok {
+			format = v
+		}
+		excluded, _ = srcOpt[0]["excluded_attributes"].(string)
+	}
+	switch format {
+	case "json":
+		var raw []byte
+		if raw, err = json.Marshal(node); err != nil {
+			return "", err
+		}
+		return string(raw), nil
+	case "xml":
+		return node.XML(strings.Split(excluded, ",")...), nil
+	default:
+		return "", fmt.Errorf("source of an element: unsupported format '%s'", format)
+	}
+}
+
+// SourceTree
+//
+// the node of this element in the JSON source of the session, with its descendants
+func (e *Element) SourceTree() (node *WDASourceNode, err error) {
+	var elemType string
+	if elemType, err = e.Type(); err != nil {
+		return nil, err
+	}
+	var rect WDARect
+	if rect, err = e.Rect(); err != nil {
+		return nil, err
+	}
+	var root *WDASourceNode
+	if root, err = sourceTree(e.client, e.endpoint); err != nil {
+		return nil, err
+	}
+	if node = findSourceNode(root, elemType, rect); node == nil {
+		return nil, fmt.Errorf("%w: %s %v of element %s is not in the source", ErrNoSuchElement, elemType, rect, e.UID)
+	}
+	return
+}
+
+// findSourceNode the deepest node of the type at this rect, a container often has the same rect as its only child
+func findSourceNode(root *WDASourceNode, elemType string, rect WDARect) (found *WDASourceNode) {
+	root.Walk(func(node *WDASourceNode) bool {
+		if node.ElementType() == elemType && sameRect(node.Rect, rect) {
+			found = node
+		}
+		return true
+	})
+	return
+}
+
+func sameRect(a, b WDARect) bool {
+	return a.WDACoordinate == b.WDACoordinate && a.Width == b.Width && a.Height == b.Height
+}
+
+// XML
+//
+// the node and its descendants in the `xml` format of WDA, without the attributes of `excludedAttributes`
+func (n *WDASourceNode) XML(excludedAttributes ...string) string {
+	excluded := make(map[string]bool, len(excludedAttributes))
+	for _, attr := range excludedAttributes {
+		excluded[strings.TrimSpace(attr)] = true
+	}
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	n.writeXML(&sb, excluded, 0)
+	return sb.String()
+}
+
+func (n *WDASourceNode) writeXML(sb *strings.Builder, excluded map[string]bool, depth int) {
+	indent := strings.Repeat("  ", depth)
+	sb.WriteString(indent + "<" + n.ElementType())
+	attributes := []struct{ name, value string }{
+		{"type", n.ElementType()},
+		{"name", n.Name},
+		{"label", n.Label},
+		{"value", n.Value},
+		{"enabled", strconv.FormatBool(n.IsEnabled)},
+		{"visible", strconv.FormatBool(n.IsVisible)},
+		{"x", strconv.Itoa(n.Rect.X)},
+		{"y", strconv.Itoa(n.Rect.Y)},
+		{"width", strconv.Itoa(n.Rect.Width)},
+		{"height", strconv.Itoa(n.Rect.Height)},
+	}
+	for _, attr := range attributes {
+		if excluded[attr.name] || (attr.value == "" && attr.name != "type") {
+			continue
+		}
+		sb.WriteString(" " + attr.name + `="`)
+		_ = xml.EscapeText(sb, []byte(attr.value))
+		sb.WriteString(`"`)
+	}
+	if len(n.Children) == 0 {
+		sb.WriteString("/>\n")
+		return
+	}
+	sb.WriteString(">\n")
+	for _, child := range n.Children {
+		child.writeXML(sb, excluded, depth+1)
+	}
+	sb.WriteString(indent + "</" + n.ElementType() + ">\n")
+}
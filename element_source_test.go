@@ -0,0 +1,41 @@
+package gwda
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestWDASourceNode_XML(t *testing.T) {
+	root := new(WDASourceNode)
+	checkErr(t, json.Unmarshal([]byte(`{"type":"Window","rect":{"x":0,"y":0,"width":375,"height":667},"isEnabled":"1","isVisible":"1","children":[
+		{"type":"Cell","rect":{"x":0,"y":100,"width":375,"height":44},"isEnabled":"1","isVisible":"1","children":[
+			{"type":"Cell","name":"General","label":"<General>","rect":{"x":0,"y":100,"width":375,"height":44},"isEnabled":"1","isVisible":"1"}]}]}`), root))
+
+	node := findSourceNode(root, "XCUIElementTypeCell", WDARect{WDACoordinate{X: 0, Y: 100}, WDASize{Width: 375, Height: 44}})
+	if node == nil || node.Name != "General" {
+		t.Fatal(node)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<XCUIElementTypeCell type="XCUIElementTypeCell" name="General" label="&lt;General&gt;" enabled="true" visible="true" y="100" width="375" height="44"/>
+`
+	if got := node.XML("x"); got != want {
+		t.Fatal(got)
+	}
+}
+
+func TestElement_Source(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	checkErr(t, s.AppLaunch("com.apple.Preferences"))
+
+	cell, err := s.FindElement(WDALocator{ClassName: WDAElementType{Cell: true}})
+	checkErr(t, err)
+	sTree, err := cell.Source()
+	checkErr(t, err)
+	t.Log(sTree)
+	sTree, err = cell.Source(NewWDASourceOption().SetFormatAsJson())
+	checkErr(t, err)
+	t.Log(sTree)
+}
@@ -1,6 +1,7 @@
 package gwda
 
 import (
+	"errors"
 	"math"
 	"os"
 	"path/filepath"
@@ -8,6 +9,20 @@ import (
 	"testing"
 )
 
+func TestNewElement(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	element, err := s.FindElement(WDALocator{ClassName: WDAElementType{Button: true}})
+	checkErr(t, err)
+
+	same := NewElement(s, element.UID)
+	rect, err := same.Rect()
+	checkErr(t, err)
+	t.Log(rect.RawJSON())
+}
+
 func TestElement_Tap(t *testing.T) {
 	c, err := NewClient(deviceURL)
 	checkErr(t, err)
@@ -364,6 +379,20 @@ func TestElement_IsEnabled(t *testing.T) {
 	t.Log(isEnabled)
 }
 
+func TestElement_IsVisible(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	_ = s.AppLaunch(bundleId)
+	element, err := s.FindElement(WDALocator{LinkText: NewWDAElementAttribute().SetValue("通知")})
+	checkErr(t, err)
+
+	isVisible, err := element.IsVisible()
+	checkErr(t, err)
+	t.Log(isVisible)
+}
+
 func TestElement_IsDisplayed(t *testing.T) {
 	c, err := NewClient(deviceURL)
 	checkErr(t, err)
@@ -654,3 +683,85 @@ func TestElement_Tmp(t *testing.T) {
 	// text, _ = element.Text()
 	// t.Log(text)
 }
+
+func TestElement_ScrollToVisible(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	checkErr(t, s.AppLaunch("com.apple.Preferences"))
+
+	cells, err := s.FindElements(WDALocator{ClassName: WDAElementType{Cell: true}})
+	checkErr(t, err)
+	last := cells[len(cells)-1]
+	checkErr(t, last.ScrollToVisible())
+	isVisible, err := last.IsVisible()
+	checkErr(t, err)
+	if !isVisible {
+		t.Fatal("expected the last cell to be visible")
+	}
+}
+
+func TestElement_SwipeDirectionWithVelocity(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	checkErr(t, s.AppLaunch("com.apple.Preferences"))
+
+	element, err := s.FindElement(WDALocator{ClassName: WDAElementType{Table: true}})
+	checkErr(t, err)
+	checkErr(t, element.SwipeDirectionWithVelocity(WDASwipeDirectionUp, 500))
+	if err = element.SwipeDirectionWithVelocity(WDASwipeDirectionDown, 0); err == nil {
+		t.Fatal("expected an error for a zero velocity")
+	}
+}
+
+func Test_validateForceTouch(t *testing.T) {
+	checkErr(t, validateForceTouch(1, 0.5))
+	if err := validateForceTouch(0, 1); err == nil {
+		t.Error("expected an error for a zero pressure")
+	}
+	if err := validateForceTouch(1, -1); err == nil {
+		t.Error("expected an error for a negative duration")
+	}
+}
+
+func TestElement_TapWithOffset(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	checkErr(t, s.AppLaunch("com.apple.Preferences"))
+
+	element, err := s.FindElement(WDALocator{ClassName: WDAElementType{Cell: true}})
+	checkErr(t, err)
+	if err = element.TapWithOffset(1.5, 0.5); err == nil {
+		t.Fatal("expected an error out of the element")
+	}
+	checkErr(t, element.TapWithOffset(0.9, 0.5))
+}
+
+func TestElement_IsStale(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	checkErr(t, s.AppLaunch("com.apple.Preferences"))
+
+	element, err := s.FindElement(WDALocator{ClassName: WDAElementType{Cell: true}})
+	checkErr(t, err)
+	isStale, err := element.IsStale()
+	checkErr(t, err)
+	if isStale {
+		t.Fatal("expected a fresh element")
+	}
+
+	checkErr(t, s.AppTerminate("com.apple.Preferences"))
+	if isStale, err = element.IsStale(); err != nil || !isStale {
+		t.Fatalf("expected a stale element, got %v, %v", isStale, err)
+	}
+	if _, err = element.Label(); !errors.Is(err, ErrStaleElement) {
+		t.Fatalf("expected %v, got %v", ErrStaleElement, err)
+	}
+}
@@ -0,0 +1,254 @@
+package gwda
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// WDAElementCondition checked by WaitForElement on the found elements
+type WDAElementCondition func(e *Element) (bool, error)
+
+// ElementDisplayed the element is visible
+func ElementDisplayed(e *Element) (bool, error) {
+	return e.IsDisplayed()
+}
+
+// ElementVisible the element is on the screen, see Element.IsVisible
+func ElementVisible(e *Element) (bool, error) {
+	return e.IsVisible()
+}
+
+// ElementHittable the element can be tapped, see Element.IsHittable
+func ElementHittable(e *Element) (bool, error) {
+	return e.IsHittable()
+}
+
+// ElementEnabled the element can be interacted with
+func ElementEnabled(e *Element) (bool, error) {
+	return e.IsEnabled()
+}
+
+// WDAElementTimeoutError returned by WaitForElement, WaitForElements and WaitUntilGone
+type WDAElementTimeoutError struct {
+	Locator WDALocator
+	UID     string // instead of Locator, for Element.WaitUntilGone
+	Gone    bool   // it was waited for the element to disappear
+	// the attribute waited for by Element.WaitForAttribute, with its last value
+	Attribute string
+	Value     string
+	Timeout   time.Duration
+	// the last response of WDA: `no such element`, or the error of the last condition checked.
+	// `nil` when the element was found but did not meet the conditions
+	LastErr error
+}
+
+func (e *WDAElementTimeoutError) Error() string {
+	var msg string
+	if e.UID != "" {
+		msg = fmt.Sprintf("element %s", e.UID)
+	} else {
+		using, value := e.Locator.getUsingAndValue()
+		msg = fmt.Sprintf("element using '%s', value '%s'", using, value)
+	}
+	if e.Gone {
+		msg += fmt.Sprintf(" still visible after %v", e.Timeout)
+	} else if e.Attribute != "" {
+		msg += fmt.Sprintf(" attribute '%s' still '%s' after %v", e.Attribute, e.Value, e.Timeout)
+	} else {
+		msg += fmt.Sprintf(" not ready after %v", e.Timeout)
+	}
+	if e.LastErr != nil {
+		msg += ": " + e.LastErr.Error()
+	}
+	return msg
+}
+
+func (e *WDAElementTimeoutError) Unwrap() error {
+	return e.LastErr
+}
+
+// WaitForElement
+//
+// Polls every `interval` (DefaultWaitInterval when `<= 0`) until the element is found and meets all `conditions`,
+// or fails with a WDAElementTimeoutError after `timeout`. The implicit timeout (see SetTimeouts) is not applied.
+//
+//	elem, err := s.WaitForElement(ByName("Continue"), 10*time.Second, 0, ElementDisplayed, ElementEnabled)
+func (s *Session) WaitForElement(wdaLocator WDALocator, timeout, interval time.Duration, conditions ...WDAElementCondition) (element *Element, err error) {
+	var elements []*Element
+	if elements, err = waitForElements(s.client, s.sessionURL(), s.sessionURL(), wdaLocator, timeout, interval, true, conditions); err != nil {
+		return nil, err
+	}
+	return elements[0], nil
+}
+
+// WaitForElements
+//
+// like WaitForElement, returns all the found elements meeting `conditions` as soon as there is at least one
+func (s *Session) WaitForElements(wdaLocator WDALocator, timeout, interval time.Duration, conditions ...WDAElementCondition) (elements []*Element, err error) {
+	return waitForElements(s.client, s.sessionURL(), s.sessionURL(), wdaLocator, timeout, interval, false, conditions)
+}
+
+// WaitForElement
+//
+// like Session.WaitForElement, among the descendants of this element
+func (e *Element) WaitForElement(wdaLocator WDALocator, timeout, interval time.Duration, conditions ...WDAElementCondition) (element *Element, err error) {
+	var elements []*Element
+	if elements, err = waitForElements(e.client, e.endpoint, e._withFormatToUrl(), wdaLocator, timeout, interval, true, conditions); err != nil {
+		return nil, err
+	}
+	return elements[0], nil
+}
+
+// WaitForElements
+//
+// like Session.WaitForElements, among the descendants of this element
+func (e *Element) WaitForElements(wdaLocator WDALocator, timeout, interval time.Duration, conditions ...WDAElementCondition) (elements []*Element, err error) {
+	return waitForElements(e.client, e.endpoint, e._withFormatToUrl(), wdaLocator, timeout, interval, false, conditions)
+}
+
+// waitForElements searches `baseUrl`, the session or an element, the found elements belong to `endpoint`
+func waitForElements(c *Client, endpoint, baseUrl *url.URL, wdaLocator WDALocator, timeout, interval time.Duration, first bool, conditions []WDAElementCondition) (elements []*Element, err error) {
+	if interval <= 0 {
+		interval = waitInterval()
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		var lastErr error
+		if elements, lastErr = pollElements(c, endpoint, baseUrl, wdaLocator, first, conditions); len(elements) != 0 {
+			return elements, nil
+		}
+		if lastErr != nil && !isNoSuchElement(lastErr) && !isStaleElement(lastErr) {
+			return nil, lastErr
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return nil, &WDAElementTimeoutError{Locator: wdaLocator, Timeout: timeout, LastErr: lastErr}
+		}
+		time.Sleep(interval)
+	}
+}
+
+// pollElements the found elements meeting `conditions`, only the first match is looked for when `first` without conditions
+func pollElements(c *Client, endpoint, baseUrl *url.URL, wdaLocator WDALocator, first bool, conditions []WDAElementCondition) (elements []*Element, err error) {
+	var elemUIDs []string
+	if first && len(conditions) == 0 {
+		var elemUID string
+		if elemUID, err = findUidOfElement(c, baseUrl, wdaLocator); err != nil {
+			return nil, err
+		}
+		elemUIDs = []string{elemUID}
+	} else if elemUIDs, err = findUidOfElements(c, baseUrl, wdaLocator); err != nil {
+		return nil, err
+	}
+
+	for _, elemUID := range elemUIDs {
+		element := newElement(c, endpoint, elemUID)
+		met := true
+		for _, condition := range conditions {
+			if met, err = condition(element); err != nil || !met {
+				break
+			}
+		}
+		if err != nil && !isStaleElement(err) {
+			return nil, err
+		}
+		if met && err == nil {
+			elements = append(elements, element)
+		}
+	}
+	return elements, err
+}
+
+// WaitUntilGone
+//
+// Polls every `interval` (DefaultWaitInterval when `<= 0`) until no element matches, or none of them is displayed,
+// e.g. a loading spinner. Fails with a WDAElementTimeoutError after `timeout`.
+func (s *Session) WaitUntilGone(wdaLocator WDALocator, timeout, interval time.Duration) (err error) {
+	return waitUntilGone(timeout, interval, &WDAElementTimeoutError{Locator: wdaLocator, Gone: true, Timeout: timeout},
+		func() (bool, error) {
+			elemUIDs, err := findUidOfElements(s.client, s.sessionURL(), wdaLocator)
+			if err != nil {
+				if isNoSuchElement(err) {
+					return true, nil
+				}
+				return false, err
+			}
+			for _, elemUID := range elemUIDs {
+				if gone, err := isElementGone(newElement(s.client, s.sessionURL(), elemUID)); err != nil || !gone {
+					return false, err
+				}
+			}
+			return true, nil
+		})
+}
+
+// WaitUntilGone
+//
+// like Session.WaitUntilGone, until this element no longer exists or is not displayed
+func (e *Element) WaitUntilGone(timeout, interval time.Duration) (err error) {
+	return waitUntilGone(timeout, interval, &WDAElementTimeoutError{UID: e.UID, Gone: true, Timeout: timeout},
+		func() (bool, error) {
+			return isElementGone(e)
+		})
+}
+
+// WaitForAttribute
+//
+// Polls the attribute `name` (e.g. `value`, `label`, `enabled`) every DefaultWaitInterval until `predicate` accepts it,
+// and returns that value, or fails with a WDAElementTimeoutError after `timeout`.
+// The attribute is always requested from WDA, the values cached by Query are not used.
+//
+//	_, err := elemProgress.WaitForAttribute("value", func(v string) bool { return v == "100%" }, 30*time.Second)
+func (e *Element) WaitForAttribute(name string, predicate func(value string) bool, timeout time.Duration) (value string, err error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		var wdaResp wdaResponse
+		// [FBRoute GET:@"/element/:uuid/attribute/:name"]
+		if wdaResp, err = executeGet(e.client, "GetAttribute", urlJoin(e.endpoint, e._withFormat("/attribute", name))); err != nil {
+			return "", err
+		}
+		if value, err = wdaResp.valueString(); err != nil {
+			return "", err
+		}
+		if predicate(value) {
+			return value, nil
+		}
+		if time.Now().Add(waitInterval()).After(deadline) {
+			return value, &WDAElementTimeoutError{UID: e.UID, Attribute: name, Value: value, Timeout: timeout}
+		}
+		time.Sleep(waitInterval())
+	}
+}
+
+func waitUntilGone(timeout, interval time.Duration, errTimeout *WDAElementTimeoutError, gone func() (bool, error)) error {
+	if interval <= 0 {
+		interval = waitInterval()
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		if ok, err := gone(); err != nil || ok {
+			return err
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return errTimeout
+		}
+		time.Sleep(interval)
+	}
+}
+
+func isElementGone(e *Element) (bool, error) {
+	displayed, err := e.IsDisplayed()
+	if err != nil {
+		if isStaleElement(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	return !displayed, nil
+}
+
+// isStaleElement the element is gone since it was found
+func isStaleElement(err error) bool {
+	return errors.Is(err, ErrStaleElement)
+}
@@ -0,0 +1,87 @@
+package gwda
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWDAElementTimeoutError(t *testing.T) {
+	errNoSuchElement := errors.New("no such element: unable to find an element")
+	err := error(&WDAElementTimeoutError{Locator: ByName("Continue"), Timeout: time.Second, LastErr: errNoSuchElement})
+	if !errors.Is(err, errNoSuchElement) {
+		t.Fatal("expected the last error to be wrapped")
+	}
+	if want := "element using 'name', value 'Continue' not ready after 1s: no such element: unable to find an element"; err.Error() != want {
+		t.Fatal(err)
+	}
+	if want := "element 5D000000-0000-0000 still visible after 2s"; (&WDAElementTimeoutError{UID: "5D000000-0000-0000", Gone: true, Timeout: 2 * time.Second}).Error() != want {
+		t.Fatal(want)
+	}
+	if want := "element 5D000000-0000-0000 attribute 'value' still '42%' after 3s"; (&WDAElementTimeoutError{UID: "5D000000-0000-0000", Attribute: "value", Value: "42%", Timeout: 3 * time.Second}).Error() != want {
+		t.Fatal(want)
+	}
+}
+
+func TestSession_WaitForElement(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	checkErr(t, s.AppLaunch("com.apple.Preferences"))
+
+	element, err := s.WaitForElement(ByName("General"), 10*time.Second, 0, ElementDisplayed)
+	checkErr(t, err)
+	checkErr(t, element.Click())
+
+	_, err = s.WaitForElements(ByName("gwda-missing"), 2*time.Second, 500*time.Millisecond)
+	var errTimeout *WDAElementTimeoutError
+	if !errors.As(err, &errTimeout) || !isNoSuchElement(errTimeout.LastErr) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+}
+
+func TestElement_WaitForElement(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	checkErr(t, s.AppLaunch("com.apple.Preferences"))
+
+	cell, err := s.WaitForElement(ByClassChain(NewWDAClassChain().Descendant(WDAElementType{Cell: true}).Index(1)), 10*time.Second, 0)
+	checkErr(t, err)
+	text, err := cell.WaitForElement(WDALocator{ClassName: WDAElementType{StaticText: true}}, 5*time.Second, 0, ElementDisplayed)
+	checkErr(t, err)
+	t.Log(text.Label())
+}
+
+func TestSession_WaitUntilGone(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	checkErr(t, s.AppLaunch("com.apple.Preferences"))
+
+	checkErr(t, s.WaitUntilGone(ByName("gwda-missing"), time.Second, 0))
+	cell, err := s.FindElement(WDALocator{ClassName: WDAElementType{Cell: true}})
+	checkErr(t, err)
+	err = cell.WaitUntilGone(time.Second, 0)
+	var errTimeout *WDAElementTimeoutError
+	if !errors.As(err, &errTimeout) || !errTimeout.Gone {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+}
+
+func TestElement_WaitForAttribute(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	checkErr(t, s.AppLaunch("com.apple.Preferences"))
+
+	cell, err := s.WaitForElement(ByClassChain(NewWDAClassChain().Descendant(WDAElementType{Cell: true}).Index(1)), 10*time.Second, 0)
+	checkErr(t, err)
+	value, err := cell.WaitForAttribute("enabled", func(v string) bool { return v == "true" }, 5*time.Second)
+	checkErr(t, err)
+	t.Log(value)
+}
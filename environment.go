@@ -0,0 +1,141 @@
+package gwda
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WDASimulatedLocation
+type WDASimulatedLocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Altitude  float64 `json:"altitude"`
+}
+
+// SimulatedLocation
+//
+// `nil` when no location is simulated. Needs a WDA build with `/wda/simulatedLocation` (Xcode 14.3+).
+func (s *Session) SimulatedLocation() (location *WDASimulatedLocation, err error) {
+	var wdaResp wdaResponse
+	if wdaResp, err = executeGet(s.client, "SimulatedLocation", urlJoin(s.sessionURL(), "/wda/simulatedLocation")); err != nil {
+		return nil, err
+	}
+	var value struct {
+		Latitude  *float64 `json:"latitude"`
+		Longitude *float64 `json:"longitude"`
+		Altitude  *float64 `json:"altitude"`
+	}
+	if _, err = wdaResp.unmarshalValue(&value); err != nil {
+		return nil, err
+	}
+	if value.Latitude == nil || value.Longitude == nil {
+		return nil, nil
+	}
+	location = &WDASimulatedLocation{Latitude: *value.Latitude, Longitude: *value.Longitude}
+	if value.Altitude != nil {
+		location.Altitude = *value.Altitude
+	}
+	return
+}
+
+// WDAEnvironment
+//
+// the conditions of the device at a time, see Session.EnvironmentSnapshot
+type WDAEnvironment struct {
+	Time        time.Time             `json:"time"`
+	Origin      WDAOrigin             `json:"origin"`
+	Orientation WDAOrientation        `json:"orientation,omitempty"`
+	Appearance  string                `json:"appearance,omitempty"` // `light`, `dark` or `unsupported`
+	Locked      bool                  `json:"locked"`
+	Location    *WDASimulatedLocation `json:"location,omitempty"` // `nil` when none is simulated
+	ActiveApp   WDAActiveAppInfo      `json:"activeApp"`
+	Settings    *WDASettings          `json:"settings,omitempty"`
+	// the readbacks which failed (e.g. `location` on older WDA builds), their fields are left empty
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+func (env WDAEnvironment) String() string {
+	bs, _ := json.Marshal(env)
+	return string(bs)
+}
+
+// EnvironmentSnapshot
+//
+// Reads the orientation, appearance, lock state, simulated location, active application and settings
+// in parallel, e.g. to log the preconditions of a test or what the device looked like when it failed.
+// An error is only returned when every readback failed.
+func (s *Session) EnvironmentSnapshot() (env WDAEnvironment, err error) {
+	env.Time = time.Now()
+	env.Origin = s.Origin()
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	readbacks := 0
+	readback := func(name string, fn func() error) {
+		readbacks++
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errRead := fn()
+			mutex.Lock()
+			defer mutex.Unlock()
+			if errRead != nil {
+				if env.Errors == nil {
+					env.Errors = make(map[string]string)
+				}
+				env.Errors[name] = errRead.Error()
+			}
+		}()
+	}
+
+	var orientation WDAOrientation
+	var appearance string
+	var locked bool
+	var location *WDASimulatedLocation
+	var activeApp WDAActiveAppInfo
+	var settings *WDASettings
+	readback("orientation", func() (err error) {
+		orientation, err = s.Orientation()
+		return
+	})
+	readback("appearance", func() (err error) {
+		var info WDADeviceInfo
+		// not cached, it changes with the settings of the device
+		info, err = deviceInfo(s.client, s.sessionURL())
+		appearance = info.UserInterfaceStyle
+		return
+	})
+	readback("locked", func() (err error) {
+		locked, err = s.IsLocked()
+		return
+	})
+	readback("location", func() (err error) {
+		location, err = s.SimulatedLocation()
+		return
+	})
+	readback("activeApp", func() (err error) {
+		activeApp, err = s.ActiveAppInfo()
+		return
+	})
+	readback("settings", func() (err error) {
+		settings, err = s.Settings()
+		return
+	})
+	wg.Wait()
+
+	env.Orientation, env.Appearance, env.Locked, env.Location = orientation, appearance, locked, location
+	env.ActiveApp, env.Settings = activeApp, settings
+	if len(env.Errors) == readbacks {
+		names := make([]string, 0, len(env.Errors))
+		for name := range env.Errors {
+			names = append(names, name+": "+env.Errors[name])
+		}
+		sort.Strings(names)
+		return env, errors.New("environment snapshot: " + strings.Join(names, "; "))
+	}
+	return env, nil
+}
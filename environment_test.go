@@ -0,0 +1,19 @@
+package gwda
+
+import (
+	"testing"
+)
+
+func TestSession_EnvironmentSnapshot(t *testing.T) {
+	client, err := NewClient(deviceURL)
+	checkErr(t, err)
+	session, err := client.NewSession()
+	checkErr(t, err)
+
+	env, err := session.EnvironmentSnapshot()
+	checkErr(t, err)
+	t.Log(env)
+	if env.ActiveApp.BundleID == "" || env.Orientation == "" {
+		t.Fatalf("incomplete snapshot: %v", env)
+	}
+}
@@ -0,0 +1,62 @@
+package gwda
+
+// FindByAccessibilityID
+//
+// the element whose accessibility identifier is `id`, the fastest lookup of WDA
+func (s *Session) FindByAccessibilityID(id string) (*Element, error) {
+	return s.FindElement(WDALocator{AccessibilityId: id})
+}
+
+// FindByName
+//
+// the element whose `name` is `name`: its accessibility identifier, or its label when it has none
+func (s *Session) FindByName(name string) (*Element, error) {
+	return s.FindElement(ByName(name))
+}
+
+// FindByLabel
+//
+// the element whose `label` (the text read by VoiceOver) is `label`, see ByLabel
+func (s *Session) FindByLabel(label string) (*Element, error) {
+	return s.FindElement(ByLabel(label))
+}
+
+// FindByType
+//
+// the first element of the type, e.g. `WDAElementType{NavigationBar: true}`
+func (s *Session) FindByType(elemType WDAElementType) (*Element, error) {
+	return s.FindElement(WDALocator{ClassName: elemType})
+}
+
+// DefaultTextAttributes searched by FindByTextContains and FindByTextMatches without attributes
+var DefaultTextAttributes = []WDAPredicateAttribute{WDAPredicateLabel, WDAPredicateName, WDAPredicateValue}
+
+// FindByTextContains
+//
+// Finds the first element one of whose `attributes` (DefaultTextAttributes when none) contains `text`,
+// case-sensitive. `text` is escaped.
+func (s *Session) FindByTextContains(text string, attributes ...WDAPredicateAttribute) (*Element, error) {
+	return s.FindElement(textLocator(text, (*WDAPredicate).Contains, attributes))
+}
+
+// FindByTextMatches
+//
+// Like FindByTextContains with an ICU regular expression which must match the whole value,
+// e.g. `(?i).*total: [0-9]+.*`
+func (s *Session) FindByTextMatches(regexp string, attributes ...WDAPredicateAttribute) (*Element, error) {
+	return s.FindElement(textLocator(regexp, (*WDAPredicate).Matches, attributes))
+}
+
+func textLocator(text string, operator func(*WDAPredicate, WDAPredicateAttribute, string) *WDAPredicate, attributes []WDAPredicateAttribute) WDALocator {
+	if len(attributes) == 0 {
+		attributes = DefaultTextAttributes
+	}
+	if len(attributes) == 1 {
+		return ByPredicate(operator(NewWDAPredicate(), attributes[0], text))
+	}
+	alternatives := make([]*WDAPredicate, len(attributes))
+	for i := range attributes {
+		alternatives[i] = operator(NewWDAPredicate(), attributes[i], text)
+	}
+	return ByPredicate(NewWDAPredicate().Or(alternatives...))
+}
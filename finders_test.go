@@ -0,0 +1,31 @@
+package gwda
+
+import "testing"
+
+func TestSession_FindByName(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	checkErr(t, s.AppLaunch("com.apple.Preferences"))
+
+	_, err = s.FindByName("General")
+	checkErr(t, err)
+	_, err = s.FindByLabel("General")
+	checkErr(t, err)
+	_, err = s.FindByAccessibilityID("com.apple.settings.general")
+	checkErr(t, err)
+	_, err = s.FindByType(WDAElementType{NavigationBar: true})
+	checkErr(t, err)
+}
+
+func Test_textLocator(t *testing.T) {
+	locator := textLocator("it's", (*WDAPredicate).Contains, nil)
+	if want := `((label CONTAINS 'it\'s') OR (name CONTAINS 'it\'s') OR (value CONTAINS 'it\'s'))`; locator.Predicate != want {
+		t.Fatalf("got  %s\nwant %s", locator.Predicate, want)
+	}
+	locator = textLocator(`[0-9]+ items`, (*WDAPredicate).Matches, []WDAPredicateAttribute{WDAPredicateLabel})
+	if want := `label MATCHES '[0-9]+ items'`; locator.Predicate != want {
+		t.Fatalf("got  %s\nwant %s", locator.Predicate, want)
+	}
+}
@@ -0,0 +1,85 @@
+package gwda
+
+import (
+	"image"
+	"math"
+)
+
+// The coordinates of WDA (WDARect, WDACoordinate, Tap, Swipe...) are in points,
+// the screenshots (ScreenshotToImage, FindByImage, FindTextOnScreen...) are in pixels,
+// the pixels are the points multiplied by Session.Scale (2 or 3 on retina screens).
+
+// Center rounded down to a whole point, see CenterFloat
+func (r WDARect) Center() WDACoordinate {
+	return WDACoordinate{X: r.X + r.Width/2, Y: r.Y + r.Height/2}
+}
+
+// CenterFloat exact center, for TapFloat
+func (r WDARect) CenterFloat() (x, y float64) {
+	return float64(r.X) + float64(r.Width)/2, float64(r.Y) + float64(r.Height)/2
+}
+
+// Contains the point is within the rect, its right and bottom edges excluded
+func (r WDARect) Contains(c WDACoordinate) bool {
+	return r.X <= c.X && c.X < r.X+r.Width && r.Y <= c.Y && c.Y < r.Y+r.Height
+}
+
+// ToPixels the rect in screenshot pixels
+func (r WDARect) ToPixels(scale float64) image.Rectangle {
+	min := r.WDACoordinate.ToPixels(scale)
+	return image.Rectangle{Min: min, Max: min.Add(image.Pt(pointsToPixels(r.Width, scale), pointsToPixels(r.Height, scale)))}
+}
+
+// ToPixels the point in screenshot pixels
+func (c WDACoordinate) ToPixels(scale float64) image.Point {
+	return image.Pt(pointsToPixels(c.X, scale), pointsToPixels(c.Y, scale))
+}
+
+// RectFromPixels a rect of a screenshot, in points
+func RectFromPixels(r image.Rectangle, scale float64) (rect WDARect) {
+	rect.WDACoordinate = CoordinateFromPixels(r.Min, scale)
+	rect.Width = pixelsToPoints(r.Dx(), scale)
+	rect.Height = pixelsToPoints(r.Dy(), scale)
+	return
+}
+
+// CoordinateFromPixels a point of a screenshot, in points
+func CoordinateFromPixels(p image.Point, scale float64) WDACoordinate {
+	return WDACoordinate{X: pixelsToPoints(p.X, scale), Y: pixelsToPoints(p.Y, scale)}
+}
+
+// PixelsToPoints
+//
+// RectFromPixels with the scale of the screen
+func (s *Session) PixelsToPoints(r image.Rectangle) (rect WDARect, err error) {
+	var scale float64
+	if scale, err = s.Scale(); err != nil {
+		return WDARect{}, err
+	}
+	return RectFromPixels(r, scale), nil
+}
+
+// PointsToPixels
+//
+// WDARect.ToPixels with the scale of the screen
+func (s *Session) PointsToPixels(rect WDARect) (r image.Rectangle, err error) {
+	var scale float64
+	if scale, err = s.Scale(); err != nil {
+		return image.Rectangle{}, err
+	}
+	return rect.ToPixels(scale), nil
+}
+
+func pixelsToPoints(v int, scale float64) int {
+	if scale <= 0 {
+		scale = 1
+	}
+	return int(math.Round(float64(v) / scale))
+}
+
+func pointsToPixels(v int, scale float64) int {
+	if scale <= 0 {
+		scale = 1
+	}
+	return int(math.Round(float64(v) * scale))
+}
@@ -0,0 +1,33 @@
+package gwda
+
+import (
+	"image"
+	"testing"
+)
+
+func TestWDARect_Geometry(t *testing.T) {
+	rect := WDARect{WDACoordinate{X: 10, Y: 20}, WDASize{Width: 31, Height: 40}}
+	if c := rect.Center(); c != (WDACoordinate{X: 25, Y: 40}) {
+		t.Fatal(c)
+	}
+	if x, y := rect.CenterFloat(); x != 25.5 || y != 40 {
+		t.Fatal(x, y)
+	}
+	if !rect.Contains(WDACoordinate{X: 10, Y: 59}) || rect.Contains(WDACoordinate{X: 41, Y: 30}) {
+		t.Fatal("unexpected Contains")
+	}
+
+	r := rect.ToPixels(3)
+	if r != image.Rect(30, 60, 123, 180) {
+		t.Fatal(r)
+	}
+	if back := RectFromPixels(r, 3); back.WDACoordinate != rect.WDACoordinate || back.Width != rect.Width || back.Height != rect.Height {
+		t.Fatal(back)
+	}
+	if c := CoordinateFromPixels(image.Pt(31, 61), 2); c != (WDACoordinate{X: 16, Y: 31}) {
+		t.Fatal(c)
+	}
+	if p := (WDACoordinate{X: 5, Y: 7}).ToPixels(0); p != image.Pt(5, 7) {
+		t.Fatal(p)
+	}
+}
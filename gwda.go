@@ -2,35 +2,114 @@ package gwda
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	goUSBMux "github.com/electricbubble/go-usbmuxd-device"
 	"io"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
-	"path"
 	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/tidwall/gjson"
 )
 
-func init() {
-	httpProxy := os.Getenv("http_proxy")
-	if httpProxy != "" {
-		if proxyURL, err := url.Parse(httpProxy); err == nil {
-			http.DefaultTransport = &http.Transport{Proxy: http.ProxyURL(proxyURL)}
+// defaultHTTPClient is used by every Client without its own transport
+var defaultHTTPClient = func() *http.Client {
+	transport := newHTTPTransport()
+	transport.Proxy = proxyFromEnvironment
+	return &http.Client{Transport: transport}
+}()
+
+// proxyFromEnvironment
+//
+// honors `HTTP_PROXY`, `HTTPS_PROXY` and `NO_PROXY` (or the lowercase versions).
+// Like `http.ProxyFromEnvironment`, requests to localhost and loopback addresses (e.g. `iproxy`) are never proxied,
+// see WDAClientOption.SetProxyURL to debug them.
+func proxyFromEnvironment(req *http.Request) (*url.URL, error) {
+	proxy := getEnvAny("HTTP_PROXY", "http_proxy")
+	if req.URL.Scheme == "https" {
+		proxy = getEnvAny("HTTPS_PROXY", "https_proxy")
+	}
+	if proxy == "" || !useProxy(req.URL.Hostname(), getEnvAny("NO_PROXY", "no_proxy")) {
+		return nil, nil
+	}
+	proxyURL, err := url.Parse(proxy)
+	if err != nil || proxyURL.Host == "" {
+		// `127.0.0.1:8888`
+		if proxyURL, err = url.Parse("http://" + proxy); err != nil {
+			return nil, fmt.Errorf("invalid proxy address %q: %w", proxy, err)
 		}
 	}
+	return proxyURL, nil
+}
+
+// useProxy
+//
+// `noProxy` is a comma separated list of hosts, `.example.com` / `example.com` match the subdomains too, `*` matches everything.
+// localhost and the loopback addresses are always excluded.
+func useProxy(host, noProxy string) bool {
+	host = strings.ToLower(host)
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return false
+	}
+	for _, p := range strings.Split(noProxy, ",") {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "" {
+			continue
+		}
+		if p == "*" {
+			return false
+		}
+		if h, _, err := net.SplitHostPort(p); err == nil {
+			p = h
+		}
+		if host == strings.TrimPrefix(p, ".") || strings.HasSuffix(host, "."+strings.TrimPrefix(p, ".")) {
+			return false
+		}
+	}
+	return true
+}
+
+func getEnvAny(names ...string) string {
+	for _, n := range names {
+		if val := os.Getenv(n); val != "" {
+			return val
+		}
+	}
+	return ""
 }
 
 var wdaDebugFlag = false
 
 var usbHTTPClient = make(map[string]*http.Client)
+var usbHTTPClientMutex sync.RWMutex
+
+func setUSBHTTPClient(key string, httpClient *http.Client) {
+	usbHTTPClientMutex.Lock()
+	defer usbHTTPClientMutex.Unlock()
+	usbHTTPClient[key] = httpClient
+}
 
+func getUSBHTTPClient(key string) (httpClient *http.Client, ok bool) {
+	usbHTTPClientMutex.RLock()
+	defer usbHTTPClientMutex.RUnlock()
+	httpClient, ok = usbHTTPClient[key]
+	return
+}
+
+// DefaultWaitTimeout and DefaultWaitInterval unless a WDAConfig sets them, see SetConfig
 var DefaultWaitTimeout = time.Second * 60
 var DefaultWaitInterval = time.Millisecond * 250
 
@@ -41,28 +120,195 @@ var wdaHeader = map[string]string{
 
 // urlJoin fix `path.Join`
 func urlJoin(endpoint *url.URL, elem string, isWdaFirst ...bool) string {
-	tmp, _ := url.Parse(endpoint.String())
+	segments := strings.Split(elem, "/")
 	if len(isWdaFirst) != 0 && isWdaFirst[0] {
-		tmp.Path = path.Join(endpoint.Path, "wda", elem)
+		segments = append([]string{"wda"}, segments...)
+	}
+	return joinURL(endpoint, nil, segments...)
+}
+
+func executeGet(c *Client, actionName, url string) (wdaResp wdaResponse, err error) {
+	return executeHTTP(c, actionName, http.MethodGet, url, nil)
+}
+
+func executePost(c *Client, actionName, url string, body wdaBody) (wdaResp wdaResponse, err error) {
+	return executeHTTP(c, actionName, http.MethodPost, url, body)
+}
+
+func executeDelete(c *Client, actionName, url string) (wdaResp wdaResponse, err error) {
+	return executeHTTP(c, actionName, http.MethodDelete, url, nil)
+}
+
+func executeHTTP(c *Client, actionName, method, sURL string, body wdaBody) (wdaResp wdaResponse, err error) {
+	return executeHTTPContext(context.Background(), c, actionName, method, sURL, body)
+}
+
+// executeHTTPContext works like executeHTTP, `ctx` bounds the request including the reading of the response
+func executeHTTPContext(ctx context.Context, c *Client, actionName, method, sURL string, body wdaBody) (wdaResp wdaResponse, err error) {
+	var call *wdaCall
+	defer func() {
+		if err = call.withOrigin(err); err != nil && call != nil && call.client != nil {
+			call.client.requestFailed(call.session, actionName, err)
+		}
+		if errors.Is(err, ErrStaleElement) && call != nil && call.session != nil {
+			call.session.InvalidateCache()
+		}
+	}()
+	wdaResp, call, err = executeHTTPOnce(ctx, c, actionName, method, sURL, body)
+	if err == nil || call == nil || call.session == nil || errors.Is(err, ErrSessionClosed) || errors.Is(err, ErrSessionSuspended) {
+		return
+	}
+
+	if actionName != "DeleteSession" && wdaResp.isInvalidSession() && call.session.isAutoRecover() {
+		// as sent, see sendHTTP
+		if _, err = call.session.recover(sessionIDFromPath(call.logURL)); err != nil {
+			return nil, fmt.Errorf("%s: failed to recover session %w", actionName, err)
+		}
+		// sent to the new session
+		if wdaResp, call, err = executeHTTPOnce(ctx, c, actionName, method, sURL, body); err == nil || call == nil || call.session == nil {
+			return
+		}
+	}
+
+	call.session.commandFailed(call, err)
+	return
+}
+
+// executeHTTPOnce `call` is also returned when the request could not be sent
+func executeHTTPOnce(ctx context.Context, c *Client, actionName, method, sURL string, body wdaBody) (wdaResp wdaResponse, call *wdaCall, err error) {
+	if call, err = sendHTTP(ctx, c, actionName, method, sURL, body); err != nil {
+		return nil, call, err
+	}
+	defer call.done()
+	defer func() { call.report(wdaResp, err) }()
+
+	wdaResp, err = ioutil.ReadAll(call.resp.Body)
+
+	if actionName == "Screenshot" {
+		debugLog(fmt.Sprintf("<-- %s %s %d %s %s 'too long, don't display'\n",
+			method, call.logURL, call.resp.StatusCode, time.Now().Sub(call.start), actionName))
 	} else {
-		tmp.Path = path.Join(endpoint.Path, elem)
+		debugLog(fmt.Sprintf("<-- %s %s %d %s %s\n%s\n", method, call.logURL, call.resp.StatusCode, time.Now().Sub(call.start), actionName, wdaResp))
 	}
-	return tmp.String()
+
+	if err != nil {
+		if call.session != nil && call.session.isClosed() {
+			return nil, call, fmt.Errorf("%s: %w", actionName, ErrSessionClosed)
+		}
+		return nil, call, fmt.Errorf("%s: failed to read response %w", actionName, err)
+	}
+
+	err = wdaResp.getErrMsg()
+	return
+}
+
+// executeStream
+//
+// works like executeHTTP, but hands the undecoded response body to the caller, who must close it.
+// Error responses are still read completely and converted by getErrMsg.
+func executeStream(c *Client, actionName, method, sURL string, body wdaBody) (rc io.ReadCloser, err error) {
+	var call *wdaCall
+	if call, err = sendHTTP(context.Background(), c, actionName, method, sURL, body); err != nil {
+		return nil, err
+	}
+	debugLog(fmt.Sprintf("<-- %s %s %d %s %s 'streaming'\n", method, call.logURL, call.resp.StatusCode, time.Now().Sub(call.start), actionName))
+	// WDA has finished the work once the headers arrive, the caller may send other requests while reading
+	call.releaseSession()
+
+	var wdaResp wdaResponse
+	defer func() { call.report(wdaResp, err) }()
+
+	if call.resp.StatusCode >= http.StatusBadRequest {
+		defer call.done()
+		if wdaResp, err = ioutil.ReadAll(call.resp.Body); err != nil {
+			return nil, fmt.Errorf("%s: failed to read response %w", actionName, err)
+		}
+		if err = wdaResp.getErrMsg(); err == nil {
+			err = fmt.Errorf("%s: unexpected status code %d", actionName, call.resp.StatusCode)
+		}
+		return nil, err
+	}
+	return call, nil
+}
+
+// wdaCall a request which has been sent, `done` must be called once the response is consumed
+type wdaCall struct {
+	client  *Client
+	session *Session
+
+	actionName string
+	method     string
+	reqBody    []byte
+
+	resp   *http.Response
+	logURL string
+	start  time.Time
+
+	command *WDACommand // recorded in the Session history
+
+	release     func()
+	releaseOnce sync.Once
+	doneOnce    sync.Once
+}
+
+func (call *wdaCall) Read(p []byte) (n int, err error) {
+	return call.resp.Body.Read(p)
 }
 
-func executeGet(actionName, url string) (wdaResp wdaResponse, err error) {
-	return executeHTTP(actionName, http.MethodGet, url, nil)
+// Close same as done, for executeStream
+func (call *wdaCall) Close() error {
+	call.done()
+	return nil
 }
 
-func executePost(actionName, url string, body wdaBody) (wdaResp wdaResponse, err error) {
-	return executeHTTP(actionName, http.MethodPost, url, body)
+func (call *wdaCall) done() {
+	call.doneOnce.Do(func() {
+		_ = call.resp.Body.Close()
+		call.releaseSession()
+		if call.session != nil {
+			call.session.untrack(call)
+		}
+	})
 }
 
-func executeDelete(actionName, url string) (wdaResp wdaResponse, err error) {
-	return executeHTTP(actionName, http.MethodDelete, url, nil)
+// report hands the outcome to the MetricsRecorder, the dump directory, the AudioCapture of the Client and the Session history,
+// `respBody` is `nil` for a streamed response
+func (call *wdaCall) report(respBody []byte, err error) {
+	if call.client == nil {
+		return
+	}
+	statusCode := 0
+	if call.resp != nil {
+		statusCode = call.resp.StatusCode
+	}
+	duration := time.Since(call.start)
+	if call.client.metrics != nil {
+		call.client.metrics.ObserveRequest(call.actionName, call.method, statusCode, duration, err)
+	}
+	if call.client.dumpDir != "" {
+		call.client.dumpCall(call, statusCode, duration, respBody, err)
+	}
+	if capture := call.client.activeAudioCapture(); capture != nil {
+		capture.addStep(call, duration)
+	}
+	if call.session != nil {
+		call.session.recordCommand(call, statusCode, duration, respBody, err)
+	}
 }
 
-func executeHTTP(actionName, method, sURL string, body wdaBody) (wdaResp wdaResponse, err error) {
+func (call *wdaCall) releaseSession() {
+	call.releaseOnce.Do(func() {
+		if call.release != nil {
+			call.release()
+		}
+	})
+}
+
+// sendHTTP
+//
+// `c` is the Client of the Session or Element sending, its transport, headers, hooks and sessions apply (none when nil).
+// The caller must call `call.done()`, `call` is also returned (already done) when the request fails to be sent
+func sendHTTP(ctx context.Context, c *Client, actionName, method, sURL string, body wdaBody) (call *wdaCall, err error) {
 	var req *http.Request
 	var reqBody io.Reader = nil
 	var bsBody []byte
@@ -73,18 +319,51 @@ func executeHTTP(actionName, method, sURL string, body wdaBody) (wdaResp wdaResp
 		reqBody = bytes.NewBuffer(bsBody)
 	}
 
+	call = &wdaCall{client: c, actionName: actionName, method: method, reqBody: bsBody}
+	if c != nil {
+		sid := sessionIDFromPath(sURL)
+		if call.session = c.lookupSession(sid); call.session != nil {
+			// the elements and queries created before a recovery still have the former session id
+			if current := call.session.ID(); current != sid {
+				sURL = strings.Replace(sURL, "/session/"+sid, "/session/"+current, 1)
+			}
+		}
+	}
+
 	req, _ = http.NewRequest(method, sURL, reqBody)
 	for k, v := range wdaHeader {
 		req.Header.Set(k, v)
 	}
-
-	httpClient := http.DefaultClient
+	httpClient := defaultHTTPClient
 
 	filteredURL, _ := url.Parse(sURL)
-	if filteredURL.Port() == "" && len(filteredURL.Host) == 40 {
+	if c != nil {
+		if call.client.httpClient != nil {
+			httpClient = call.client.httpClient
+		}
+		for k := range call.client.header {
+			req.Header.Set(k, call.client.header.Get(k))
+		}
+		if call.client.headerFunc != nil {
+			var header http.Header
+			if header, err = call.client.headerFunc(req); err != nil {
+				err = fmt.Errorf("%s: header provider %w", actionName, err)
+				call.report(nil, err)
+				return call, err
+			}
+			for k := range header {
+				req.Header[k] = header[k]
+			}
+		}
+	}
+	if filteredURL.User != nil {
+		// keep the password out of the debug log
+		filteredURL.User = url.User(filteredURL.User.Username())
+	}
+	if filteredURL.Port() == "" && isUDID(filteredURL.Host) {
 		udid := filteredURL.Host
 		filteredURL.Host = "__UDID__"
-		if tmpClient, ok := usbHTTPClient[udid]; !ok {
+		if tmpClient, ok := getUSBHTTPClient(udid); !ok {
 			// much better for debugging
 			return nil, fmt.Errorf("no http client: %s", sURL)
 			// return nil, fmt.Errorf("no http client: %s", filteredURL.String())
@@ -92,34 +371,50 @@ func executeHTTP(actionName, method, sURL string, body wdaBody) (wdaResp wdaResp
 			httpClient = tmpClient
 		}
 	}
+	call.logURL = filteredURL.String()
 
-	debugLog(fmt.Sprintf("--> %s %s %s\n%s", method, filteredURL.String(), actionName, bsBody))
-
-	start := time.Now()
-	var resp *http.Response
-	resp, err = httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("%s: failed to send request %w", actionName, err)
+	if call.session != nil {
+		call.release = call.session.acquire()
+		if ctx, err = call.session.track(ctx, call); err != nil {
+			call.releaseSession()
+			err = fmt.Errorf("%s: %w", actionName, err)
+			call.report(nil, err)
+			return call, err
+		}
 	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
+	req = req.WithContext(ctx)
 
-	wdaResp, err = ioutil.ReadAll(resp.Body)
+	debugLog(fmt.Sprintf("--> %s %s %s\n%s", method, call.logURL, actionName, bsBody))
 
-	if actionName == "Screenshot" {
-		debugLog(fmt.Sprintf("<-- %s %s %d %s %s 'too long, don't display'\n",
-			method, filteredURL.String(), resp.StatusCode, time.Now().Sub(start), actionName))
-	} else {
-		debugLog(fmt.Sprintf("<-- %s %s %d %s %s\n%s\n", method, filteredURL.String(), resp.StatusCode, time.Now().Sub(start), actionName, wdaResp))
+	call.start = time.Now()
+	if call.resp, err = httpClient.Do(req); err != nil {
+		call.releaseSession()
+		if call.session != nil {
+			call.session.untrack(call)
+		}
+		if call.session != nil && call.session.isClosed() {
+			err = fmt.Errorf("%s: %w", actionName, ErrSessionClosed)
+		} else {
+			err = fmt.Errorf("%s: failed to send request %w", actionName, err)
+		}
+		call.report(nil, err)
+		return call, err
 	}
+	return
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("%s: failed to read response %w", actionName, err)
+// sessionIDFromPath `/session/:sessionId/...`
+func sessionIDFromPath(p string) string {
+	const prefix = "/session/"
+	i := strings.Index(p, prefix)
+	if i == -1 {
+		return ""
 	}
-
-	err = wdaResp.getErrMsg()
-	return
+	sid := p[i+len(prefix):]
+	if j := strings.IndexAny(sid, "/?"); j != -1 {
+		sid = sid[:j]
+	}
+	return sid
 }
 
 type wdaBody map[string]interface{}
@@ -158,6 +453,40 @@ func (wdaResp wdaResponse) getValue() gjson.Result {
 	return gjson.GetBytes(wdaResp, "value")
 }
 
+// unmarshalValue
+//
+// decodes `value` into `v` in one pass, the returned raw value shares the memory of the response
+func (wdaResp wdaResponse) unmarshalValue(v interface{}) (raw json.RawMessage, err error) {
+	raw = rawPath(wdaResp, "value")
+	err = jsonUnmarshal(raw, v)
+	return
+}
+
+// rawPath slices the value at `path` out of `raw` without copying
+func rawPath(raw []byte, path string) json.RawMessage {
+	result := gjson.GetBytes(raw, path)
+	if !result.Exists() {
+		return nil
+	}
+	if result.Index > 0 && result.Index+len(result.Raw) <= len(raw) {
+		return raw[result.Index : result.Index+len(result.Raw)]
+	}
+	return json.RawMessage(result.Raw)
+}
+
+// rawJSON marshals `v` only when it was not decoded from a response
+func rawJSON(raw json.RawMessage, v interface{}) json.RawMessage {
+	if raw != nil {
+		return raw
+	}
+	bs, _ := json.Marshal(v)
+	return bs
+}
+
+func rawString(raw json.RawMessage, v interface{}) string {
+	return string(rawJSON(raw, v))
+}
+
 func (wdaResp wdaResponse) getErrMsg() error {
 	// {
 	//  "value" : {
@@ -167,10 +496,13 @@ func (wdaResp wdaResponse) getErrMsg() error {
 	//  },
 	//  "sessionId" : "215BB5C5-B189-496F-83B7-37CBBB2DC54E"
 	// }
+	if !wdaResp.mayBeError() {
+		return nil
+	}
 	wdaErrType := wdaResp.getByPath("value.error").String()
 	// if wdaErrType == "" && wdaResp.getValue().Type == gjson.Null {
 	if wdaErrType == "" {
-		return nil
+		return wdaResp.getMJSONWPErrMsg()
 	}
 	wdaErrMsg := wdaResp.getByPath("value.message").String()
 	errText := wdaErrMsg
@@ -180,9 +512,73 @@ func (wdaResp wdaResponse) getErrMsg() error {
 	if len(subMatch) == 2 {
 		errText = subMatch[1]
 	}
+	for _, elemErr := range wdaElementErrors {
+		if wdaErrType != elemErr.code {
+			continue
+		}
+		if wdaErrType == elemErr.err.Error() {
+			return fmt.Errorf("%w: %s", elemErr.err, errText)
+		}
+		return fmt.Errorf("%s: %s (%w)", wdaErrType, errText, elemErr.err)
+	}
 	return fmt.Errorf("%s: %s", wdaErrType, errText)
 }
 
+// wdaElementErrors the element errors by W3C error code and MJSONWP status (`0` without one)
+var wdaElementErrors = []struct {
+	code   string
+	status int64
+	err    error
+}{
+	{"no such element", 7, ErrNoSuchElement},
+	{"stale element reference", 10, ErrStaleElement},
+	{"element not visible", 11, ErrElementNotVisible},
+	{"element not interactable", 0, ErrElementNotInteractable},
+	{"element click intercepted", 0, ErrElementNotInteractable},
+	{"invalid element state", 12, ErrElementNotInteractable},
+}
+
+// getMJSONWPErrMsg
+//
+//	{"status": 7, "value": "An element could not be located on the page using the given search parameters"}
+//	{"status": 6, "value": {"message": "Session does not exist"}}
+func (wdaResp wdaResponse) getMJSONWPErrMsg() error {
+	status := wdaResp.getByPath("status")
+	if status.Type != gjson.Number || status.Int() == 0 {
+		return nil
+	}
+	msg := wdaResp.getByPath("value.message").String()
+	if msg == "" {
+		msg = wdaResp.getValue().String()
+	}
+	err := fmt.Errorf("status %d: %s", status.Int(), msg)
+	for _, elemErr := range wdaElementErrors {
+		if elemErr.status != 0 && status.Int() == elemErr.status {
+			return &wdaStatusError{err: err, kind: elemErr.err}
+		}
+	}
+	return err
+}
+
+// wdaStatusError keeps the text of an MJSONWP error, and wraps the element error of its status
+type wdaStatusError struct {
+	err  error
+	kind error
+}
+
+func (e *wdaStatusError) Error() string {
+	return e.err.Error()
+}
+
+func (e *wdaStatusError) Unwrap() error {
+	return e.kind
+}
+
+func (wdaResp wdaResponse) isInvalidSession() bool {
+	// MJSONWP `NoSuchDriver`
+	return wdaResp.getByPath("value.error").String() == "invalid session id" || wdaResp.getByPath("status").Int() == 6
+}
+
 func WDADebug(b ...bool) {
 	if len(b) == 0 {
 		b = []bool{true}
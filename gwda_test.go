@@ -1,6 +1,7 @@
 package gwda
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 )
@@ -67,3 +68,89 @@ func TestTmpGwda(t *testing.T) {
 // 	// WdFrame                  string `json:"wdFrame"`
 // 	// WdRect                   string `json:"wdRect"`
 // }
+
+func Test_useProxy(t *testing.T) {
+	noProxy := "localhost, .example.com,10.0.0.1:8100"
+	for host, expected := range map[string]bool{
+		"localhost":        false,
+		"wda.example.com":  false,
+		"example.com":      false,
+		"10.0.0.1":         false,
+		"192.168.1.2":      true,
+		"farm.example.org": true,
+		"notexample.com":   true,
+	} {
+		if useProxy(host, noProxy) != expected {
+			t.Fatalf("%s: expected %v", host, expected)
+		}
+	}
+	if useProxy("localhost", "*") {
+		t.Fatal("'*' should match everything")
+	}
+	for _, host := range []string{"localhost", "127.0.0.1", "::1"} {
+		if useProxy(host, "") {
+			t.Fatalf("%s: loopback should not be proxied", host)
+		}
+	}
+}
+
+func Test_wdaResponse_unmarshalValue(t *testing.T) {
+	wdaResp := wdaResponse(`{"value":{"statusBarSize":{"width":375,"height":44},"scale":3},"sessionId":"S"}`)
+	var screen WDAScreen
+	var err error
+	if screen.raw, err = wdaResp.unmarshalValue(&screen); err != nil {
+		t.Fatal(err)
+	}
+	screen.StatusBarSize.raw = rawPath(screen.raw, "statusBarSize")
+	if screen.Scale != 3 || screen.StatusBarSize.Height != 44 {
+		t.Fatalf("%+v", screen)
+	}
+	if screen.String() != `{"statusBarSize":{"width":375,"height":44},"scale":3}` {
+		t.Error(screen.String())
+	}
+	if screen.StatusBarSize.String() != `{"width":375,"height":44}` {
+		t.Error(screen.StatusBarSize.String())
+	}
+
+	// not decoded from a response
+	if s := (WDASize{Width: 1, Height: 2}).String(); s != `{"width":1,"height":2}` {
+		t.Error(s)
+	}
+}
+
+func Test_wdaResponse_getErrMsg_staleElement(t *testing.T) {
+	for _, resp := range []string{
+		`{"value":{"error":"stale element reference","message":"The previously found element \"Wi-Fi\" Cell is not present in the current view anymore"}}`,
+		`{"status":10,"value":"The element is no longer attached to the DOM"}`,
+	} {
+		if err := wdaResponse(resp).getErrMsg(); !errors.Is(err, ErrStaleElement) || !isStaleElement(err) {
+			t.Errorf("%s: got %v", resp, err)
+		}
+	}
+	if err := wdaResponse(`{"value":{"error":"no such element","message":"..."}}`).getErrMsg(); errors.Is(err, ErrStaleElement) {
+		t.Errorf("unexpected %v", err)
+	}
+}
+
+func Test_wdaResponse_getErrMsg_elementErrors(t *testing.T) {
+	for resp, want := range map[string]error{
+		`{"value":{"error":"no such element","message":"unable to find an element using 'name', value 'gwda'"}}`: ErrNoSuchElement,
+		`{"status":7,"value":"An element could not be located on the page using the given search parameters"}`:   ErrNoSuchElement,
+		`{"value":{"error":"element not visible","message":"..."}}`:                                              ErrElementNotVisible,
+		`{"status":11,"value":{"message":"..."}}`:                                                                ErrElementNotVisible,
+		`{"value":{"error":"element not interactable","message":"..."}}`:                                         ErrElementNotInteractable,
+		`{"value":{"error":"invalid element state","message":"..."}}`:                                            ErrElementNotInteractable,
+	} {
+		err := wdaResponse(resp).getErrMsg()
+		if !errors.Is(err, want) {
+			t.Errorf("%s: got %v", resp, err)
+		}
+		if want == ErrNoSuchElement && !isNoSuchElement(err) {
+			t.Errorf("%s: expected isNoSuchElement", resp)
+		}
+	}
+	err := wdaResponse(`{"value":{"error":"invalid element state","message":"Button is disabled"}}`).getErrMsg()
+	if want := "invalid element state: Button is disabled (element not interactable)"; err.Error() != want {
+		t.Error(err)
+	}
+}
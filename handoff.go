@@ -0,0 +1,146 @@
+package gwda
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ErrSessionSuspended is returned (wrapped) by the requests sent while the Session is suspended
+var ErrSessionSuspended = errors.New("session suspended")
+
+// WDASessionState
+//
+// what another process needs to take over a session, see Session.Suspend and Client.AttachSessionState
+type WDASessionState struct {
+	DeviceURL    string          `json:"deviceURL"`
+	SessionID    string          `json:"sessionId"`
+	Capabilities json.RawMessage `json:"capabilities,omitempty"` // as sent by NewSession, to recover
+	AutoRecover  bool            `json:"autoRecover,omitempty"`
+	Settings     json.RawMessage `json:"settings,omitempty"` // only saved by Marshal
+}
+
+// State of the session
+func (s *Session) State() (state WDASessionState) {
+	state.SessionID = s.ID()
+	state.AutoRecover = s.isAutoRecover()
+	state.DeviceURL = s.deviceURL().String()
+	if s.capabilities != nil {
+		state.Capabilities, _ = json.Marshal(s.capabilities)
+	}
+	return
+}
+
+// Suspend
+//
+// Hands the session over, e.g. for a rolling restart of the controlling service: the requests sent from now on
+// fail with ErrSessionSuspended, the ScreenInterruptionWatcher polls are skipped, and the requests in flight are
+// waited for up to `timeout`. The returned state is passed to Client.AttachSessionState by the next process.
+// Resume takes the session back. When the requests in flight don't finish in time, the session is not
+// suspended anymore, unless it already was.
+func (s *Session) Suspend(timeout time.Duration) (state WDASessionState, err error) {
+	s.inflightMutex.Lock()
+	suspending := atomic.CompareAndSwapInt32(&s.suspended, 0, 1)
+	s.inflightMutex.Unlock()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		s.inflightMutex.Lock()
+		pending := len(s.inflight)
+		s.inflightMutex.Unlock()
+		if pending == 0 {
+			return s.State(), nil
+		}
+		if time.Now().After(deadline) {
+			if suspending {
+				atomic.StoreInt32(&s.suspended, 0)
+			}
+			return WDASessionState{}, fmt.Errorf("suspend: %d requests still in flight after %v", pending, timeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Resume
+//
+// takes back a suspended session, which must still be alive on WDA
+func (s *Session) Resume() (err error) {
+	atomic.StoreInt32(&s.suspended, 0)
+	if err = s.Ping(); err != nil {
+		return fmt.Errorf("resume: %w", err)
+	}
+	return
+}
+
+func (s *Session) isSuspended() bool {
+	return atomic.LoadInt32(&s.suspended) == 1
+}
+
+// AttachSessionState
+//
+// AttachSession with the state of Session.Suspend, the session is recovered with its original capabilities
+func (c *Client) AttachSessionState(state WDASessionState) (s *Session, err error) {
+	if s, err = c.AttachSession(state.SessionID); err != nil {
+		return nil, err
+	}
+	if len(state.Capabilities) != 0 {
+		var capabilities wdaBody
+		if err = json.Unmarshal(state.Capabilities, &capabilities); err != nil {
+			return nil, fmt.Errorf("attach session %s: capabilities: %w", state.SessionID, err)
+		}
+		s.capabilities = capabilities
+	}
+	s.SetAutoRecover(state.AutoRecover)
+	atomic.StoreInt32(&s.suspended, 0)
+	return
+}
+
+// Marshal
+//
+// The state of the session along with its settings, for RestoreSession after the process crashed.
+func (s *Session) Marshal() (data []byte, err error) {
+	state := s.State()
+	var settings *WDASettings
+	if settings, err = s.Settings(); err != nil {
+		return nil, err
+	}
+	state.Settings = settings.RawJSON()
+	return json.Marshal(state)
+}
+
+// RestoreSession
+//
+// Attaches to the session saved by Marshal (see Client.AttachSessionState) and applies its settings again,
+// in case WDA lost them. `opt` is the option of the new Client, can be omitted.
+func RestoreSession(data []byte, opt ...*WDAClientOption) (s *Session, err error) {
+	var state WDASessionState
+	if err = json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("restore session: %w", err)
+	}
+	if len(opt) == 0 {
+		opt = []*WDAClientOption{nil}
+	}
+	var c *Client
+	if c, err = NewClientWithOption(state.DeviceURL, opt[0]); err != nil {
+		return nil, fmt.Errorf("restore session: %w", err)
+	}
+	if s, err = c.AttachSessionState(state); err != nil {
+		return nil, err
+	}
+	if len(state.Settings) != 0 {
+		settings := NewWDASettings()
+		values := make(map[string]json.RawMessage)
+		if err = json.Unmarshal(state.Settings, &values); err != nil {
+			return nil, fmt.Errorf("restore session: settings: %w", err)
+		}
+		for k, v := range values {
+			settings.Set(k, v)
+		}
+		if err = s.ApplySettings(settings); err != nil {
+			return nil, fmt.Errorf("restore session: settings: %w", err)
+		}
+	}
+	return
+}
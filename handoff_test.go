@@ -0,0 +1,60 @@
+package gwda
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestSession_Suspend(t *testing.T) {
+	deviceURL, _ := url.Parse("http://localhost:8100")
+	s := newSession(deviceURL, "S1")
+	s.capabilities = newWdaBody().set("capabilities", newWdaBody())
+
+	call := &wdaCall{actionName: "FindElements"}
+	_, err := s.track(context.Background(), call)
+	checkErr(t, err)
+
+	if _, err = s.Suspend(20 * time.Millisecond); err == nil {
+		t.Fatal("a request is still in flight")
+	}
+	source := &wdaCall{actionName: "Source"}
+	if _, err = s.track(context.Background(), source); err != nil {
+		t.Fatal("a session failing to suspend must still accept requests:", err)
+	}
+	s.untrack(source)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		s.untrack(call)
+	}()
+	state, err := s.Suspend(time.Second)
+	checkErr(t, err)
+	if state.SessionID != "S1" || state.DeviceURL != "http://localhost:8100" || string(state.Capabilities) != `{"capabilities":{}}` {
+		t.Fatalf("unexpected state: %+v", state)
+	}
+	if _, err = s.track(context.Background(), &wdaCall{actionName: "Source"}); !errors.Is(err, ErrSessionSuspended) {
+		t.Fatal("a suspended session must refuse requests:", err)
+	}
+}
+
+func TestRestoreSession(t *testing.T) {
+	client, err := NewClient(deviceURL)
+	checkErr(t, err)
+	session, err := client.NewSession(NewWDASessionCapability(bundleId))
+	checkErr(t, err)
+	session.SetAutoRecover(true)
+
+	data, err := session.Marshal()
+	checkErr(t, err)
+
+	restored, err := RestoreSession(data)
+	checkErr(t, err)
+	if restored.ID() != session.ID() || !restored.isAutoRecover() {
+		t.Fatalf("restored %s, want %s", restored.ID(), session.ID())
+	}
+	_, err = restored.ActiveAppInfo()
+	checkErr(t, err)
+}
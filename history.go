@@ -0,0 +1,244 @@
+package gwda
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxHistoryBodySize bodies of the history are cut beyond it, e.g. screenshots
+const maxHistoryBodySize = 4 << 10
+
+// thumbnailWidth of the screenshot attached to a failed command
+const thumbnailWidth = 160
+
+// WDACommand a request recorded in the history of a Session
+type WDACommand struct {
+	Time       time.Time     `json:"time"`
+	Action     string        `json:"action"`
+	Method     string        `json:"method"`
+	URL        string        `json:"url"`
+	Request    string        `json:"request,omitempty"`
+	StatusCode int           `json:"statusCode"`
+	Duration   time.Duration `json:"duration"`
+	Response   string        `json:"response,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	// Thumbnail PNG of the screen right after the command failed, see SetHistoryThumbnail
+	Thumbnail []byte `json:"thumbnail,omitempty"`
+}
+
+type commandHistory struct {
+	mutex sync.Mutex
+
+	commands []*WDACommand // ring
+	next     int
+	full     bool
+
+	thumbnail   bool
+	dumpOnError io.Writer
+	capturing   int32 // a thumbnail is being taken, its screenshot is not recorded
+}
+
+func (h *commandHistory) add(cmd *WDACommand) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if len(h.commands) == 0 {
+		return
+	}
+	h.commands[h.next] = cmd
+	h.next = (h.next + 1) % len(h.commands)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// list oldest first, the caller must hold the lock
+func (h *commandHistory) list() []*WDACommand {
+	if !h.full {
+		return append([]*WDACommand(nil), h.commands[:h.next]...)
+	}
+	return append(append([]*WDACommand(nil), h.commands[h.next:]...), h.commands[:h.next]...)
+}
+
+// SetHistorySize
+//
+// Keeps the last `k` requests of this session (including its elements) in memory,
+// so an intermittent failure in a long run comes with its recent history. `0` disables it and drops the history.
+//
+// Default is `0`
+func (s *Session) SetHistorySize(k int) {
+	s.historyMutex.Lock()
+	defer s.historyMutex.Unlock()
+	if k <= 0 {
+		s.history = nil
+		return
+	}
+	h := &commandHistory{commands: make([]*WDACommand, k)}
+	if s.history != nil {
+		s.history.mutex.Lock()
+		h.thumbnail, h.dumpOnError = s.history.thumbnail, s.history.dumpOnError
+		for _, cmd := range s.history.list() {
+			h.commands[h.next] = cmd
+			h.next = (h.next + 1) % k
+			if h.next == 0 {
+				h.full = true
+			}
+		}
+		s.history.mutex.Unlock()
+	}
+	s.history = h
+}
+
+// SetHistoryThumbnail
+//
+// attaches a thumbnail of the screen to every failed command of the history, costs one screenshot per failure
+func (s *Session) SetHistoryThumbnail(b bool) {
+	if h := s.getHistory(); h != nil {
+		h.mutex.Lock()
+		h.thumbnail = b
+		h.mutex.Unlock()
+	}
+}
+
+// SetHistoryDumpOnError
+//
+// writes the history (see DumpHistory) to `w` every time a command fails, `nil` stops it
+func (s *Session) SetHistoryDumpOnError(w io.Writer) {
+	if h := s.getHistory(); h != nil {
+		h.mutex.Lock()
+		h.dumpOnError = w
+		h.mutex.Unlock()
+	}
+}
+
+// History
+//
+// the recorded commands, oldest first
+func (s *Session) History() (commands []WDACommand) {
+	h := s.getHistory()
+	if h == nil {
+		return nil
+	}
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	list := h.list()
+	commands = make([]WDACommand, len(list))
+	for i := range list {
+		commands[i] = *list[i]
+	}
+	return
+}
+
+// DumpHistory
+//
+// writes the recorded commands to `w`, one JSON object per line, oldest first
+func (s *Session) DumpHistory(w io.Writer) (err error) {
+	enc := json.NewEncoder(w)
+	for _, cmd := range s.History() {
+		if err = enc.Encode(cmd); err != nil {
+			return err
+		}
+	}
+	return
+}
+
+func (s *Session) getHistory() *commandHistory {
+	s.historyMutex.Lock()
+	defer s.historyMutex.Unlock()
+	return s.history
+}
+
+func (s *Session) recordCommand(call *wdaCall, statusCode int, duration time.Duration, respBody []byte, err error) {
+	h := s.getHistory()
+	if h == nil || (call.actionName == "Screenshot" && atomic.LoadInt32(&h.capturing) == 1) {
+		return
+	}
+	cmd := &WDACommand{
+		Time:       call.start,
+		Action:     call.actionName,
+		Method:     call.method,
+		URL:        call.logURL,
+		Request:    truncateBody(call.reqBody),
+		StatusCode: statusCode,
+		Duration:   duration,
+		Response:   truncateBody(respBody),
+	}
+	if err != nil {
+		cmd.Error = err.Error()
+	}
+	call.command = cmd
+	h.add(cmd)
+}
+
+// commandFailed
+//
+// called once the failed call has released the session, so it may send requests itself
+func (s *Session) commandFailed(call *wdaCall, err error) {
+	h := s.getHistory()
+	if h == nil {
+		return
+	}
+	h.mutex.Lock()
+	thumbnail, w := h.thumbnail, h.dumpOnError
+	h.mutex.Unlock()
+
+	// the screenshot failing too must not recurse
+	if thumbnail && call.command != nil && call.actionName != "Screenshot" {
+		atomic.StoreInt32(&h.capturing, 1)
+		bs, errThumb := s.thumbnail()
+		atomic.StoreInt32(&h.capturing, 0)
+		if errThumb == nil {
+			h.mutex.Lock()
+			call.command.Thumbnail = bs
+			h.mutex.Unlock()
+		} else {
+			debugLog(fmt.Sprintf("history thumbnail: %s", errThumb))
+		}
+	}
+	if w != nil {
+		if errDump := s.DumpHistory(w); errDump != nil {
+			debugLog(fmt.Sprintf("history dump: %s", errDump))
+		}
+	}
+}
+
+func (s *Session) thumbnail() (bs []byte, err error) {
+	var img image.Image
+	if img, _, err = s.ScreenshotToImage(); err != nil {
+		return nil, err
+	}
+	b := img.Bounds()
+	if b.Dx() == 0 {
+		return nil, fmt.Errorf("empty screenshot")
+	}
+	width := thumbnailWidth
+	if b.Dx() < width {
+		width = b.Dx()
+	}
+	height := b.Dy() * width / b.Dx()
+	thumb := image.NewRGBA(image.Rect(0, 0, width, height))
+	// nearest neighbor is good enough to recognize the screen
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			thumb.Set(x, y, img.At(b.Min.X+x*b.Dx()/width, b.Min.Y+y*b.Dy()/height))
+		}
+	}
+	buf := new(bytes.Buffer)
+	if err = png.Encode(buf, thumb); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func truncateBody(body []byte) string {
+	if len(body) <= maxHistoryBodySize {
+		return string(body)
+	}
+	return fmt.Sprintf("%s... (%d bytes)", body[:maxHistoryBodySize], len(body))
+}
@@ -0,0 +1,32 @@
+package gwda
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSession_SetHistorySize(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+
+	s.SetHistorySize(3)
+	s.SetHistoryThumbnail(true)
+	s.SetHistoryDumpOnError(os.Stderr)
+
+	checkErr(t, s.PressHomeButton())
+	_, err = s.Orientation()
+	checkErr(t, err)
+	if _, err = s.FindElement(WDALocator{Name: "no such element"}); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	history := s.History()
+	if len(history) != 3 {
+		t.Fatalf("history size %d", len(history))
+	}
+	if last := history[len(history)-1]; last.Error == "" || len(last.Thumbnail) == 0 {
+		t.Fatal("missing evidence of the failed command")
+	}
+}
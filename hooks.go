@@ -0,0 +1,80 @@
+package gwda
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// clientHooks registered on a Client
+type clientHooks struct {
+	mutex          sync.RWMutex
+	sessionCreated []func(s *Session)
+	sessionDeleted []func(s *Session)
+	requestError   []func(s *Session, actionName string, err error)
+
+	inRequestError int32 // for the requests without session, see Session.inRequestError
+}
+
+// OnSessionCreated
+//
+// called after NewSession (NewSessionWithCapabilities) and AttachSession succeeded, e.g. to start a recording
+func (c *Client) OnSessionCreated(hook func(s *Session)) {
+	c.hooks.mutex.Lock()
+	defer c.hooks.mutex.Unlock()
+	c.hooks.sessionCreated = append(c.hooks.sessionCreated, hook)
+}
+
+// OnSessionDeleted
+//
+// called after DeleteSession, whether WDA could delete it or not
+func (c *Client) OnSessionDeleted(hook func(s *Session)) {
+	c.hooks.mutex.Lock()
+	defer c.hooks.mutex.Unlock()
+	c.hooks.sessionDeleted = append(c.hooks.sessionDeleted, hook)
+}
+
+// OnRequestError
+//
+// Called for every failed request of the Client, `s` is `nil` for the requests without session.
+// The requests of `s` failing while such a hook runs for it (e.g. a screenshot taken by it) don't call the hooks again,
+// the failures of the other sessions do.
+func (c *Client) OnRequestError(hook func(s *Session, actionName string, err error)) {
+	c.hooks.mutex.Lock()
+	defer c.hooks.mutex.Unlock()
+	c.hooks.requestError = append(c.hooks.requestError, hook)
+}
+
+func (c *Client) sessionCreated(s *Session) {
+	c.hooks.mutex.RLock()
+	hooks := c.hooks.sessionCreated
+	c.hooks.mutex.RUnlock()
+	for _, hook := range hooks {
+		hook(s)
+	}
+}
+
+func (c *Client) sessionDeleted(s *Session) {
+	c.hooks.mutex.RLock()
+	hooks := c.hooks.sessionDeleted
+	c.hooks.mutex.RUnlock()
+	for _, hook := range hooks {
+		hook(s)
+	}
+}
+
+func (c *Client) requestFailed(s *Session, actionName string, err error) {
+	c.hooks.mutex.RLock()
+	hooks := c.hooks.requestError
+	c.hooks.mutex.RUnlock()
+	inRequestError := &c.hooks.inRequestError
+	if s != nil {
+		inRequestError = &s.inRequestError
+	}
+	if len(hooks) == 0 || !atomic.CompareAndSwapInt32(inRequestError, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(inRequestError, 0)
+	for _, hook := range hooks {
+		hook(s, actionName, err)
+	}
+}
@@ -0,0 +1,55 @@
+package gwda
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestClient_OnRequestError(t *testing.T) {
+	c := new(Client)
+	var calls []string
+	c.OnRequestError(func(s *Session, actionName string, err error) {
+		calls = append(calls, actionName+": "+err.Error())
+		// a failing request of the hook must not call it again
+		c.requestFailed(s, "Screenshot", errors.New("nested"))
+	})
+	c.requestFailed(nil, "FindElement", errors.New("no such element"))
+	c.requestFailed(nil, "Tap", errors.New("boom"))
+	if len(calls) != 2 || calls[0] != "FindElement: no such element" || calls[1] != "Tap: boom" {
+		t.Fatalf("unexpected calls: %q", calls)
+	}
+}
+
+func TestClient_OnRequestError_otherSession(t *testing.T) {
+	c := new(Client)
+	s1, s2 := newSession(&url.URL{Scheme: "http", Host: "192.168.1.2:8100"}, "S1"), newSession(&url.URL{Scheme: "http", Host: "192.168.1.2:8100"}, "S2")
+	var calls []string
+	c.OnRequestError(func(s *Session, actionName string, err error) {
+		calls = append(calls, s.ID()+" "+actionName)
+		if s == s1 {
+			// as if S2 failed in another goroutine meanwhile, and the screenshot of the hook for S1
+			c.requestFailed(s2, "Tap", errors.New("boom"))
+			c.requestFailed(s1, "Screenshot", errors.New("nested"))
+		}
+	})
+	c.requestFailed(s1, "FindElement", errors.New("no such element"))
+	if len(calls) != 2 || calls[0] != "S1 FindElement" || calls[1] != "S2 Tap" {
+		t.Fatalf("unexpected calls: %q", calls)
+	}
+}
+
+func TestClient_OnSessionCreated(t *testing.T) {
+	client, err := NewClient(deviceURL)
+	checkErr(t, err)
+	var created, deleted *Session
+	client.OnSessionCreated(func(s *Session) { created = s })
+	client.OnSessionDeleted(func(s *Session) { deleted = s })
+
+	session, err := client.NewSession()
+	checkErr(t, err)
+	checkErr(t, session.DeleteSession())
+	if created != session || deleted != session {
+		t.Fatalf("hooks: created %p, deleted %p, session %p", created, deleted, session)
+	}
+}
@@ -0,0 +1,218 @@
+package gwda
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// AppInstaller
+//
+// Installs and uninstalls the applications of a device. WDA has no endpoint for it, so it is done
+// by a backend talking to the device directly, e.g. IdeviceInstaller or a wrapper of go-ios.
+type AppInstaller interface {
+	InstallApp(udid, appPath string) error
+	UninstallApp(udid, bundleId string) error
+}
+
+// AppLister
+//
+// implemented by the AppInstaller which can list the installed applications, e.g. IdeviceInstaller
+type AppLister interface {
+	InstalledApps(udid string) ([]WDAInstalledApp, error)
+}
+
+// WDAInstalledApp
+type WDAInstalledApp struct {
+	BundleID string
+	Name     string // display name
+	Version  string // CFBundleVersion
+}
+
+// DefaultAppInstaller used by the clients without WDAClientOption.SetAppInstaller
+var DefaultAppInstaller AppInstaller = IdeviceInstaller{}
+
+// IdeviceInstaller
+//
+// runs `ideviceinstaller` of libimobiledevice, which must be in the PATH unless `Path` is set
+type IdeviceInstaller struct {
+	Path string
+}
+
+func (ii IdeviceInstaller) InstallApp(udid, appPath string) error {
+	return ii.run("-u", udid, "-i", appPath)
+}
+
+func (ii IdeviceInstaller) UninstallApp(udid, bundleId string) error {
+	return ii.run("-u", udid, "-U", bundleId)
+}
+
+// InstalledApps the user applications
+func (ii IdeviceInstaller) InstalledApps(udid string) (apps []WDAInstalledApp, err error) {
+	var output []byte
+	if output, err = ii.output("-u", udid, "-l"); err != nil {
+		return nil, err
+	}
+	return parseIdeviceInstallerList(output)
+}
+
+func (ii IdeviceInstaller) run(args ...string) (err error) {
+	_, err = ii.output(args...)
+	return
+}
+
+func (ii IdeviceInstaller) output(args ...string) ([]byte, error) {
+	name := ii.Path
+	if name == "" {
+		name = "ideviceinstaller"
+	}
+	output, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	// older versions exit with 0 on failure
+	if strings.Contains(string(output), "ERROR") {
+		return nil, fmt.Errorf("%s %s: %s", name, strings.Join(args, " "), strings.TrimSpace(string(output)))
+	}
+	return output, nil
+}
+
+// parseIdeviceInstallerList
+//
+//	CFBundleIdentifier, CFBundleVersion, CFBundleDisplayName
+//	com.example.demo, "42", "Demo"
+func parseIdeviceInstallerList(output []byte) (apps []WDAInstalledApp, err error) {
+	reader := csv.NewReader(strings.NewReader(string(output)))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	for {
+		var record []string
+		if record, err = reader.Read(); err == io.EOF {
+			return apps, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("installed apps: %w", err)
+		}
+		if len(record) < 3 || record[0] == "CFBundleIdentifier" || strings.HasPrefix(record[0], "Total:") {
+			continue
+		}
+		apps = append(apps, WDAInstalledApp{BundleID: record[0], Version: record[1], Name: record[2]})
+	}
+}
+
+// UDID of the device, known when connected through usbmuxd or set with WDAClientOption.SetUDID
+func (c *Client) UDID() string {
+	if c.serialNumber != "" {
+		return c.serialNumber
+	}
+	return c.udid
+}
+
+func (c *Client) installer() (installer AppInstaller, udid string, err error) {
+	if installer = c.appInstaller; installer == nil {
+		installer = DefaultAppInstaller
+	}
+	if installer == nil {
+		return nil, "", errors.New("no app installer")
+	}
+	if udid = c.UDID(); udid == "" {
+		return nil, "", errors.New("the UDID of the device is unknown, see WDAClientOption.SetUDID")
+	}
+	return
+}
+
+// InstallApp
+//
+// installs an `.ipa` (or an `.app` directory) with the AppInstaller of the Client
+func (c *Client) InstallApp(appPath string) (err error) {
+	var info os.FileInfo
+	if info, err = os.Stat(appPath); err != nil {
+		return err
+	}
+	switch ext := strings.ToLower(filepath.Ext(appPath)); {
+	case ext == ".ipa" && !info.IsDir(), ext == ".app" && info.IsDir():
+	default:
+		return fmt.Errorf("not an .ipa or .app: %s", appPath)
+	}
+	var installer AppInstaller
+	var udid string
+	if installer, udid, err = c.installer(); err != nil {
+		return err
+	}
+	if err = installer.InstallApp(udid, appPath); err != nil {
+		return &WDAOriginError{Origin: c.Origin(), Err: fmt.Errorf("install %s: %w", appPath, err)}
+	}
+	return
+}
+
+// UninstallApp
+//
+// uninstalls the application with the AppInstaller of the Client
+func (c *Client) UninstallApp(bundleId string) (err error) {
+	if bundleId == "" {
+		return errors.New("'bundleId' is empty")
+	}
+	var installer AppInstaller
+	var udid string
+	if installer, udid, err = c.installer(); err != nil {
+		return err
+	}
+	if err = installer.UninstallApp(udid, bundleId); err != nil {
+		return &WDAOriginError{Origin: c.Origin(), Err: fmt.Errorf("uninstall %s: %w", bundleId, err)}
+	}
+	return
+}
+
+// InstalledApps
+//
+// Lists the user applications with the AppInstaller of the Client, it must be an AppLister.
+// WDA does not expose the installed applications, AppState only tells whether one is installed.
+func (c *Client) InstalledApps() (apps []WDAInstalledApp, err error) {
+	var installer AppInstaller
+	var udid string
+	if installer, udid, err = c.installer(); err != nil {
+		return nil, err
+	}
+	lister, ok := installer.(AppLister)
+	if !ok {
+		return nil, fmt.Errorf("the app installer %T can not list the applications", installer)
+	}
+	if apps, err = lister.InstalledApps(udid); err != nil {
+		return nil, &WDAOriginError{Origin: c.Origin(), Err: err}
+	}
+	return
+}
+
+// InstalledApps see Client.InstalledApps
+func (s *Session) InstalledApps() ([]WDAInstalledApp, error) {
+	if s.client == nil {
+		return nil, errors.New("session without client")
+	}
+	return s.client.InstalledApps()
+}
+
+// InstallApp see Client.InstallApp
+func (s *Session) InstallApp(appPath string) error {
+	if s.client == nil {
+		return errors.New("session without client")
+	}
+	return s.client.InstallApp(appPath)
+}
+
+// UninstallApp
+//
+// terminates the application if it is running, then see Client.UninstallApp
+func (s *Session) UninstallApp(bundleId string) error {
+	if s.client == nil {
+		return errors.New("session without client")
+	}
+	if state, err := s.AppState(bundleId); err == nil && (state == WDAAppRunningBack || state == WDAAppRunningFront) {
+		_ = s.AppTerminate(bundleId)
+	}
+	return s.client.UninstallApp(bundleId)
+}
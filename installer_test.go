@@ -0,0 +1,74 @@
+package gwda
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type recordingInstaller struct {
+	calls []string
+	err   error
+}
+
+func (ri *recordingInstaller) InstallApp(udid, appPath string) error {
+	ri.calls = append(ri.calls, "install "+udid+" "+filepath.Base(appPath))
+	return ri.err
+}
+
+func (ri *recordingInstaller) UninstallApp(udid, bundleId string) error {
+	ri.calls = append(ri.calls, "uninstall "+udid+" "+bundleId)
+	return ri.err
+}
+
+func TestClient_InstallApp(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gwda")
+	checkErr(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	ipa := filepath.Join(dir, "Demo.ipa")
+	checkErr(t, ioutil.WriteFile(ipa, []byte("PK"), 0644))
+
+	installer := new(recordingInstaller)
+	c := &Client{deviceURL: &url.URL{Scheme: "http", Host: "192.168.1.2:8100"}}
+	c.appInstaller = installer
+
+	if err := c.InstallApp(ipa); err == nil {
+		t.Fatal("expected an error without UDID")
+	}
+	c.udid = "00008030-001A2B3C4D5E6F"
+
+	checkErr(t, c.InstallApp(ipa))
+	checkErr(t, c.UninstallApp("com.example.demo"))
+	if len(installer.calls) != 2 || installer.calls[0] != "install 00008030-001A2B3C4D5E6F Demo.ipa" ||
+		installer.calls[1] != "uninstall 00008030-001A2B3C4D5E6F com.example.demo" {
+		t.Fatalf("unexpected calls: %q", installer.calls)
+	}
+
+	if err := c.InstallApp(filepath.Join(dir, "missing.ipa")); !os.IsNotExist(err) {
+		t.Fatalf("expected a missing file, got %v", err)
+	}
+	if err := c.InstallApp(dir); err == nil {
+		t.Fatal("expected an error for a directory which is not an .app")
+	}
+
+	installer.err = errors.New("device locked")
+	err = c.InstallApp(ipa)
+	if origin, ok := OriginOf(err); !ok || origin.UDID != c.udid || !errors.Is(err, installer.err) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func Test_parseIdeviceInstallerList(t *testing.T) {
+	apps, err := parseIdeviceInstallerList([]byte(`CFBundleIdentifier, CFBundleVersion, CFBundleDisplayName
+com.example.demo, "42", "Demo"
+com.example.lite, "7", "Demo, Lite"
+`))
+	checkErr(t, err)
+	if len(apps) != 2 || apps[0] != (WDAInstalledApp{BundleID: "com.example.demo", Name: "Demo", Version: "42"}) ||
+		apps[1].Name != "Demo, Lite" {
+		t.Fatalf("unexpected apps: %+v", apps)
+	}
+}
@@ -0,0 +1,187 @@
+package gwda
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type WDAScreenInterruptionType string
+
+const (
+	// WDAScreenInterruptionScreenTime an app limit of Screen Time has been reached
+	WDAScreenInterruptionScreenTime WDAScreenInterruptionType = "ScreenTime"
+	// WDAScreenInterruptionPasscode the device asks for the passcode again, e.g. after an update or a long lock
+	WDAScreenInterruptionPasscode WDAScreenInterruptionType = "Passcode"
+)
+
+// WDAScreenInterruption
+type WDAScreenInterruption struct {
+	Type    WDAScreenInterruptionType
+	Time    time.Time
+	Element *Element // matched by the detector
+}
+
+// ScreenInterruptionDetector
+//
+// an interruption is present when `Locator` matches any element
+type ScreenInterruptionDetector struct {
+	Type    WDAScreenInterruptionType
+	Locator WDALocator
+}
+
+// DefaultScreenInterruptionDetectors English and Chinese system UI
+var DefaultScreenInterruptionDetectors = []ScreenInterruptionDetector{
+	{
+		Type: WDAScreenInterruptionScreenTime,
+		Locator: WDALocator{Predicate: "type == 'XCUIElementTypeButton' AND " +
+			"label IN {'Ask For More Time','Ignore Limit','请求更多时间','忽略限额'}"},
+	},
+	{
+		Type: WDAScreenInterruptionPasscode,
+		Locator: WDALocator{Predicate: "(type == 'XCUIElementTypeStaticText' OR type == 'XCUIElementTypeSecureTextField') AND " +
+			"(label BEGINSWITH[c] 'Enter Passcode' OR label CONTAINS[c] 'iPhone Passcode' OR label BEGINSWITH '输入密码' OR label CONTAINS 'iPhone密码')"},
+	},
+}
+
+// ScreenInterruptionHandler
+//
+// e.g. enters the passcode or reports the run as stalled, an error goes to ScreenInterruptionWatcher.OnError
+type ScreenInterruptionHandler func(s *Session, interruption WDAScreenInterruption) error
+
+// DetectScreenInterruption
+//
+// runs the detectors once, `nil` means no interruption. Default is DefaultScreenInterruptionDetectors
+func (s *Session) DetectScreenInterruption(detectors ...ScreenInterruptionDetector) (interruption *WDAScreenInterruption, err error) {
+	if len(detectors) == 0 {
+		detectors = DefaultScreenInterruptionDetectors
+	}
+	for _, detector := range detectors {
+		it := s.Query().By(detector.Locator).Iter(context.Background())
+		found := it.Next()
+		elem := it.Element()
+		_ = it.Close()
+		if err = it.Err(); err != nil {
+			return nil, err
+		}
+		if found {
+			return &WDAScreenInterruption{Type: detector.Type, Time: time.Now(), Element: elem}, nil
+		}
+	}
+	return nil, nil
+}
+
+// ScreenInterruptionWatcher
+//
+// Polls the detectors in the background, so overnight runs don't silently stall on system interruptions.
+// An interruption is reported once when it appears, not on every poll while it stays.
+// The settings can also be changed while it runs.
+//
+//	watcher := s.NewScreenInterruptionWatcher().
+//		Handle(WDAScreenInterruptionPasscode, enterPasscode).
+//		OnError(func(err error) { log.Println(err) })
+//	watcher.Start()
+//	defer watcher.Stop()
+//	go func() {
+//		for interruption := range watcher.Events() {
+//		}
+//	}()
+type ScreenInterruptionWatcher struct {
+	session   *Session
+	detectors []ScreenInterruptionDetector
+	handlers  map[WDAScreenInterruptionType]ScreenInterruptionHandler // replaced, not changed, by Handle
+
+	events   chan WDAScreenInterruption
+	poller   *poller
+	previous WDAScreenInterruptionType
+}
+
+// NewScreenInterruptionWatcher
+//
+// polls every 5 seconds with DefaultScreenInterruptionDetectors
+func (s *Session) NewScreenInterruptionWatcher() *ScreenInterruptionWatcher {
+	events := make(chan WDAScreenInterruption, 16)
+	return &ScreenInterruptionWatcher{
+		session:   s,
+		detectors: DefaultScreenInterruptionDetectors,
+		handlers:  make(map[WDAScreenInterruptionType]ScreenInterruptionHandler),
+		events:    events,
+		poller:    newPoller(5*time.Second, events),
+	}
+}
+
+func (w *ScreenInterruptionWatcher) SetInterval(d time.Duration) *ScreenInterruptionWatcher {
+	w.poller.setInterval(d)
+	return w
+}
+
+func (w *ScreenInterruptionWatcher) SetDetectors(detectors ...ScreenInterruptionDetector) *ScreenInterruptionWatcher {
+	w.poller.locked(func() { w.detectors = detectors })
+	return w
+}
+
+func (w *ScreenInterruptionWatcher) Handle(interruptionType WDAScreenInterruptionType, handler ScreenInterruptionHandler) *ScreenInterruptionWatcher {
+	w.poller.locked(func() {
+		handlers := make(map[WDAScreenInterruptionType]ScreenInterruptionHandler, len(w.handlers)+1)
+		for k, v := range w.handlers {
+			handlers[k] = v
+		}
+		handlers[interruptionType] = handler
+		w.handlers = handlers
+	})
+	return w
+}
+
+// OnError
+//
+// called from the watcher goroutine with the errors of the detectors and of the handlers, they are only logged without it
+func (w *ScreenInterruptionWatcher) OnError(fn func(err error)) *ScreenInterruptionWatcher {
+	w.poller.setOnError(fn)
+	return w
+}
+
+// Events
+//
+// reported interruptions, closed by Stop. When nobody reads, the oldest ones are dropped
+func (w *ScreenInterruptionWatcher) Events() <-chan WDAScreenInterruption {
+	return w.events
+}
+
+func (w *ScreenInterruptionWatcher) Start() {
+	w.poller.start(w.poll, false)
+}
+
+// Stop waits for the running poll to finish
+func (w *ScreenInterruptionWatcher) Stop() {
+	w.poller.stop()
+}
+
+func (w *ScreenInterruptionWatcher) poll() (err error) {
+	if w.session.isSuspended() {
+		return nil
+	}
+	var detectors []ScreenInterruptionDetector
+	var handlers map[WDAScreenInterruptionType]ScreenInterruptionHandler
+	w.poller.locked(func() { detectors, handlers = w.detectors, w.handlers })
+
+	var interruption *WDAScreenInterruption
+	if interruption, err = w.session.DetectScreenInterruption(detectors...); err != nil {
+		return fmt.Errorf("screen interruption watcher: %w", err)
+	}
+	if interruption == nil {
+		w.previous = ""
+		return nil
+	}
+	if interruption.Type == w.previous {
+		return nil
+	}
+	w.previous = interruption.Type
+
+	w.poller.publish(*interruption)
+	if handler, ok := handlers[interruption.Type]; ok {
+		if err = handler(w.session, *interruption); err != nil {
+			return fmt.Errorf("screen interruption handler %s: %w", interruption.Type, err)
+		}
+	}
+	return nil
+}
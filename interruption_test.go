@@ -0,0 +1,30 @@
+package gwda
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSession_DetectScreenInterruption(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+
+	interruption, err := s.DetectScreenInterruption()
+	checkErr(t, err)
+	t.Log(interruption)
+
+	watcher := s.NewScreenInterruptionWatcher().
+		SetInterval(time.Second).
+		Handle(WDAScreenInterruptionPasscode, func(s *Session, interruption WDAScreenInterruption) error {
+			t.Log("passcode required at", interruption.Time)
+			return nil
+		})
+	watcher.Start()
+	time.Sleep(3 * time.Second)
+	watcher.Stop()
+	for interruption := range watcher.Events() {
+		t.Log(interruption.Type, interruption.Time)
+	}
+}
@@ -0,0 +1,87 @@
+package gwda
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync/atomic"
+)
+
+// JSONDecoder
+//
+// decodes the values of the responses, e.g. a faster drop-in replacement of encoding/json for polling loops
+type JSONDecoder interface {
+	Unmarshal(data []byte, v interface{}) error
+}
+
+type stdJSONDecoder struct{}
+
+func (stdJSONDecoder) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// jsonDecoderHolder an atomic.Value always stores the same concrete type
+type jsonDecoderHolder struct {
+	JSONDecoder
+}
+
+var jsonDecoder atomic.Value
+
+func init() {
+	jsonDecoder.Store(jsonDecoderHolder{stdJSONDecoder{}})
+}
+
+// SetJSONDecoder
+//
+// Replaces encoding/json for the responses, `nil` restores it. The decoder must honor the `json` struct tags.
+// Streamed responses (Query.Iter, SourceReader, ...) still use encoding/json.
+func SetJSONDecoder(dec JSONDecoder) {
+	if dec == nil {
+		dec = stdJSONDecoder{}
+	}
+	jsonDecoder.Store(jsonDecoderHolder{dec})
+}
+
+func jsonUnmarshal(data []byte, v interface{}) error {
+	return jsonDecoder.Load().(jsonDecoderHolder).Unmarshal(data, v)
+}
+
+// wdaElementRef `{"ELEMENT": "..."}` or `{"element-6066-11e4-a52e-4f735466cecf": "..."}`
+type wdaElementRef struct {
+	ELEMENT string `json:"ELEMENT"`
+	W3C     string `json:"element-6066-11e4-a52e-4f735466cecf"`
+}
+
+func (ref wdaElementRef) uid() string {
+	if ref.ELEMENT != "" {
+		return ref.ELEMENT
+	}
+	return ref.W3C
+}
+
+// valueString
+//
+// decodes a string value directly, other values are returned as their JSON text and `null` as ""
+func (wdaResp wdaResponse) valueString() (s string, err error) {
+	raw := rawPath(wdaResp, "value")
+	switch {
+	case len(raw) == 0 || bytes.Equal(raw, []byte("null")):
+		return "", nil
+	case raw[0] == '"':
+		err = jsonUnmarshal(raw, &s)
+		return
+	default:
+		return string(raw), nil
+	}
+}
+
+func (wdaResp wdaResponse) valueBool() bool {
+	return bytes.Equal(rawPath(wdaResp, "value"), []byte("true"))
+}
+
+// mayBeError
+//
+// `false` when the response has neither `error` nor `status`, so getErrMsg can skip the lookups.
+// Both keys are matched with their quotes, the same words escaped inside a string value can't match.
+func (wdaResp wdaResponse) mayBeError() bool {
+	return bytes.Contains(wdaResp, []byte(`"error"`)) || bytes.Contains(wdaResp, []byte(`"status"`))
+}
@@ -0,0 +1,59 @@
+package gwda
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type countingDecoder struct {
+	n int
+}
+
+func (d *countingDecoder) Unmarshal(data []byte, v interface{}) error {
+	d.n++
+	return json.Unmarshal(data, v)
+}
+
+func TestSetJSONDecoder(t *testing.T) {
+	dec := new(countingDecoder)
+	SetJSONDecoder(dec)
+	defer SetJSONDecoder(nil)
+
+	var ref wdaElementRef
+	_, err := wdaResponse(`{"value":{"element-6066-11e4-a52e-4f735466cecf":"E1"},"sessionId":"S"}`).unmarshalValue(&ref)
+	checkErr(t, err)
+	if ref.uid() != "E1" || dec.n != 1 {
+		t.Error(ref, dec.n)
+	}
+}
+
+func Test_wdaResponse_valueString(t *testing.T) {
+	for resp, want := range map[string]string{
+		`{"value":"a\"b中","sessionId":"S"}`: `a"b中`,
+		`{"value":null,"sessionId":"S"}`:    "",
+		`{"value":true,"sessionId":"S"}`:    "true",
+		`{"value":12.5,"sessionId":"S"}`:    "12.5",
+		`{"sessionId":"S"}`:                 "",
+	} {
+		got, err := wdaResponse(resp).valueString()
+		checkErr(t, err)
+		if got != want {
+			t.Errorf("%s: got %q, want %q", resp, got, want)
+		}
+	}
+	if !wdaResponse(`{"value" : true}`).valueBool() || wdaResponse(`{"value":false}`).valueBool() {
+		t.Error("valueBool")
+	}
+}
+
+func Test_wdaResponse_mayBeError(t *testing.T) {
+	for resp, want := range map[string]bool{
+		`{"value":{"error":"no such element","message":"..."}}`: true,
+		`{"status":7,"value":"..."}`:                            true,
+		`{"value":"<a label=\"error\"/>","sessionId":"S"}`:      false,
+	} {
+		if got := wdaResponse(resp).mayBeError(); got != want {
+			t.Errorf("%s: got %v", resp, got)
+		}
+	}
+}
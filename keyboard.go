@@ -0,0 +1,135 @@
+package gwda
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// KeyboardGlobeKeyNames names of the key switching the keyboard, by system language
+var KeyboardGlobeKeyNames = []string{"Next keyboard", "下一个键盘", "切換鍵盤", "次のキーボード"}
+
+// SwitchKeyboardLanguage
+//
+// Long-presses the globe key of the visible keyboard and selects the keyboard whose name starts with `target`
+// (case-insensitive), e.g. `English` or `简体中文`. The keyboards must have been added in the settings of the device.
+func (s *Session) SwitchKeyboardLanguage(target string) (err error) {
+	var globe *Element
+	if globe, err = s.FindElement(WDALocator{Predicate: fmt.Sprintf(
+		"type == 'XCUIElementTypeButton' AND name IN {%s}", predicateStringList(KeyboardGlobeKeyNames))}); err != nil {
+		return fmt.Errorf("globe key: %w", err)
+	}
+	if err = globe.TouchAndHoldFloat(1); err != nil {
+		return err
+	}
+
+	// the menu lists the keyboards as cells (or plain texts on older systems)
+	locator := WDALocator{Predicate: fmt.Sprintf(
+		"type IN {'XCUIElementTypeCell', 'XCUIElementTypeStaticText'} AND label BEGINSWITH[c] %s", predicateString(target))}
+	var item *Element
+	condition := func(s *Session) (bool, error) {
+		item, err = s.FindElement(locator)
+		return err == nil, nil
+	}
+	if errWait := s._waitWithTimeoutAndInterval(condition, 3*time.Second, waitInterval()); errWait != nil {
+		// close the menu
+		_ = globe.Click()
+		return fmt.Errorf("keyboard '%s' is not in the menu: %w", target, err)
+	}
+	return item.Click()
+}
+
+// WDAKeyboardDismissStrategy see DismissKeyboard
+type WDAKeyboardDismissStrategy string
+
+const (
+	// WDAKeyboardDismissEndpoint `/wda/keyboard/dismiss`, unreliable on iPhone
+	WDAKeyboardDismissEndpoint WDAKeyboardDismissStrategy = "endpoint"
+	// WDAKeyboardDismissKey taps a key of KeyboardDismissKeyNames
+	WDAKeyboardDismissKey WDAKeyboardDismissStrategy = "key"
+	// WDAKeyboardDismissTapOutside taps above the keyboard, it may hit a control of the application
+	WDAKeyboardDismissTapOutside WDAKeyboardDismissStrategy = "tapOutside"
+)
+
+// DefaultKeyboardDismissStrategies tried in order by DismissKeyboard
+var DefaultKeyboardDismissStrategies = []WDAKeyboardDismissStrategy{
+	WDAKeyboardDismissEndpoint, WDAKeyboardDismissKey, WDAKeyboardDismissTapOutside,
+}
+
+// KeyboardDismissKeyNames names of the keys closing the keyboard, by system language
+var KeyboardDismissKeyNames = []string{"Done", "Return", "return", "Hide keyboard", "完成", "换行", "確認", "完了"}
+
+// DismissKeyboard
+//
+// Tries `strategies` (DefaultKeyboardDismissStrategies when none) in order until the keyboard is gone
+// and returns the one which worked. It is not an error when there is no keyboard, `""` is returned then.
+func (s *Session) DismissKeyboard(strategies ...WDAKeyboardDismissStrategy) (used WDAKeyboardDismissStrategy, err error) {
+	if len(strategies) == 0 {
+		strategies = DefaultKeyboardDismissStrategies
+	}
+	var keyboard *Element
+	if keyboard, err = s.keyboard(); err != nil || keyboard == nil {
+		return "", err
+	}
+	var tried []string
+	for _, strategy := range strategies {
+		var errStrategy error
+		switch strategy {
+		case WDAKeyboardDismissEndpoint:
+			// [FBRoute POST:@"/wda/keyboard/dismiss"]
+			_, errStrategy = executePost(s.client, "DismissKeyboard", urlJoin(s.sessionURL(), "/wda/keyboard/dismiss"), nil)
+		case WDAKeyboardDismissKey:
+			var key *Element
+			if key, errStrategy = keyboard.FindElement(WDALocator{Predicate: fmt.Sprintf(
+				"type IN {'XCUIElementTypeButton', 'XCUIElementTypeKey'} AND name IN {%s}", predicateStringList(KeyboardDismissKeyNames))}); errStrategy == nil {
+				errStrategy = key.Click()
+			}
+		case WDAKeyboardDismissTapOutside:
+			var rect WDARect
+			if rect, errStrategy = keyboard.Rect(); errStrategy == nil {
+				errStrategy = s.Tap(rect.X+rect.Width/2, rect.Y/2)
+			}
+		default:
+			return "", fmt.Errorf("unknown keyboard dismiss strategy '%s'", strategy)
+		}
+		if errStrategy == nil {
+			var gone bool
+			if gone, err = s.waitKeyboardGone(time.Second); err != nil {
+				return "", err
+			}
+			if gone {
+				return strategy, nil
+			}
+			errStrategy = errors.New("keyboard still shown")
+		}
+		tried = append(tried, fmt.Sprintf("%s: %s", strategy, errStrategy))
+	}
+	return "", fmt.Errorf("keyboard not dismissed (%s)", strings.Join(tried, "; "))
+}
+
+// keyboard the visible keyboard, `nil` without one
+func (s *Session) keyboard() (keyboard *Element, err error) {
+	var elemUIDs []string
+	if elemUIDs, err = findUidOfElements(s.client, s.sessionURL(), WDALocator{ClassName: WDAElementType{Keyboard: true}}); err != nil {
+		if isNoSuchElement(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return newElement(s.client, s.sessionURL(), elemUIDs[0]), nil
+}
+
+func (s *Session) waitKeyboardGone(timeout time.Duration) (gone bool, err error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		var keyboard *Element
+		if keyboard, err = s.keyboard(); err != nil || keyboard == nil {
+			return err == nil, err
+		}
+		if time.Now().Add(waitInterval()).After(deadline) {
+			return false, nil
+		}
+		time.Sleep(waitInterval())
+	}
+}
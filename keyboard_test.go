@@ -0,0 +1,30 @@
+package gwda
+
+import "testing"
+
+func TestSession_SwitchKeyboardLanguage(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+
+	// a keyboard must be visible, e.g. the search field of the Settings
+	checkErr(t, s.SwitchKeyboardLanguage("English"))
+	checkErr(t, s.SendKeys("hello"))
+}
+
+func TestSession_DismissKeyboard(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+
+	// a keyboard must be visible, e.g. the search field of the Settings
+	used, err := s.DismissKeyboard()
+	checkErr(t, err)
+	t.Log(used)
+
+	if used, err = s.DismissKeyboard(); err != nil || used != "" {
+		t.Fatalf("expected nothing to dismiss, got %q, %v", used, err)
+	}
+}
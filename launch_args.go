@@ -0,0 +1,150 @@
+package gwda
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// WDAContentSizeCategory the Dynamic Type text size, see WDAAppLaunchOption.SetContentSizeCategory
+type WDAContentSizeCategory string
+
+const (
+	WDAContentSizeCategoryExtraSmall                        WDAContentSizeCategory = "UICTContentSizeCategoryXS"
+	WDAContentSizeCategorySmall                             WDAContentSizeCategory = "UICTContentSizeCategoryS"
+	WDAContentSizeCategoryMedium                            WDAContentSizeCategory = "UICTContentSizeCategoryM"
+	WDAContentSizeCategoryLarge                             WDAContentSizeCategory = "UICTContentSizeCategoryL"
+	WDAContentSizeCategoryExtraLarge                        WDAContentSizeCategory = "UICTContentSizeCategoryXL"
+	WDAContentSizeCategoryExtraExtraLarge                   WDAContentSizeCategory = "UICTContentSizeCategoryXXL"
+	WDAContentSizeCategoryExtraExtraExtraLarge              WDAContentSizeCategory = "UICTContentSizeCategoryXXXL"
+	WDAContentSizeCategoryAccessibilityMedium               WDAContentSizeCategory = "UICTContentSizeCategoryAccessibilityM"
+	WDAContentSizeCategoryAccessibilityLarge                WDAContentSizeCategory = "UICTContentSizeCategoryAccessibilityL"
+	WDAContentSizeCategoryAccessibilityExtraLarge           WDAContentSizeCategory = "UICTContentSizeCategoryAccessibilityXL"
+	WDAContentSizeCategoryAccessibilityExtraExtraLarge      WDAContentSizeCategory = "UICTContentSizeCategoryAccessibilityXXL"
+	WDAContentSizeCategoryAccessibilityExtraExtraExtraLarge WDAContentSizeCategory = "UICTContentSizeCategoryAccessibilityXXXL"
+)
+
+var (
+	languageTagRegexp = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+	localeRegexp      = regexp.MustCompile(`^[A-Za-z]{2,3}(_[A-Za-z0-9]{2,8})*(@[A-Za-z0-9=;-]+)?$`)
+	plistPlainRegexp  = regexp.MustCompile(`^[A-Za-z0-9_$+/:.-]+$`)
+)
+
+func (alo WDAAppLaunchOption) arguments() []string {
+	args, _ := alo["arguments"].([]string)
+	return args
+}
+
+func (alo WDAAppLaunchOption) environment() map[string]string {
+	env, _ := alo["environment"].(map[string]string)
+	return env
+}
+
+// AddArguments appends to the arguments (see SetArguments)
+func (alo WDAAppLaunchOption) AddArguments(args ...string) WDAAppLaunchOption {
+	return alo.SetArguments(append(append([]string(nil), alo.arguments()...), args...))
+}
+
+// AddEnvironment adds an environment variable (see SetEnvironment)
+func (alo WDAAppLaunchOption) AddEnvironment(key, value string) WDAAppLaunchOption {
+	env := make(map[string]string, len(alo.environment())+1)
+	for k, v := range alo.environment() {
+		env[k] = v
+	}
+	env[key] = value
+	return alo.SetEnvironment(env)
+}
+
+// SetUserDefault
+//
+// Overrides a user default of the application for this launch (`-key value`), the value is escaped for it.
+func (alo WDAAppLaunchOption) SetUserDefault(key, value string) WDAAppLaunchOption {
+	return alo.setArgumentPair("-"+key, plistString(value))
+}
+
+// SetAppleLanguages
+//
+// the preferred languages of the application, e.g. `en`, `zh-Hans`
+func (alo WDAAppLaunchOption) SetAppleLanguages(languages ...string) WDAAppLaunchOption {
+	quoted := make([]string, len(languages))
+	for i := range languages {
+		quoted[i] = plistString(languages[i])
+	}
+	return alo.setArgumentPair("-AppleLanguages", "("+strings.Join(quoted, ", ")+")")
+}
+
+// SetAppleLocale e.g. `en_US`, `zh_CN`
+func (alo WDAAppLaunchOption) SetAppleLocale(locale string) WDAAppLaunchOption {
+	return alo.setArgumentPair("-AppleLocale", locale)
+}
+
+// SetContentSizeCategory the Dynamic Type text size of the application
+func (alo WDAAppLaunchOption) SetContentSizeCategory(category WDAContentSizeCategory) WDAAppLaunchOption {
+	return alo.setArgumentPair("-UIPreferredContentSizeCategoryName", string(category))
+}
+
+// setArgumentPair replaces the value of `flag`, or appends both
+func (alo WDAAppLaunchOption) setArgumentPair(flag, value string) WDAAppLaunchOption {
+	args := append([]string(nil), alo.arguments()...)
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == flag {
+			args[i+1] = value
+			return alo.SetArguments(args)
+		}
+	}
+	return alo.SetArguments(append(args, flag, value))
+}
+
+// Validate
+//
+// Checks the values of the typed helpers and the environment, AppLaunch validates the option before sending it.
+func (alo WDAAppLaunchOption) Validate() error {
+	args := alo.arguments()
+	for i := 0; i < len(args); i++ {
+		flag := args[i]
+		if !strings.HasPrefix(flag, "-") || flag == "-" {
+			continue
+		}
+		var value string
+		if i+1 < len(args) {
+			value = args[i+1]
+		}
+		switch flag {
+		case "-AppleLanguages":
+			list := strings.TrimSuffix(strings.TrimPrefix(value, "("), ")")
+			if list == value || strings.TrimSpace(list) == "" {
+				return fmt.Errorf("launch arguments: -AppleLanguages expects a list, got '%s'", value)
+			}
+			for _, lang := range strings.Split(list, ",") {
+				if lang = strings.Trim(strings.TrimSpace(lang), `"`); !languageTagRegexp.MatchString(lang) {
+					return fmt.Errorf("launch arguments: invalid language '%s'", lang)
+				}
+			}
+		case "-AppleLocale":
+			if !localeRegexp.MatchString(value) {
+				return fmt.Errorf("launch arguments: invalid locale '%s'", value)
+			}
+		case "-UIPreferredContentSizeCategoryName":
+			if !strings.HasPrefix(value, "UICTContentSizeCategory") {
+				return fmt.Errorf("launch arguments: invalid content size category '%s'", value)
+			}
+		default:
+			continue
+		}
+		i++
+	}
+	for k := range alo.environment() {
+		if k == "" || strings.ContainsAny(k, "=\x00") {
+			return fmt.Errorf("launch environment: invalid variable name '%s'", k)
+		}
+	}
+	return nil
+}
+
+// plistString quotes `s` as an old-style property list string when needed
+func plistString(s string) string {
+	if plistPlainRegexp.MatchString(s) {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
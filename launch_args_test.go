@@ -0,0 +1,48 @@
+package gwda
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWDAAppLaunchOption_TypedArguments(t *testing.T) {
+	opt := NewWDAAppLaunchOption().
+		SetArguments([]string{"-UITest"}).
+		SetAppleLanguages("zh-Hans", "en").
+		SetAppleLocale("zh_CN").
+		SetContentSizeCategory(WDAContentSizeCategoryExtraLarge).
+		SetUserDefault("greeting", `say "hi"`).
+		SetAppleLanguages("en")
+	want := []string{
+		"-UITest",
+		"-AppleLanguages", "(en)",
+		"-AppleLocale", "zh_CN",
+		"-UIPreferredContentSizeCategoryName", "UICTContentSizeCategoryXL",
+		"-greeting", `"say \"hi\""`,
+	}
+	if got := opt.arguments(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("arguments = %q, want %q", got, want)
+	}
+	if got := NewWDAAppLaunchOption().SetAppleLanguages("zh-Hans", "en").arguments()[1]; got != "(zh-Hans, en)" {
+		t.Errorf("-AppleLanguages = %s", got)
+	}
+	if err := opt.AddEnvironment("UITEST_MODE", "1").Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWDAAppLaunchOption_Validate(t *testing.T) {
+	for _, opt := range []WDAAppLaunchOption{
+		NewWDAAppLaunchOption().SetAppleLanguages("english!"),
+		NewWDAAppLaunchOption().SetAppleLanguages(),
+		NewWDAAppLaunchOption().SetAppleLocale("en US"),
+		NewWDAAppLaunchOption().SetContentSizeCategory("huge"),
+		NewWDAAppLaunchOption().AddArguments("-AppleLocale"),
+		NewWDAAppLaunchOption().AddEnvironment("A=B", "1"),
+	} {
+		if err := opt.Validate(); err == nil || !strings.HasPrefix(err.Error(), "launch") {
+			t.Errorf("Validate(%v) = %v", opt, err)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package gwda
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DefaultPingTimeout used by Ping without a timeout, unless a WDAConfig sets one
+var DefaultPingTimeout = 5 * time.Second
+
+// Ping
+//
+// checks WDA answers `/status` within `timeout` (default DefaultPingTimeout),
+// unlike HealthCheck it doesn't touch the UI
+func (c *Client) Ping(timeout ...time.Duration) (err error) {
+	return ping(c, "Ping", urlJoin(c.deviceURL, "/status"), timeout...)
+}
+
+// Ping
+//
+// checks WDA answers within `timeout` (default DefaultPingTimeout) and still knows this session
+func (s *Session) Ping(timeout ...time.Duration) (err error) {
+	return ping(s.client, "Ping", urlJoin(s.sessionURL(), ""), timeout...)
+}
+
+func ping(c *Client, actionName, sURL string, timeout ...time.Duration) (err error) {
+	if len(timeout) == 0 {
+		timeout = []time.Duration{pingTimeout()}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout[0])
+	defer cancel()
+	_, err = executeHTTPContext(ctx, c, actionName, http.MethodGet, sURL, nil)
+	return
+}
+
+// WDALivenessEvent
+type WDALivenessEvent struct {
+	Alive bool
+	Time  time.Time
+	Err   error // the last failed ping when WDA is down
+}
+
+// LivenessWatchdog
+//
+// Pings WDA in the background and reports when it stops responding, and when it is back,
+// so the orchestration can restart it. The settings can also be changed while it runs.
+//
+//	watchdog := c.NewLivenessWatchdog().
+//		OnChange(func(c *Client, event WDALivenessEvent) {
+//			if !event.Alive {
+//				restartWDA(c)
+//			}
+//		})
+//	watchdog.Start()
+//	defer watchdog.Stop()
+type LivenessWatchdog struct {
+	client    *Client
+	timeout   time.Duration
+	threshold int
+	onChange  func(c *Client, event WDALivenessEvent)
+
+	events   chan WDALivenessEvent
+	poller   *poller
+	alive    bool
+	failures int
+}
+
+// NewLivenessWatchdog
+//
+// pings every 10 seconds with DefaultPingTimeout, WDA is down after 2 failed pings in a row
+func (c *Client) NewLivenessWatchdog() *LivenessWatchdog {
+	events := make(chan WDALivenessEvent, 16)
+	return &LivenessWatchdog{
+		client:    c,
+		timeout:   pingTimeout(),
+		threshold: 2,
+		events:    events,
+		poller:    newPoller(10*time.Second, events),
+		alive:     true,
+	}
+}
+
+func (w *LivenessWatchdog) SetInterval(d time.Duration) *LivenessWatchdog {
+	w.poller.setInterval(d)
+	return w
+}
+
+// SetTimeout of every ping
+func (w *LivenessWatchdog) SetTimeout(d time.Duration) *LivenessWatchdog {
+	w.poller.locked(func() { w.timeout = d })
+	return w
+}
+
+// SetFailureThreshold
+//
+// failed pings in a row before WDA is reported down
+func (w *LivenessWatchdog) SetFailureThreshold(n int) *LivenessWatchdog {
+	if n < 1 {
+		n = 1
+	}
+	w.poller.locked(func() { w.threshold = n })
+	return w
+}
+
+// OnChange
+//
+// called from the watchdog goroutine on every reported event
+func (w *LivenessWatchdog) OnChange(fn func(c *Client, event WDALivenessEvent)) *LivenessWatchdog {
+	w.poller.locked(func() { w.onChange = fn })
+	return w
+}
+
+// OnError
+//
+// called from the watchdog goroutine with every failed ping, also the ones below the threshold,
+// they are only logged without it
+func (w *LivenessWatchdog) OnError(fn func(err error)) *LivenessWatchdog {
+	w.poller.setOnError(fn)
+	return w
+}
+
+// Events
+//
+// reported events, closed by Stop. When nobody reads, the oldest ones are dropped
+func (w *LivenessWatchdog) Events() <-chan WDALivenessEvent {
+	return w.events
+}
+
+func (w *LivenessWatchdog) Start() {
+	w.poller.start(w.poll, false)
+}
+
+// Stop waits for the running ping to finish
+func (w *LivenessWatchdog) Stop() {
+	w.poller.stop()
+}
+
+func (w *LivenessWatchdog) poll() error {
+	var timeout time.Duration
+	var threshold int
+	var onChange func(c *Client, event WDALivenessEvent)
+	w.poller.locked(func() { timeout, threshold, onChange = w.timeout, w.threshold, w.onChange })
+
+	err := w.client.Ping(timeout)
+	if err != nil {
+		if w.failures++; w.failures < threshold || !w.alive {
+			return fmt.Errorf("liveness watchdog: %w", err)
+		}
+	} else {
+		w.failures = 0
+		if w.alive {
+			return nil
+		}
+	}
+	w.alive = err == nil
+	event := WDALivenessEvent{Alive: w.alive, Time: time.Now(), Err: err}
+	if onChange != nil {
+		onChange(w.client, event)
+	}
+	w.poller.publish(event)
+	if err != nil {
+		return fmt.Errorf("liveness watchdog: %w", err)
+	}
+	return nil
+}
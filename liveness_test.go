@@ -0,0 +1,35 @@
+package gwda
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClient_Ping(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	checkErr(t, c.Ping(time.Second))
+
+	s, err := c.NewSession()
+	checkErr(t, err)
+	checkErr(t, s.Ping())
+}
+
+func TestLivenessWatchdog(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+
+	watchdog := c.NewLivenessWatchdog().
+		SetInterval(time.Second).
+		SetTimeout(500 * time.Millisecond).
+		OnChange(func(c *Client, event WDALivenessEvent) {
+			t.Log("alive:", event.Alive, event.Err)
+		})
+	watchdog.Start()
+	// stop WDA meanwhile to see it reported
+	time.Sleep(10 * time.Second)
+	watchdog.Stop()
+	for event := range watchdog.Events() {
+		t.Log(event.Alive, event.Time)
+	}
+}
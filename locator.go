@@ -0,0 +1,252 @@
+package gwda
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ByName the element whose `name` (accessibility identifier, or label) is `name`
+func ByName(name string) WDALocator {
+	return WDALocator{Name: name}
+}
+
+// ByLabel the element whose `label` is `label`
+func ByLabel(label string) WDALocator {
+	return WDALocator{Predicate: NewWDAPredicate().Equal(WDAPredicateLabel, label).String()}
+}
+
+// ByXPath slow on large hierarchies, prefer ByPredicate or ByClassChain
+func ByXPath(xpath string) WDALocator {
+	return WDALocator{XPath: xpath}
+}
+
+// ByPredicate
+//
+//	elem, err := s.FindElement(ByPredicate(NewWDAPredicate().
+//		Type(WDAElementType{Button: true}).
+//		BeginsWith(WDAPredicateLabel, `Say "hi"`)))
+func ByPredicate(predicate *WDAPredicate) WDALocator {
+	return WDALocator{Predicate: predicate.String()}
+}
+
+// ByClassChain
+//
+//	elem, err := s.FindElement(ByClassChain(NewWDAClassChain().
+//		Descendant(WDAElementType{Cell: true}, NewWDAPredicate().Equal(WDAPredicateName, "Wi-Fi")).
+//		Child(WDAElementType{Switch: true})))
+func ByClassChain(classChain *WDAClassChain) WDALocator {
+	return WDALocator{ClassChain: classChain.String()}
+}
+
+// WDAPredicateAttribute an attribute of the elements usable in an NSPredicate
+type WDAPredicateAttribute string
+
+const (
+	WDAPredicateName       WDAPredicateAttribute = "name"
+	WDAPredicateLabel      WDAPredicateAttribute = "label"
+	WDAPredicateValue      WDAPredicateAttribute = "value"
+	WDAPredicateType       WDAPredicateAttribute = "type"
+	WDAPredicateIdentifier WDAPredicateAttribute = "identifier"
+	WDAPredicateVisible    WDAPredicateAttribute = "visible"
+	WDAPredicateEnabled    WDAPredicateAttribute = "enabled"
+	WDAPredicateSelected   WDAPredicateAttribute = "selected"
+	WDAPredicateAccessible WDAPredicateAttribute = "accessible"
+)
+
+// WDAPredicate
+//
+// Builds an NSPredicate, the conditions are joined with AND, the values are quoted and escaped.
+type WDAPredicate struct {
+	conditions []string
+}
+
+func NewWDAPredicate() *WDAPredicate {
+	return &WDAPredicate{}
+}
+
+func (p *WDAPredicate) compare(attr WDAPredicateAttribute, operator, value string) *WDAPredicate {
+	p.conditions = append(p.conditions, fmt.Sprintf("%s %s %s", attr, operator, predicateString(value)))
+	return p
+}
+
+// Type the element type, WDAElementType{Any: true} matches all of them
+func (p *WDAPredicate) Type(elemType WDAElementType) *WDAPredicate {
+	if elemType.Any {
+		return p
+	}
+	return p.compare(WDAPredicateType, "==", elemType.String())
+}
+
+func (p *WDAPredicate) Equal(attr WDAPredicateAttribute, value string) *WDAPredicate {
+	return p.compare(attr, "==", value)
+}
+
+func (p *WDAPredicate) NotEqual(attr WDAPredicateAttribute, value string) *WDAPredicate {
+	return p.compare(attr, "!=", value)
+}
+
+func (p *WDAPredicate) Contains(attr WDAPredicateAttribute, value string) *WDAPredicate {
+	return p.compare(attr, "CONTAINS", value)
+}
+
+func (p *WDAPredicate) BeginsWith(attr WDAPredicateAttribute, value string) *WDAPredicate {
+	return p.compare(attr, "BEGINSWITH", value)
+}
+
+func (p *WDAPredicate) EndsWith(attr WDAPredicateAttribute, value string) *WDAPredicate {
+	return p.compare(attr, "ENDSWITH", value)
+}
+
+// Like with the wildcards `*` and `?`
+func (p *WDAPredicate) Like(attr WDAPredicateAttribute, pattern string) *WDAPredicate {
+	return p.compare(attr, "LIKE", pattern)
+}
+
+// Matches an ICU regular expression matching the whole value
+func (p *WDAPredicate) Matches(attr WDAPredicateAttribute, regexp string) *WDAPredicate {
+	return p.compare(attr, "MATCHES", regexp)
+}
+
+// Is a boolean attribute, e.g. WDAPredicateVisible
+func (p *WDAPredicate) Is(attr WDAPredicateAttribute, b bool) *WDAPredicate {
+	p.conditions = append(p.conditions, fmt.Sprintf("%s == %d", attr, boolToInt(b)))
+	return p
+}
+
+// Or
+//
+// adds one condition which is true when any of `predicates` is
+func (p *WDAPredicate) Or(predicates ...*WDAPredicate) *WDAPredicate {
+	alternatives := make([]string, 0, len(predicates))
+	for _, other := range predicates {
+		if s := other.String(); s != "" {
+			alternatives = append(alternatives, "("+s+")")
+		}
+	}
+	if len(alternatives) != 0 {
+		p.conditions = append(p.conditions, "("+strings.Join(alternatives, " OR ")+")")
+	}
+	return p
+}
+
+func (p *WDAPredicate) String() string {
+	return strings.Join(p.conditions, " AND ")
+}
+
+// predicateString a single quoted NSPredicate string literal, the quotes, backslashes and control characters escaped
+func predicateString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`, "\t", `\t`).Replace(s) + "'"
+}
+
+// predicateStringList the values of an `IN {...}` list
+func predicateStringList(list []string) string {
+	quoted := make([]string, len(list))
+	for i := range list {
+		quoted[i] = predicateString(list[i])
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// WDAClassChain
+//
+// Builds an iOS class chain, e.g. `**/XCUIElementTypeCell[`name == "Wi-Fi"`]/XCUIElementTypeSwitch[1]`
+type WDAClassChain struct {
+	segments []string
+}
+
+func NewWDAClassChain() *WDAClassChain {
+	return &WDAClassChain{}
+}
+
+func (cc *WDAClassChain) segment(prefix string, elemType WDAElementType, predicates []*WDAPredicate) *WDAClassChain {
+	class := "*"
+	if !elemType.Any && elemType.String() != "UNKNOWN" {
+		class = elemType.String()
+	}
+	return cc.segmentOfClass(prefix, class, predicates)
+}
+
+func (cc *WDAClassChain) segmentOfClass(prefix, class string, predicates []*WDAPredicate) *WDAClassChain {
+	segment := prefix + class
+	for _, predicate := range predicates {
+		if s := predicate.String(); s != "" {
+			// a backtick of the predicate is escaped by doubling it
+			segment += "[`" + strings.Replace(s, "`", "``", -1) + "`]"
+		}
+	}
+	cc.segments = append(cc.segments, segment)
+	return cc
+}
+
+// Child a direct child of the previous segment (or of the root)
+func (cc *WDAClassChain) Child(elemType WDAElementType, predicates ...*WDAPredicate) *WDAClassChain {
+	return cc.segment("", elemType, predicates)
+}
+
+// Descendant any descendant of the previous segment (or of the root)
+func (cc *WDAClassChain) Descendant(elemType WDAElementType, predicates ...*WDAPredicate) *WDAClassChain {
+	return cc.segment("**/", elemType, predicates)
+}
+
+// Index
+//
+// keeps only the nth (from 1, negative from the end) of the elements matched by the last segment
+func (cc *WDAClassChain) Index(n int) *WDAClassChain {
+	if last := len(cc.segments) - 1; last >= 0 {
+		cc.segments[last] += fmt.Sprintf("[%d]", n)
+	}
+	return cc
+}
+
+func (cc *WDAClassChain) String() string {
+	return strings.Join(cc.segments, "/")
+}
+
+// nthLocator the class chain matching only the nth (from 1, negative from the end) element of `wdaLocator`,
+// `false` when it can not be expressed as one
+func nthLocator(wdaLocator WDALocator, nth int) (WDALocator, bool) {
+	using, value := wdaLocator.getUsingAndValue()
+	classChain := NewWDAClassChain()
+	switch using {
+	case "class chain":
+		// the index of a segment counts the matches per parent, only a single `**/` segment counts them all;
+		// an index of the last segment already picks one element
+		if !isDescendantSegment(value) || (strings.HasSuffix(value, "]") && !strings.HasSuffix(value, "`]")) {
+			return WDALocator{}, false
+		}
+		return WDALocator{ClassChain: fmt.Sprintf("%s[%d]", value, nth)}, true
+	case "class name":
+		classChain.segmentOfClass("**/", value, nil)
+	case "predicate string":
+		classChain.segmentOfClass("**/", "*", []*WDAPredicate{{conditions: []string{value}}})
+	case "name", "id", "accessibility id":
+		classChain.segmentOfClass("**/", "*", []*WDAPredicate{NewWDAPredicate().Equal(WDAPredicateName, value)})
+	default:
+		return WDALocator{}, false
+	}
+	return ByClassChain(classChain.Index(nth)), true
+}
+
+// isDescendantSegment whether `classChain` is a single `**/` segment, the `/` of its predicates aside
+func isDescendantSegment(classChain string) bool {
+	if !strings.HasPrefix(classChain, "**/") {
+		return false
+	}
+	quoted := false
+	for _, r := range classChain[len("**/"):] {
+		switch {
+		case r == '`':
+			quoted = !quoted
+		case r == '/' && !quoted:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package gwda
+
+import "testing"
+
+func TestWDAPredicate(t *testing.T) {
+	predicate := NewWDAPredicate().
+		Type(WDAElementType{Button: true}).
+		BeginsWith(WDAPredicateLabel, `Say "hi" \o/`).
+		Is(WDAPredicateVisible, true).
+		Or(NewWDAPredicate().Equal(WDAPredicateName, "a"), NewWDAPredicate().Like(WDAPredicateValue, "b*"))
+	want := `type == 'XCUIElementTypeButton' AND label BEGINSWITH 'Say "hi" \\o/' AND visible == 1 AND ((name == 'a') OR (value LIKE 'b*'))`
+	if got := predicate.String(); got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+	if locator := ByLabel("OK"); locator.Predicate != `label == 'OK'` {
+		t.Fatal(locator.Predicate)
+	}
+	if using, value := ByPredicate(predicate).getUsingAndValue(); using != "predicate string" || value != want {
+		t.Fatal(using, value)
+	}
+}
+
+func TestWDAClassChain(t *testing.T) {
+	classChain := NewWDAClassChain().
+		Descendant(WDAElementType{Cell: true}, NewWDAPredicate().Equal(WDAPredicateName, "`Wi-Fi'`")).
+		Child(WDAElementType{Switch: true}).Index(1).
+		Descendant(WDAElementType{Any: true}).Index(-1)
+	want := "**/XCUIElementTypeCell[`name == '``Wi-Fi\\'``'`]/XCUIElementTypeSwitch[1]/**/*[-1]"
+	if got := classChain.String(); got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+	if using, _ := ByClassChain(classChain).getUsingAndValue(); using != "class chain" {
+		t.Fatal(using)
+	}
+}
+
+func Test_nthLocator(t *testing.T) {
+	for _, tc := range []struct {
+		locator WDALocator
+		nth     int
+		want    string
+	}{
+		{WDALocator{ClassName: WDAElementType{Cell: true}}, 3, "**/XCUIElementTypeCell[3]"},
+		{WDALocator{Predicate: "label == 'OK'"}, -1, "**/*[`label == 'OK'`][-1]"},
+		{ByName("Wi-Fi"), 2, "**/*[`name == 'Wi-Fi'`][2]"},
+		{ByClassChain(NewWDAClassChain().Descendant(WDAElementType{Cell: true})), 2, "**/XCUIElementTypeCell[2]"},
+		{WDALocator{ClassChain: "**/XCUIElementTypeCell[`visible == 1`]"}, 1, "**/XCUIElementTypeCell[`visible == 1`][1]"},
+		{WDALocator{ClassChain: "**/XCUIElementTypeCell[1]"}, 1, ""},
+		{WDALocator{ClassChain: "**/XCUIElementTypeCell[`label == 'a/b'`]"}, 2, "**/XCUIElementTypeCell[`label == 'a/b'`][2]"},
+		{WDALocator{ClassChain: "**/XCUIElementTypeCell/XCUIElementTypeStaticText"}, 3, ""},
+		{ByClassChain(NewWDAClassChain().Child(WDAElementType{Window: true})), 1, ""},
+		{ByXPath("//XCUIElementTypeCell"), 1, ""},
+	} {
+		locator, ok := nthLocator(tc.locator, tc.nth)
+		if ok != (tc.want != "") || locator.ClassChain != tc.want {
+			t.Errorf("nthLocator(%v, %d) = %q, %v", tc.locator, tc.nth, locator.ClassChain, ok)
+		}
+	}
+}
+
+func Test_predicateString(t *testing.T) {
+	if got := predicateString(`It's a \ test`); got != `'It\'s a \\ test'` {
+		t.Error(got)
+	}
+	if got := predicateString("line 1\nline 2\tend\r"); got != `'line 1\nline 2\tend\r'` {
+		t.Error(got)
+	}
+	if got := predicateStringList([]string{"a", "b'"}); got != `'a', 'b\''` {
+		t.Error(got)
+	}
+	if got := NewWDAPredicate().Equal(WDAPredicateLabel, "Sign\nin").String(); got != `label == 'Sign\nin'` {
+		t.Error(got)
+	}
+}
@@ -0,0 +1,154 @@
+package gwda
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetricsRecorder
+//
+// Observes every WDA request of a Client, `actionName` is a stable endpoint name (e.g. `FindElements`).
+// `statusCode` is `0` when no response was received. It is called concurrently.
+type MetricsRecorder interface {
+	ObserveRequest(actionName, method string, statusCode int, duration time.Duration, err error)
+}
+
+// DefaultLatencyBuckets in seconds
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// PrometheusMetrics
+//
+// a ready-made MetricsRecorder, serves the Prometheus text format without extra dependencies
+//
+//	metrics := NewPrometheusMetrics()
+//	http.Handle("/metrics", metrics)
+//	client, err := NewClientWithOption(deviceURL, NewWDAClientOption().SetMetricsRecorder(metrics))
+//
+//	gwda_requests_total{action="Tap",method="POST",code="200"} 3
+//	gwda_request_errors_total{action="FindElement",method="POST"} 1
+//	gwda_request_duration_seconds_bucket{action="Tap",le="0.1"} 2
+type PrometheusMetrics struct {
+	buckets []float64
+
+	mutex     sync.Mutex
+	requests  map[[3]string]uint64
+	errors    map[[2]string]uint64
+	latencies map[string]*latencyHistogram
+}
+
+type latencyHistogram struct {
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// NewPrometheusMetrics
+//
+// uses DefaultLatencyBuckets when `buckets` is empty
+func NewPrometheusMetrics(buckets ...float64) *PrometheusMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	return &PrometheusMetrics{
+		buckets:   sorted,
+		requests:  make(map[[3]string]uint64),
+		errors:    make(map[[2]string]uint64),
+		latencies: make(map[string]*latencyHistogram),
+	}
+}
+
+func (m *PrometheusMetrics) ObserveRequest(actionName, method string, statusCode int, duration time.Duration, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.requests[[3]string{actionName, method, strconv.Itoa(statusCode)}]++
+	if err != nil {
+		m.errors[[2]string{actionName, method}]++
+	}
+
+	h, ok := m.latencies[actionName]
+	if !ok {
+		h = &latencyHistogram{counts: make([]uint64, len(m.buckets))}
+		m.latencies[actionName] = h
+	}
+	seconds := duration.Seconds()
+	for i := range m.buckets {
+		if seconds <= m.buckets[i] {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format
+func (m *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = m.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text format
+func (m *PrometheusMetrics) WriteTo(w io.Writer) (n int64, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var sb strings.Builder
+
+	sb.WriteString("# HELP gwda_requests_total WDA requests by action, method and status code.\n")
+	sb.WriteString("# TYPE gwda_requests_total counter\n")
+	requestKeys := make([][3]string, 0, len(m.requests))
+	for k := range m.requests {
+		requestKeys = append(requestKeys, k)
+	}
+	sort.Slice(requestKeys, func(i, j int) bool {
+		return strings.Join(requestKeys[i][:], "\x00") < strings.Join(requestKeys[j][:], "\x00")
+	})
+	for _, k := range requestKeys {
+		fmt.Fprintf(&sb, "gwda_requests_total{action=%q,method=%q,code=%q} %d\n", k[0], k[1], k[2], m.requests[k])
+	}
+
+	sb.WriteString("# HELP gwda_request_errors_total WDA requests which returned an error.\n")
+	sb.WriteString("# TYPE gwda_request_errors_total counter\n")
+	errorKeys := make([][2]string, 0, len(m.errors))
+	for k := range m.errors {
+		errorKeys = append(errorKeys, k)
+	}
+	sort.Slice(errorKeys, func(i, j int) bool {
+		return errorKeys[i][0]+"\x00"+errorKeys[i][1] < errorKeys[j][0]+"\x00"+errorKeys[j][1]
+	})
+	for _, k := range errorKeys {
+		fmt.Fprintf(&sb, "gwda_request_errors_total{action=%q,method=%q} %d\n", k[0], k[1], m.errors[k])
+	}
+
+	sb.WriteString("# HELP gwda_request_duration_seconds WDA request latency.\n")
+	sb.WriteString("# TYPE gwda_request_duration_seconds histogram\n")
+	actions := make([]string, 0, len(m.latencies))
+	for k := range m.latencies {
+		actions = append(actions, k)
+	}
+	sort.Strings(actions)
+	for _, action := range actions {
+		h := m.latencies[action]
+		var cumulative uint64
+		for i, le := range m.buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(&sb, "gwda_request_duration_seconds_bucket{action=%q,le=%q} %d\n", action, strconv.FormatFloat(le, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(&sb, "gwda_request_duration_seconds_bucket{action=%q,le=\"+Inf\"} %d\n", action, h.count)
+		fmt.Fprintf(&sb, "gwda_request_duration_seconds_sum{action=%q} %s\n", action, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&sb, "gwda_request_duration_seconds_count{action=%q} %d\n", action, h.count)
+	}
+
+	var written int
+	written, err = io.WriteString(w, sb.String())
+	return int64(written), err
+}
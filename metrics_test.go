@@ -0,0 +1,34 @@
+package gwda
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrometheusMetrics_WriteTo(t *testing.T) {
+	metrics := NewPrometheusMetrics(0.1, 1)
+	metrics.ObserveRequest("Tap", "POST", 200, 50*time.Millisecond, nil)
+	metrics.ObserveRequest("Tap", "POST", 200, 500*time.Millisecond, nil)
+	metrics.ObserveRequest("FindElement", "POST", 404, 2*time.Second, errors.New("no such element"))
+
+	var sb strings.Builder
+	if _, err := metrics.WriteTo(&sb); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+	for _, line := range []string{
+		`gwda_requests_total{action="Tap",method="POST",code="200"} 2`,
+		`gwda_request_errors_total{action="FindElement",method="POST"} 1`,
+		`gwda_request_duration_seconds_bucket{action="Tap",le="0.1"} 1`,
+		`gwda_request_duration_seconds_bucket{action="Tap",le="1"} 2`,
+		`gwda_request_duration_seconds_bucket{action="FindElement",le="1"} 0`,
+		`gwda_request_duration_seconds_bucket{action="FindElement",le="+Inf"} 1`,
+		`gwda_request_duration_seconds_count{action="Tap"} 2`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("missing %q in\n%s", line, out)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package gwda
+
+import (
+	"errors"
+	"fmt"
+)
+
+// sessionApps the applications registered with RegisterApps
+type sessionApps struct {
+	bundleIds []string
+	current   string
+}
+
+// RegisterApps
+//
+// Registers the applications a test flows through (e.g. the app under test and a companion),
+// the first one registered becomes the current app.
+func (s *Session) RegisterApps(bundleIds ...string) {
+	s.appsMutex.Lock()
+	defer s.appsMutex.Unlock()
+	for _, bundleId := range bundleIds {
+		if bundleId == "" || containsString(s.apps.bundleIds, bundleId) {
+			continue
+		}
+		s.apps.bundleIds = append(s.apps.bundleIds, bundleId)
+		if s.apps.current == "" {
+			s.apps.current = bundleId
+		}
+	}
+}
+
+// Apps registered with RegisterApps
+func (s *Session) Apps() []string {
+	s.appsMutex.Lock()
+	defer s.appsMutex.Unlock()
+	return append([]string(nil), s.apps.bundleIds...)
+}
+
+// CurrentApp
+//
+// the registered application the session works on, `""` when none is registered
+func (s *Session) CurrentApp() string {
+	s.appsMutex.Lock()
+	defer s.appsMutex.Unlock()
+	return s.apps.current
+}
+
+// SwitchApp
+//
+// Brings a registered application to the foreground (launching it if needed) and makes it the current app,
+// the queries of the session then target it. The session is kept.
+func (s *Session) SwitchApp(bundleId string) (err error) {
+	s.appsMutex.Lock()
+	registered := containsString(s.apps.bundleIds, bundleId)
+	s.appsMutex.Unlock()
+	if !registered {
+		return fmt.Errorf("app '%s' is not registered, see RegisterApps", bundleId)
+	}
+
+	var state WDAAppRunState
+	if state, err = s.AppState(bundleId); err != nil {
+		return err
+	}
+	if state == WDAAppRunningBack || state == WDAAppRunningFront {
+		err = s.AppActivate(bundleId)
+	} else {
+		err = s.AppLaunch(bundleId)
+	}
+	if err != nil {
+		return fmt.Errorf("switch to app '%s': %w", bundleId, err)
+	}
+
+	s.appsMutex.Lock()
+	s.apps.current = bundleId
+	s.appsMutex.Unlock()
+	return
+}
+
+// AppStates of the registered applications
+func (s *Session) AppStates() (states map[string]WDAAppRunState, err error) {
+	bundleIds := s.Apps()
+	if len(bundleIds) == 0 {
+		return nil, errors.New("no app registered, see RegisterApps")
+	}
+	states = make(map[string]WDAAppRunState, len(bundleIds))
+	for _, bundleId := range bundleIds {
+		if states[bundleId], err = s.AppState(bundleId); err != nil {
+			return nil, fmt.Errorf("state of app '%s': %w", bundleId, err)
+		}
+	}
+	return
+}
+
+func containsString(list []string, s string) bool {
+	for i := range list {
+		if list[i] == s {
+			return true
+		}
+	}
+	return false
+}
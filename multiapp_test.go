@@ -0,0 +1,36 @@
+package gwda
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSession_RegisterApps(t *testing.T) {
+	s := new(Session)
+	s.RegisterApps("com.example.app", "", "com.example.companion", "com.example.app")
+	if got := s.Apps(); !reflect.DeepEqual(got, []string{"com.example.app", "com.example.companion"}) {
+		t.Fatalf("apps: %q", got)
+	}
+	if s.CurrentApp() != "com.example.app" {
+		t.Fatalf("current app: %s", s.CurrentApp())
+	}
+	if err := s.SwitchApp("com.example.unknown"); err == nil {
+		t.Fatal("expected an error for an app which is not registered")
+	}
+}
+
+func TestSession_SwitchApp(t *testing.T) {
+	client, err := NewClient(deviceURL)
+	checkErr(t, err)
+	session, err := client.NewSession()
+	checkErr(t, err)
+
+	session.RegisterApps("com.apple.Preferences", "com.apple.mobilesafari")
+	checkErr(t, session.SwitchApp("com.apple.mobilesafari"))
+	checkErr(t, session.SwitchApp("com.apple.Preferences"))
+	states, err := session.AppStates()
+	checkErr(t, err)
+	if states["com.apple.Preferences"] != WDAAppRunningFront || states["com.apple.mobilesafari"] != WDAAppRunningBack {
+		t.Fatalf("unexpected states: %v", states)
+	}
+}
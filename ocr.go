@@ -0,0 +1,225 @@
+package gwda
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OCREngine
+//
+// recognizes the text of `img`, one WDAOCRText per line (or per word when the engine does not group them),
+// the rectangles are in the pixels of `img`.
+//
+// e.g. an implementation calling the Vision framework on a macOS sidecar can replace DefaultOCREngine
+type OCREngine interface {
+	Recognize(img image.Image) ([]WDAOCRText, error)
+}
+
+// DefaultOCREngine used by FindTextOnScreen
+var DefaultOCREngine OCREngine = Tesseract{}
+
+// ErrTextNotPresent the text was not recognized on the screen
+var ErrTextNotPresent = errors.New("text not present")
+
+// WDAOCRText
+type WDAOCRText struct {
+	Text       string
+	PixelRect  image.Rectangle
+	Confidence float64 // in range [0.0, 1.0]
+	// the words of a line, in reading order, to narrow the rectangle down to a part of the line. Optional
+	Words []WDAOCRText
+}
+
+// Tesseract
+//
+// runs `tesseract`, which must be in the PATH unless `Path` is set.
+// `Language` is passed with `-l`, e.g. `eng+chi_sim`, Tesseract uses `eng` when empty.
+type Tesseract struct {
+	Path     string
+	Language string
+}
+
+func (t Tesseract) Recognize(img image.Image) (texts []WDAOCRText, err error) {
+	name := t.Path
+	if name == "" {
+		name = "tesseract"
+	}
+	args := []string{"stdin", "stdout"}
+	if t.Language != "" {
+		args = append(args, "-l", t.Language)
+	}
+	args = append(args, "tsv")
+
+	buf := new(bytes.Buffer)
+	if err = png.Encode(buf, img); err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = buf
+	stderr := new(bytes.Buffer)
+	cmd.Stderr = stderr
+	var output []byte
+	if output, err = cmd.Output(); err != nil {
+		return nil, fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return parseTesseractTSV(string(output)), nil
+}
+
+// parseTesseractTSV groups the words (level 5) by line
+//
+//	level	page_num	block_num	par_num	line_num	word_num	left	top	width	height	conf	text
+func parseTesseractTSV(tsv string) (texts []WDAOCRText) {
+	type lineKey struct{ block, par, line string }
+	var order []lineKey
+	words := make(map[lineKey][]WDAOCRText)
+	for _, row := range strings.Split(tsv, "\n") {
+		fields := strings.Split(strings.TrimRight(row, "\r"), "\t")
+		if len(fields) < 12 || fields[0] != "5" {
+			continue
+		}
+		text := strings.TrimSpace(fields[11])
+		if text == "" {
+			continue
+		}
+		var v [5]float64
+		valid := true
+		for i := range v {
+			var err error
+			if v[i], err = strconv.ParseFloat(fields[6+i], 64); err != nil {
+				valid = false
+				break
+			}
+		}
+		if !valid {
+			continue
+		}
+		key := lineKey{fields[2], fields[3], fields[4]}
+		if _, ok := words[key]; !ok {
+			order = append(order, key)
+		}
+		x, y := int(v[0]), int(v[1])
+		words[key] = append(words[key], WDAOCRText{
+			Text:       text,
+			PixelRect:  image.Rect(x, y, x+int(v[2]), y+int(v[3])),
+			Confidence: v[4] / 100,
+		})
+	}
+
+	for _, key := range order {
+		lineWords := words[key]
+		line := WDAOCRText{Words: lineWords}
+		parts := make([]string, len(lineWords))
+		for i, w := range lineWords {
+			parts[i] = w.Text
+			line.PixelRect = line.PixelRect.Union(w.PixelRect)
+			line.Confidence += w.Confidence / float64(len(lineWords))
+		}
+		line.Text = strings.Join(parts, " ")
+		texts = append(texts, line)
+	}
+	return
+}
+
+// SetOCREngine
+//
+// replaces DefaultOCREngine for this session, `nil` restores it
+func (s *Session) SetOCREngine(engine OCREngine) {
+	s.ocrEngine.Store(ocrEngineValue{engine})
+}
+
+// ocrEngineValue see imageMatcherValue
+type ocrEngineValue struct {
+	OCREngine
+}
+
+func (s *Session) getOCREngine() OCREngine {
+	if v, _ := s.ocrEngine.Load().(ocrEngineValue); v.OCREngine != nil {
+		return v.OCREngine
+	}
+	return DefaultOCREngine
+}
+
+// FindTextOnScreen
+//
+// Takes a screenshot and recognizes its text with the OCREngine of the session (DefaultOCREngine unless SetOCREngine),
+// for the text drawn by the app which is not in the element tree (games, canvases, images).
+//
+// Returns the areas containing `text` (case-sensitive, narrowed down to the matching words when the engine reports them),
+// the most confident first. When there is none, the error wraps ErrTextNotPresent.
+//
+//	found, err := s.FindTextOnScreen("Start Game")
+//	err = found[0].Tap()
+func (s *Session) FindTextOnScreen(text string) (elements []*WDAImageElement, err error) {
+	var screen image.Image
+	if screen, _, err = s.ScreenshotToImage(); err != nil {
+		return nil, err
+	}
+	var scale float64
+	if scale, err = s.Scale(); err != nil {
+		return nil, err
+	}
+	var texts []WDAOCRText
+	if texts, err = s.getOCREngine().Recognize(screen); err != nil {
+		return nil, err
+	}
+	matches := findOCRText(texts, text)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrTextNotPresent, text)
+	}
+	for _, m := range matches {
+		m.Rect = RectFromPixels(m.PixelRect, scale)
+		elements = append(elements, &WDAImageElement{session: s, match: m})
+	}
+	return
+}
+
+// findOCRText the pixel rectangles of the recognized texts containing `text`, the most confident first
+func findOCRText(texts []WDAOCRText, text string) (matches []WDAImageMatch) {
+	if text == "" {
+		return nil
+	}
+	for _, t := range texts {
+		idx := strings.Index(t.Text, text)
+		if idx == -1 {
+			continue
+		}
+		match := WDAImageMatch{PixelRect: t.PixelRect, Confidence: t.Confidence}
+		if len(t.Words) != 0 && strings.Join(wordTexts(t.Words), " ") == t.Text {
+			// the words overlapping the matched part of the line
+			var rect image.Rectangle
+			var confidence float64
+			var n int
+			offset := 0
+			for _, w := range t.Words {
+				end := offset + len(w.Text)
+				if end > idx && offset < idx+len(text) {
+					rect = rect.Union(w.PixelRect)
+					confidence += w.Confidence
+					n++
+				}
+				offset = end + 1
+			}
+			match.PixelRect, match.Confidence = rect, confidence/float64(n)
+		}
+		matches = append(matches, match)
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Confidence > matches[j].Confidence
+	})
+	return
+}
+
+func wordTexts(words []WDAOCRText) []string {
+	texts := make([]string, len(words))
+	for i, w := range words {
+		texts[i] = w.Text
+	}
+	return texts
+}
@@ -0,0 +1,45 @@
+package gwda
+
+import (
+	"image"
+	"testing"
+)
+
+func Test_parseTesseractTSV(t *testing.T) {
+	tsv := "level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n" +
+		"4\t1\t1\t1\t1\t0\t10\t20\t200\t30\t-1\t\n" +
+		"5\t1\t1\t1\t1\t1\t10\t20\t80\t30\t96\tStart\n" +
+		"5\t1\t1\t1\t1\t2\t100\t20\t110\t30\t90\tGame\n" +
+		"5\t1\t2\t1\t1\t1\t10\t100\t60\t30\t80\tQuit\n"
+	texts := parseTesseractTSV(tsv)
+	if len(texts) != 2 || texts[0].Text != "Start Game" || texts[1].Text != "Quit" {
+		t.Fatal(texts)
+	}
+	if texts[0].PixelRect != image.Rect(10, 20, 210, 50) || len(texts[0].Words) != 2 {
+		t.Fatal(texts[0])
+	}
+
+	matches := findOCRText(texts, "Game")
+	if len(matches) != 1 || matches[0].PixelRect != image.Rect(100, 20, 210, 50) || matches[0].Confidence != 0.9 {
+		t.Fatal(matches)
+	}
+	matches = findOCRText(texts, "rt Ga")
+	if len(matches) != 1 || matches[0].PixelRect != image.Rect(10, 20, 210, 50) {
+		t.Fatal(matches)
+	}
+	if matches = findOCRText(texts, "Settings"); len(matches) != 0 {
+		t.Fatal(matches)
+	}
+}
+
+func TestSession_FindTextOnScreen(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+
+	found, err := s.FindTextOnScreen("Settings")
+	checkErr(t, err)
+	t.Log(found[0].Confidence(), found[0].Rect())
+	checkErr(t, found[0].Tap())
+}
@@ -0,0 +1,99 @@
+package gwda
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WDAOrigin
+//
+// where a result or an error comes from, to attribute the logs of parallel runs on several devices
+type WDAOrigin struct {
+	Device    string // host and port of the device URL, or the UDID when connected through usbmuxd
+	UDID      string // only known when connected through usbmuxd or set with WDAClientOption.SetUDID
+	SessionID string // empty for the requests without session
+}
+
+func (o WDAOrigin) String() string {
+	if o.SessionID == "" {
+		return "device " + o.Device
+	}
+	return "device " + o.Device + ", session " + o.SessionID
+}
+
+// Origin of the requests of the Client
+func (c *Client) Origin() WDAOrigin {
+	return WDAOrigin{Device: deviceName(c), UDID: c.UDID()}
+}
+
+// Origin of the requests of the Session
+func (s *Session) Origin() (origin WDAOrigin) {
+	if s.client != nil {
+		origin = s.client.Origin()
+	} else {
+		origin.Device = s.sessionURL().Host
+	}
+	origin.SessionID = s.ID()
+	return
+}
+
+// WDAOriginError
+//
+// Every failed request is wrapped in it, the message ends with the origin,
+// e.g. `no such element: ... (device 192.168.1.2_8100, session 4A1B...)`.
+type WDAOriginError struct {
+	Origin WDAOrigin
+	Err    error
+}
+
+func (e *WDAOriginError) Error() string {
+	return fmt.Sprintf("%s (%s)", e.Err, e.Origin)
+}
+
+func (e *WDAOriginError) Unwrap() error {
+	return e.Err
+}
+
+// OriginOf returns the origin of an error of a request
+func OriginOf(err error) (origin WDAOrigin, ok bool) {
+	var errOrigin *WDAOriginError
+	if errors.As(err, &errOrigin) {
+		return errOrigin.Origin, true
+	}
+	return WDAOrigin{}, false
+}
+
+// withOrigin wraps the error of `call` once
+func (call *wdaCall) withOrigin(err error) error {
+	if err == nil || call == nil || call.client == nil {
+		return err
+	}
+	var errOrigin *WDAOriginError
+	if errors.As(err, &errOrigin) {
+		return err
+	}
+	if call.session != nil {
+		return &WDAOriginError{Origin: call.session.Origin(), Err: err}
+	}
+	return &WDAOriginError{Origin: call.client.Origin(), Err: err}
+}
+
+// WDAScreenshot
+type WDAScreenshot struct {
+	Origin WDAOrigin
+	Time   time.Time // when it was received
+	Raw    *bytes.Buffer
+}
+
+// ScreenshotWithOrigin
+//
+// Screenshot along with its origin and time
+func (s *Session) ScreenshotWithOrigin(element ...*Element) (shot WDAScreenshot, err error) {
+	if shot.Raw, err = s.Screenshot(element...); err != nil {
+		return WDAScreenshot{}, err
+	}
+	shot.Origin, shot.Time = s.Origin(), time.Now()
+	return
+}
@@ -0,0 +1,37 @@
+package gwda
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestWDAOriginError(t *testing.T) {
+	c := &Client{deviceURL: &url.URL{Scheme: "http", Host: "192.168.1.2:8100"}}
+	call := &wdaCall{client: c}
+
+	errNotFound := errors.New("no such element")
+	err := call.withOrigin(errNotFound)
+	if !errors.Is(err, errNotFound) {
+		t.Fatalf("expected %v to wrap %v", err, errNotFound)
+	}
+	if want := "no such element (device 192.168.1.2_8100)"; err.Error() != want {
+		t.Fatalf("got %q, want %q", err, want)
+	}
+	if call.withOrigin(err) != err {
+		t.Fatal("wrapped twice")
+	}
+
+	origin, ok := OriginOf(err)
+	if !ok || origin.Device != "192.168.1.2_8100" || origin.SessionID != "" {
+		t.Fatalf("unexpected origin: %+v", origin)
+	}
+	if _, ok = OriginOf(errNotFound); ok {
+		t.Fatal("an error without origin")
+	}
+
+	var nilCall *wdaCall
+	if nilCall.withOrigin(errNotFound) != errNotFound {
+		t.Fatal("a request which was not sent has no origin")
+	}
+}
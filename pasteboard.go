@@ -0,0 +1,88 @@
+package gwda
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// DefaultPasteboardCompanion the app of WebDriverAgent, its bundle id depends on how WDA was signed
+var DefaultPasteboardCompanion = "com.facebook.WebDriverAgentRunner.xctrunner"
+
+// SetPasteboardCompanion
+//
+// Since iOS 13 only the app in the foreground may read the pasteboard. When set, GetPasteboard brings the
+// companion (e.g. DefaultPasteboardCompanion, or a helper app of yours) to the foreground,
+// reads the pasteboard and activates the previous app again. With `""` the pasteboard is read as is, see SetPasteboardRetryEmpty.
+//
+// Default is `""`
+func (s *Session) SetPasteboardCompanion(bundleId string) {
+	s.pasteboardCompanion.Store(bundleId)
+}
+
+// SetPasteboardRetryEmpty
+//
+// Without companion, an empty read is taken for a pasteboard hidden from the background and read once more
+// with DefaultPasteboardCompanion in the foreground, switching apps. A pasteboard which is really empty
+// (e.g. after ClearPasteboard) switches apps too, so it is off unless asked for.
+//
+// Default is `false`
+func (s *Session) SetPasteboardRetryEmpty(b bool) {
+	var v int32
+	if b {
+		v = 1
+	}
+	atomic.StoreInt32(&s.pasteboardRetryEmpty, v)
+}
+
+func (s *Session) getPasteboardCompanion() string {
+	bundleId, _ := s.pasteboardCompanion.Load().(string)
+	return bundleId
+}
+
+// withPasteboardCompanion runs `fn` with the companion in the foreground, if there is one
+func (s *Session) withPasteboardCompanion(companion string, fn func() error) (err error) {
+	if companion == "" {
+		return fn()
+	}
+
+	var previous WDAActiveAppInfo
+	if previous, err = s.ActiveAppInfo(); err != nil {
+		return fmt.Errorf("pasteboard companion: %w", err)
+	}
+	if previous.BundleID == companion {
+		return fn()
+	}
+	if err = s.AppActivate(companion); err != nil {
+		return fmt.Errorf("pasteboard companion '%s' could not be brought to the foreground (is it installed?): %w", companion, err)
+	}
+	err = fn()
+
+	// SpringBoard can't be activated, the home screen is where the companion leaves it anyway
+	if previous.BundleID == "" || previous.BundleID == "com.apple.springboard" {
+		if errHome := s.PressHomeButton(); errHome != nil && err == nil {
+			err = fmt.Errorf("pasteboard companion: failed to leave '%s' %w", companion, errHome)
+		}
+		return
+	}
+	if errBack := s.AppActivate(previous.BundleID); errBack != nil && err == nil {
+		err = fmt.Errorf("pasteboard companion: failed to activate '%s' again %w", previous.BundleID, errBack)
+	}
+	return
+}
+
+// ClearPasteboard
+//
+// Empties the general pasteboard by setting empty content for each WDAContentType. Setting the plaintext already
+// replaces all the items, so only its failure is reported, WDA may refuse an empty url or image.
+func (s *Session) ClearPasteboard() (err error) {
+	if err = s.SetPasteboard(WDAContentTypePlaintext, ""); err != nil {
+		return fmt.Errorf("clear pasteboard: %w", err)
+	}
+	for _, contentType := range []WDAContentType{WDAContentTypeUrl, WDAContentTypeImage} {
+		if errType := s.SetPasteboard(contentType, ""); errType != nil {
+			debugLog(fmt.Sprintf("clear pasteboard (%s): %s", contentType, errType))
+		}
+	}
+	// the last type set is the one left on the pasteboard
+	return s.SetPasteboard(WDAContentTypePlaintext, "")
+}
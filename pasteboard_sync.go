@@ -0,0 +1,208 @@
+package gwda
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HostClipboard the clipboard of the host, see SystemClipboard
+type HostClipboard interface {
+	ReadText() (string, error)
+	WriteText(text string) error
+}
+
+// SystemClipboard
+//
+// Runs the clipboard tool of the host: `pbcopy`/`pbpaste` on macOS, `wl-copy`/`wl-paste` on Wayland,
+// `xclip` on X11, PowerShell on Windows.
+type SystemClipboard struct{}
+
+func (SystemClipboard) ReadText() (string, error) {
+	read, _, err := clipboardCommands(runtime.GOOS, os.Getenv("WAYLAND_DISPLAY") != "")
+	if err != nil {
+		return "", err
+	}
+	output, err := exec.Command(read[0], read[1:]...).Output()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", strings.Join(read, " "), err)
+	}
+	text := string(output)
+	if runtime.GOOS == "windows" {
+		// Get-Clipboard appends a line break
+		text = strings.TrimSuffix(text, "\r\n")
+	}
+	return text, nil
+}
+
+func (SystemClipboard) WriteText(text string) error {
+	_, write, err := clipboardCommands(runtime.GOOS, os.Getenv("WAYLAND_DISPLAY") != "")
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(write[0], write[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w: %s", strings.Join(write, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func clipboardCommands(goos string, wayland bool) (read, write []string, err error) {
+	switch {
+	case goos == "darwin":
+		return []string{"pbpaste"}, []string{"pbcopy"}, nil
+	case goos == "windows":
+		return []string{"powershell", "-NoProfile", "-Command", "Get-Clipboard -Raw"},
+			[]string{"powershell", "-NoProfile", "-Command", "$input | Set-Clipboard"}, nil
+	case wayland:
+		return []string{"wl-paste", "--no-newline"}, []string{"wl-copy"}, nil
+	case goos == "linux", goos == "freebsd", goos == "openbsd", goos == "netbsd":
+		return []string{"xclip", "-selection", "clipboard", "-o"}, []string{"xclip", "-selection", "clipboard", "-i"}, nil
+	}
+	return nil, nil, fmt.Errorf("no clipboard support on %s", goos)
+}
+
+// PasteboardSync
+//
+// Mirrors the plaintext of the host clipboard to the pasteboard of the device, and back when bidirectional,
+// on demand (SyncToDevice, SyncFromDevice) or every interval once started.
+// With a companion (see SetPasteboardCompanion, SetPasteboardRetryEmpty) reading the pasteboard of a real device
+// brings it to the foreground, the polling back should then only be enabled while nobody interacts with the device.
+// The settings can also be changed while it runs.
+//
+//	ps := s.NewPasteboardSync().SetInterval(time.Second)
+//	ps.Start()
+//	defer ps.Stop()
+type PasteboardSync struct {
+	session       *Session
+	host          HostClipboard
+	bidirectional bool
+	poller        *poller
+
+	mutex      sync.Mutex
+	lastHost   string // last text seen on, or written to, the host
+	lastDevice string // same for the device
+	primed     bool   // synchronized once
+}
+
+// NewPasteboardSync
+//
+// with SystemClipboard, polls the host every 2 seconds
+func (s *Session) NewPasteboardSync() *PasteboardSync {
+	return &PasteboardSync{
+		session: s,
+		host:    SystemClipboard{},
+		poller:  newPoller(2*time.Second, nil),
+	}
+}
+
+func (ps *PasteboardSync) SetHostClipboard(host HostClipboard) *PasteboardSync {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	ps.host = host
+	return ps
+}
+
+func (ps *PasteboardSync) SetInterval(d time.Duration) *PasteboardSync {
+	ps.poller.setInterval(d)
+	return ps
+}
+
+// SetBidirectional
+//
+// Also copies the device pasteboard to the host when it changes.
+//
+// Default is `false`
+func (ps *PasteboardSync) SetBidirectional(b bool) *PasteboardSync {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	ps.bidirectional = b
+	return ps
+}
+
+// OnError
+//
+// called from the polling goroutine with every failed poll, they are only logged without it
+func (ps *PasteboardSync) OnError(fn func(err error)) *PasteboardSync {
+	ps.poller.setOnError(fn)
+	return ps
+}
+
+// SyncToDevice copies the host clipboard to the device
+func (ps *PasteboardSync) SyncToDevice() (err error) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	var text string
+	if text, err = ps.host.ReadText(); err != nil {
+		return fmt.Errorf("pasteboard sync: %w", err)
+	}
+	return ps.toDevice(text)
+}
+
+// SyncFromDevice copies the device pasteboard to the host
+func (ps *PasteboardSync) SyncFromDevice() (err error) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	var text string
+	if text, err = ps.session.GetPasteboardForPlaintext(); err != nil {
+		return fmt.Errorf("pasteboard sync: %w", err)
+	}
+	return ps.toHost(text)
+}
+
+func (ps *PasteboardSync) toDevice(text string) (err error) {
+	if err = ps.session.SetPasteboardForPlaintext(text); err != nil {
+		return fmt.Errorf("pasteboard sync: %w", err)
+	}
+	ps.lastHost, ps.lastDevice, ps.primed = text, text, true
+	return
+}
+
+func (ps *PasteboardSync) toHost(text string) (err error) {
+	if err = ps.host.WriteText(text); err != nil {
+		return fmt.Errorf("pasteboard sync: %w", err)
+	}
+	ps.lastHost, ps.lastDevice, ps.primed = text, text, true
+	return
+}
+
+// poll copies the side which changed since the last poll, the host wins when both did
+func (ps *PasteboardSync) poll() (err error) {
+	if ps.session.isSuspended() {
+		return nil
+	}
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	var hostText string
+	if hostText, err = ps.host.ReadText(); err != nil {
+		return fmt.Errorf("pasteboard sync: %w", err)
+	}
+	if !ps.primed || hostText != ps.lastHost {
+		return ps.toDevice(hostText)
+	}
+	if !ps.bidirectional {
+		return nil
+	}
+	var deviceText string
+	if deviceText, err = ps.session.GetPasteboardForPlaintext(); err != nil {
+		return fmt.Errorf("pasteboard sync: %w", err)
+	}
+	if deviceText != ps.lastDevice {
+		return ps.toHost(deviceText)
+	}
+	return nil
+}
+
+func (ps *PasteboardSync) Start() {
+	ps.poller.start(ps.poll, true)
+}
+
+// Stop waits for the running poll to finish
+func (ps *PasteboardSync) Stop() {
+	ps.poller.stop()
+}
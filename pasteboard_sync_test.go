@@ -0,0 +1,61 @@
+package gwda
+
+import "testing"
+
+type memoryClipboard struct {
+	text string
+}
+
+func (mc *memoryClipboard) ReadText() (string, error) {
+	return mc.text, nil
+}
+
+func (mc *memoryClipboard) WriteText(text string) error {
+	mc.text = text
+	return nil
+}
+
+func Test_clipboardCommands(t *testing.T) {
+	for _, tc := range []struct {
+		goos    string
+		wayland bool
+		read    string
+	}{
+		{"darwin", false, "pbpaste"},
+		{"linux", false, "xclip"},
+		{"linux", true, "wl-paste"},
+		{"windows", false, "powershell"},
+	} {
+		read, write, err := clipboardCommands(tc.goos, tc.wayland)
+		checkErr(t, err)
+		if read[0] != tc.read || len(write) == 0 {
+			t.Errorf("%s: unexpected commands %q %q", tc.goos, read, write)
+		}
+	}
+	if _, _, err := clipboardCommands("plan9", false); err == nil {
+		t.Error("expected an error for plan9")
+	}
+}
+
+func TestPasteboardSync(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	s.SetPasteboardCompanion(DefaultPasteboardCompanion)
+
+	host := &memoryClipboard{text: "from host"}
+	ps := s.NewPasteboardSync().SetHostClipboard(host).SetBidirectional(true)
+	checkErr(t, ps.SyncToDevice())
+	content, err := s.GetPasteboardForPlaintext()
+	checkErr(t, err)
+	if content != "from host" {
+		t.Fatal(content)
+	}
+
+	checkErr(t, s.SetPasteboardForPlaintext("from device"))
+	checkErr(t, ps.poll())
+	if host.text != "from device" {
+		t.Fatal(host.text)
+	}
+}
@@ -0,0 +1,62 @@
+package gwda
+
+import "testing"
+
+func TestSession_SetPasteboardCompanion(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	checkErr(t, s.AppLaunch(bundleId))
+
+	checkErr(t, s.SetPasteboardForPlaintext("gwda"))
+	s.SetPasteboardCompanion(DefaultPasteboardCompanion)
+	content, err := s.GetPasteboardForPlaintext()
+	checkErr(t, err)
+	if content != "gwda" {
+		t.Error(content)
+	}
+	appInfo, err := s.ActiveAppInfo()
+	checkErr(t, err)
+	if appInfo.BundleID != bundleId {
+		t.Error("the previous app is not in the foreground again:", appInfo.BundleID)
+	}
+}
+
+func TestSession_ClearPasteboard(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+
+	checkErr(t, s.SetPasteboardForPlaintext("gwda"))
+	checkErr(t, s.ClearPasteboard())
+	s.SetPasteboardCompanion(DefaultPasteboardCompanion)
+	content, err := s.GetPasteboardForPlaintext()
+	checkErr(t, err)
+	if content != "" {
+		t.Error(content)
+	}
+}
+
+func TestSession_SetPasteboardRetryEmpty(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	checkErr(t, s.AppLaunch(bundleId))
+
+	checkErr(t, s.ClearPasteboard())
+	// by default an empty read stays in the app
+	content, err := s.GetPasteboardForPlaintext()
+	checkErr(t, err)
+	appInfo, err := s.ActiveAppInfo()
+	checkErr(t, err)
+	if content != "" || appInfo.BundleID != bundleId {
+		t.Error(content, appInfo.BundleID)
+	}
+
+	s.SetPasteboardRetryEmpty(true)
+	_, err = s.GetPasteboardForPlaintext()
+	checkErr(t, err)
+}
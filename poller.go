@@ -0,0 +1,110 @@
+package gwda
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// poller
+//
+// Runs `poll` every interval in its own goroutine between start and stop, for ScreenInterruptionWatcher,
+// LivenessWatchdog and PasteboardSync. Their settings are guarded by `mutex`, so they can be changed while it runs.
+type poller struct {
+	mutex    sync.Mutex
+	interval time.Duration
+	onError  func(err error)
+	events   interface{} // buffered channel of the published events, closed by stop; nil without
+
+	stopCh    chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+	done      chan struct{}
+}
+
+func newPoller(interval time.Duration, events interface{}) *poller {
+	return &poller{
+		interval: interval,
+		events:   events,
+		stopCh:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// locked runs `fn` holding the mutex of the settings
+func (p *poller) locked(fn func()) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	fn()
+}
+
+func (p *poller) setInterval(d time.Duration) {
+	p.locked(func() { p.interval = d })
+}
+
+func (p *poller) setOnError(fn func(err error)) {
+	p.locked(func() { p.onError = fn })
+}
+
+// start polls first after an interval, or at once when `immediately`
+func (p *poller) start(poll func() error, immediately bool) {
+	p.startOnce.Do(func() {
+		go p.run(poll, immediately)
+	})
+}
+
+// stop waits for the running poll to finish
+func (p *poller) stop() {
+	p.stopOnce.Do(func() {
+		close(p.stopCh)
+		started := true
+		p.startOnce.Do(func() { started = false })
+		if started {
+			<-p.done
+		}
+		if p.events != nil {
+			reflect.ValueOf(p.events).Close()
+		}
+	})
+}
+
+func (p *poller) run(poll func() error, immediately bool) {
+	defer close(p.done)
+	for {
+		if immediately {
+			if err := poll(); err != nil {
+				p.report(err)
+			}
+		}
+		immediately = true
+
+		var interval time.Duration
+		p.locked(func() { interval = p.interval })
+		timer := time.NewTimer(interval)
+		select {
+		case <-p.stopCh:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// report gives `err` to the error callback, it is only logged without one
+func (p *poller) report(err error) {
+	var onError func(err error)
+	p.locked(func() { onError = p.onError })
+	if onError == nil {
+		debugLog(err.Error())
+		return
+	}
+	onError(err)
+}
+
+// publish sends `event` to the events, the oldest ones are dropped when nobody reads
+func (p *poller) publish(event interface{}) {
+	events, value := reflect.ValueOf(p.events), reflect.ValueOf(event)
+	for !events.TrySend(value) {
+		events.TryRecv()
+	}
+}
@@ -0,0 +1,41 @@
+package gwda
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_poller(t *testing.T) {
+	events := make(chan int, 2)
+	p := newPoller(time.Millisecond, events)
+	errs := make(chan error, 1)
+	p.setOnError(func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+
+	polls := 0
+	p.start(func() error {
+		polls++
+		p.publish(polls)
+		if polls == 3 {
+			return errors.New("poll 3")
+		}
+		return nil
+	}, true)
+	if err := <-errs; err.Error() != "poll 3" {
+		t.Fatal(err)
+	}
+	p.stop()
+
+	var got []int
+	for event := range events {
+		got = append(got, event)
+	}
+	if len(got) != 2 || got[1] != polls {
+		t.Fatalf("the oldest events should be dropped, got %v after %d polls", got, polls)
+	}
+}
@@ -0,0 +1,49 @@
+package gwda
+
+import "github.com/tidwall/gjson"
+
+// WDAProtocol
+//
+// The wire protocol spoken to WDA, which only changes what is sent (e.g. how NewSession wraps the capabilities).
+// Responses of both protocols are always understood: element keys, `sessionId` location and error formats.
+type WDAProtocol string
+
+const (
+	// WDAProtocolW3C `{"capabilities": {"alwaysMatch": {...}}}`
+	WDAProtocolW3C WDAProtocol = "W3C"
+	// WDAProtocolMJSONWP `{"desiredCapabilities": {...}}`, for WDA builds before the W3C migration
+	WDAProtocolMJSONWP WDAProtocol = "MJSONWP"
+)
+
+// WDAElementKey W3C identifier of an element reference
+const WDAElementKey = "element-6066-11e4-a52e-4f735466cecf"
+
+// elementUIDOf `{"ELEMENT": "..."}` or `{"element-6066-11e4-a52e-4f735466cecf": "..."}`
+func elementUIDOf(v gjson.Result) string {
+	if uid := v.Get("ELEMENT").String(); uid != "" {
+		return uid
+	}
+	return v.Get(WDAElementKey).String()
+}
+
+func (c *Client) newSessionBody(capabilities ...WDASessionCapability) wdaBody {
+	caps := newWdaBody()
+	if len(capabilities) != 0 {
+		caps = wdaBody(capabilities[0])
+	}
+	if c.protocol == WDAProtocolMJSONWP {
+		return newWdaBody().set("desiredCapabilities", caps)
+	}
+	if len(capabilities) == 0 {
+		return newWdaBody().set("capabilities", newWdaBody())
+	}
+	return newWdaBody().set("capabilities", newWdaBody().set("alwaysMatch", caps))
+}
+
+// getSessionID MJSONWP at the top level, W3C in `value`
+func (wdaResp wdaResponse) getSessionID() string {
+	if sid := wdaResp.getByPath("sessionId").String(); sid != "" {
+		return sid
+	}
+	return wdaResp.getByPath("value.sessionId").String()
+}
@@ -0,0 +1,39 @@
+package gwda
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWdaResponse_protocols(t *testing.T) {
+	w3c := wdaResponse(`{"value":{"sessionId":"A1","capabilities":{}}}`)
+	if sid := w3c.getSessionID(); sid != "A1" {
+		t.Errorf("W3C sessionId %q", sid)
+	}
+	mjsonwp := wdaResponse(`{"value":{},"sessionId":"B2","status":0}`)
+	if sid := mjsonwp.getSessionID(); sid != "B2" {
+		t.Errorf("MJSONWP sessionId %q", sid)
+	}
+	if err := mjsonwp.getErrMsg(); err != nil {
+		t.Error(err)
+	}
+
+	for _, resp := range []string{
+		`{"value":{"ELEMENT":"E1"}}`,
+		`{"value":{"element-6066-11e4-a52e-4f735466cecf":"E1"}}`,
+	} {
+		if uid := elementUIDOf(wdaResponse(resp).getValue()); uid != "E1" {
+			t.Errorf("%s: element %q", resp, uid)
+		}
+	}
+
+	errResp := wdaResponse(`{"status":7,"value":"An element could not be located"}`)
+	if err := errResp.getErrMsg(); err == nil || err.Error() != "status 7: An element could not be located" {
+		t.Errorf("MJSONWP error %v", err)
+	} else if !errors.Is(err, ErrNoSuchElement) {
+		t.Errorf("MJSONWP error %v is not ErrNoSuchElement", err)
+	}
+	if !wdaResponse(`{"status":6,"value":{"message":"Session does not exist"}}`).isInvalidSession() {
+		t.Error("MJSONWP invalid session")
+	}
+}
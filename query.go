@@ -0,0 +1,423 @@
+package gwda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// Query
+//
+// describes which elements to look for, the search is scoped to the Session or Element it was created from
+type Query struct {
+	client   *Client
+	endpoint *url.URL // session URL, owner of every found element
+	baseUrl  *url.URL // where `/elements` is posted
+	locator  WDALocator
+	segments []querySegment // of the chained calls (Type, Label, Descendant, ...), compiled to a class chain
+
+	attributes []string
+}
+
+type querySegment struct {
+	child     bool // instead of a descendant of the previous segment
+	class     string
+	predicate *WDAPredicate
+	index     int
+}
+
+func newQuery(c *Client, endpoint, baseUrl *url.URL) *Query {
+	return &Query{client: c, endpoint: endpoint, baseUrl: baseUrl}
+}
+
+// Query
+//
+// starts a query against the whole application
+func (s *Session) Query() *Query {
+	return newQuery(s.client, s.sessionURL(), s.sessionURL())
+}
+
+// Query
+//
+// starts a query against the descendants of this element
+func (e *Element) Query() *Query {
+	return newQuery(e.client, e.endpoint, e._withFormatToUrl())
+}
+
+// By
+//
+// uses the given locator as is, instead of the chained calls
+func (q *Query) By(wdaLocator WDALocator) *Query {
+	q.locator = wdaLocator
+	q.segments = nil
+	return q
+}
+
+// current the segment the chained calls apply to, the first one is a descendant of the root
+func (q *Query) current() *querySegment {
+	if len(q.segments) == 0 {
+		q.segments = append(q.segments, querySegment{})
+	}
+	return &q.segments[len(q.segments)-1]
+}
+
+// Type
+//
+// the element type, e.g. `XCUIElementTypeCell` or `Cell`, all of them when not called
+func (q *Query) Type(elemType string) *Query {
+	if elemType != "*" && !strings.HasPrefix(elemType, "XCUIElementType") {
+		elemType = "XCUIElementType" + elemType
+	}
+	q.current().class = elemType
+	return q
+}
+
+func (q *Query) Name(name string) *Query {
+	return q.Where(NewWDAPredicate().Equal(WDAPredicateName, name))
+}
+
+func (q *Query) Label(label string) *Query {
+	return q.Where(NewWDAPredicate().Equal(WDAPredicateLabel, label))
+}
+
+func (q *Query) Value(value string) *Query {
+	return q.Where(NewWDAPredicate().Equal(WDAPredicateValue, value))
+}
+
+// Where adds the conditions of `predicate`
+func (q *Query) Where(predicate *WDAPredicate) *Query {
+	segment := q.current()
+	if segment.predicate == nil {
+		segment.predicate = NewWDAPredicate()
+	}
+	segment.predicate.conditions = append(segment.predicate.conditions, predicate.conditions...)
+	return q
+}
+
+// Index keeps the nth match (from 1, negative from the end), see WDAClassChain.Index
+func (q *Query) Index(n int) *Query {
+	q.current().index = n
+	return q
+}
+
+// Descendant the following calls describe a descendant of the elements matched so far
+func (q *Query) Descendant() *Query {
+	q.current()
+	q.segments = append(q.segments, querySegment{})
+	return q
+}
+
+// Child the following calls describe a direct child of the elements matched so far
+func (q *Query) Child() *Query {
+	q.current()
+	q.segments = append(q.segments, querySegment{child: true})
+	return q
+}
+
+// wdaLocator the class chain of the chained calls, or the locator of By
+func (q *Query) wdaLocator() WDALocator {
+	if len(q.segments) == 0 {
+		return q.locator
+	}
+	classChain := NewWDAClassChain()
+	for _, segment := range q.segments {
+		prefix, class := "**/", segment.class
+		if segment.child {
+			prefix = ""
+		}
+		if class == "" {
+			class = "*"
+		}
+		var predicates []*WDAPredicate
+		if segment.predicate != nil {
+			predicates = append(predicates, segment.predicate)
+		}
+		classChain.segmentOfClass(prefix, class, predicates)
+		if segment.index != 0 {
+			classChain.Index(segment.index)
+		}
+	}
+	return ByClassChain(classChain)
+}
+
+// First
+//
+// Finds the first match with a single request (the chained calls are one class chain query).
+// WithAttributes is not applied, see Iter.
+//
+//	elem, err := s.Query().Type("Cell").Label("Wi-Fi").Descendant().Type("Switch").First()
+func (q *Query) First() (element *Element, err error) {
+	var elemUID string
+	if elemUID, err = findUidOfElement(q.client, q.baseUrl, q.wdaLocator()); err != nil {
+		return nil, err
+	}
+	return newElement(q.client, q.endpoint, elemUID), nil
+}
+
+// All
+//
+// like FindElements, the elements carry the attributes asked with WithAttributes
+func (q *Query) All() (elements []*Element, err error) {
+	it := q.Iter(context.Background())
+	defer func() { _ = it.Close() }()
+	for it.Next() {
+		elements = append(elements, it.Element())
+	}
+	if err = it.Err(); err != nil {
+		return nil, err
+	}
+	if len(elements) == 0 {
+		using, value := q.wdaLocator().getUsingAndValue()
+		return nil, fmt.Errorf("%w: unable to find an element using '%s', value '%s'", ErrNoSuchElement, using, value)
+	}
+	return
+}
+
+// WithAttributes
+//
+// Asks WDA to return the given attributes (e.g. `name`, `rect`) inline with every match, where the WDA fork supports it.
+// The found elements answer Rect, Name, Label, Value, Text, Type, IsEnabled, IsDisplayed, IsSelected and GetAttribute
+// from these values without another request, they are a snapshot taken by the find.
+// A WDA without the support ignores them, the elements then fall back to requests.
+func (q *Query) WithAttributes(attributes ...string) *Query {
+	q.attributes = attributes
+	return q
+}
+
+// Iter
+//
+// Returns an iterator which decodes the `/elements` response while it is being received,
+// so hundreds of matches are neither buffered nor turned into a slice up front.
+// Cancelling `ctx` aborts the transfer.
+//
+// Unlike FindElements, no matches is not an error, Next simply returns `false`.
+func (q *Query) Iter(ctx context.Context) *ElementIterator {
+	return &ElementIterator{ctx: ctx, query: q}
+}
+
+// ElementIterator
+//
+//	it := s.Query().By(WDALocator{ClassName: WDAElementType{Cell: true}}).Iter(ctx)
+//	defer it.Close()
+//	for it.Next() {
+//		elem := it.Element()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type ElementIterator struct {
+	ctx   context.Context
+	query *Query
+
+	rc   io.ReadCloser
+	dec  *json.Decoder
+	stop chan struct{}
+
+	elem    *Element
+	err     error
+	started bool
+	done    bool
+}
+
+// Next
+//
+// advances to the next element, it returns `false` when there are no more elements or an error occurred
+func (it *ElementIterator) Next() bool {
+	if it.done {
+		return false
+	}
+	if err := it.ctx.Err(); err != nil {
+		it.finish(err)
+		return false
+	}
+	if !it.started {
+		it.started = true
+		if err := it.open(); err != nil {
+			it.finish(err)
+			return false
+		}
+	}
+	if !it.dec.More() {
+		it.finish(nil)
+		return false
+	}
+	var v map[string]json.RawMessage
+	if err := it.dec.Decode(&v); err != nil {
+		it.finish(it.wrapErr(err))
+		return false
+	}
+	rawUID, ok := v["ELEMENT"]
+	if !ok {
+		rawUID = v[WDAElementKey]
+	}
+	var uid string
+	if rawUID != nil {
+		if err := json.Unmarshal(rawUID, &uid); err != nil {
+			it.finish(it.wrapErr(err))
+			return false
+		}
+	}
+	if uid == "" {
+		it.finish(errors.New("FindElements: an element of the response has no id"))
+		return false
+	}
+	it.elem = newElement(it.query.client, it.query.endpoint, uid)
+	if len(it.query.attributes) != 0 {
+		it.elem.setAttributes(v)
+	}
+	return true
+}
+
+// Element
+//
+// returns the current element
+func (it *ElementIterator) Element() *Element {
+	return it.elem
+}
+
+// Err
+//
+// returns the error that stopped the iteration, if any
+func (it *ElementIterator) Err() error {
+	return it.err
+}
+
+// Close
+//
+// releases the underlying response, it is safe to call more than once
+func (it *ElementIterator) Close() error {
+	it.finish(nil)
+	return nil
+}
+
+func (it *ElementIterator) open() (err error) {
+	using, value := it.query.wdaLocator().getUsingAndValue()
+	if using == "" {
+		return errors.New("'WDALocator' is empty")
+	}
+	body := newWdaBody().set("using", using).set("value", value)
+	if len(it.query.attributes) != 0 {
+		body.set("attributes", it.query.attributes)
+	}
+	if it.rc, err = executeStream(it.query.client, "FindElements", http.MethodPost, urlJoin(it.query.baseUrl, "/elements"), body); err != nil {
+		return err
+	}
+	it.stop = make(chan struct{})
+	go func(rc io.ReadCloser, ctx context.Context, stop chan struct{}) {
+		select {
+		case <-ctx.Done():
+			_ = rc.Close()
+		case <-stop:
+		}
+	}(it.rc, it.ctx, it.stop)
+
+	it.dec = json.NewDecoder(it.rc)
+	// {"value": [ {"ELEMENT": "..."}, ... ], "sessionId": "..."}
+	if err = it.expectDelim('{'); err != nil {
+		return err
+	}
+	for it.dec.More() {
+		var key json.Token
+		if key, err = it.dec.Token(); err != nil {
+			return it.wrapErr(err)
+		}
+		if key != "value" {
+			var skip json.RawMessage
+			if err = it.dec.Decode(&skip); err != nil {
+				return it.wrapErr(err)
+			}
+			continue
+		}
+		var tok json.Token
+		if tok, err = it.dec.Token(); err != nil {
+			return it.wrapErr(err)
+		}
+		switch tok {
+		case json.Delim('['):
+			return nil
+		case json.Delim('{'):
+			return it.decodeErrValue()
+		default:
+			return fmt.Errorf("FindElements: unexpected value %v", tok)
+		}
+	}
+	return errors.New("FindElements: missing value")
+}
+
+// decodeErrValue
+//
+// `{"value": {"error": "...", "message": "..."}}` with a successful status code
+func (it *ElementIterator) decodeErrValue() (err error) {
+	value := newWdaBody()
+	for it.dec.More() {
+		var key json.Token
+		if key, err = it.dec.Token(); err != nil {
+			return it.wrapErr(err)
+		}
+		var v interface{}
+		if err = it.dec.Decode(&v); err != nil {
+			return it.wrapErr(err)
+		}
+		value.set(fmt.Sprint(key), v)
+	}
+	bsResp, _ := json.Marshal(newWdaBody().set("value", value))
+	if err = wdaResponse(bsResp).getErrMsg(); err == nil {
+		err = errors.New("FindElements: unexpected value")
+	}
+	return
+}
+
+func (it *ElementIterator) expectDelim(delim json.Delim) error {
+	tok, err := it.dec.Token()
+	if err != nil {
+		return it.wrapErr(err)
+	}
+	if tok != delim {
+		return fmt.Errorf("FindElements: expected '%s', got %v", delim, tok)
+	}
+	return nil
+}
+
+func (it *ElementIterator) wrapErr(err error) error {
+	if ctxErr := it.ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return fmt.Errorf("FindElements: failed to read response %w", err)
+}
+
+func (it *ElementIterator) finish(err error) {
+	if it.done {
+		return
+	}
+	it.done = true
+	it.err = err
+	it.elem = nil
+	if it.rc != nil {
+		close(it.stop)
+		_ = it.rc.Close()
+	}
+}
+
+// setAttributes keeps the members of a found element besides its reference
+func (e *Element) setAttributes(v map[string]json.RawMessage) {
+	for key, raw := range v {
+		if key == "ELEMENT" || key == WDAElementKey {
+			continue
+		}
+		if e.attributes == nil {
+			e.attributes = make(map[string]gjson.Result, len(v))
+		}
+		e.attributes[key] = gjson.ParseBytes(raw)
+	}
+}
+
+func (e *Element) cachedAttribute(name string) (v gjson.Result, ok bool) {
+	v, ok = e.attributes[name]
+	return
+}
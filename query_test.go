@@ -0,0 +1,133 @@
+package gwda
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestQuery_Iter(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	WDADebug(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	it := s.Query().By(WDALocator{ClassName: WDAElementType{Cell: true}}).Iter(ctx)
+	defer func() {
+		_ = it.Close()
+	}()
+	count := 0
+	for it.Next() {
+		count++
+		t.Log(it.Element().UID)
+	}
+	checkErr(t, it.Err())
+	t.Log("count:", count)
+}
+
+func TestElement_setAttributes(t *testing.T) {
+	var v map[string]json.RawMessage
+	checkErr(t, json.Unmarshal([]byte(`{"ELEMENT":"E1","name":"General","enabled":true,"rect":{"x":1,"y":2,"width":3,"height":4}}`), &v))
+	elem := newElement(nil, nil, "E1")
+	elem.setAttributes(v)
+
+	name, err := elem.Name()
+	checkErr(t, err)
+	if name != "General" {
+		t.Error("name:", name)
+	}
+	rect, err := elem.Rect()
+	checkErr(t, err)
+	if rect.X != 1 || rect.Y != 2 || rect.Width != 3 || rect.Height != 4 {
+		t.Error("rect:", rect)
+	}
+	if isEnabled, err := elem.IsEnabled(); err != nil || !isEnabled {
+		t.Error("enabled:", isEnabled, err)
+	}
+	if _, ok := elem.cachedAttribute("ELEMENT"); ok {
+		t.Error("the reference is not an attribute")
+	}
+}
+
+func TestQuery_WithAttributes(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+
+	it := s.Query().By(WDALocator{ClassName: WDAElementType{Cell: true}}).WithAttributes("name", "rect").Iter(context.Background())
+	defer func() {
+		_ = it.Close()
+	}()
+	for it.Next() {
+		name, err := it.Element().Name()
+		checkErr(t, err)
+		rect, err := it.Element().Rect()
+		checkErr(t, err)
+		t.Log(name, rect)
+	}
+	checkErr(t, it.Err())
+}
+
+func TestQuery_Iter_missingID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"value":[{"ELEMENT":"E1"},{"name":"Wi-Fi"},{"ELEMENT":"E3"}],"sessionId":"S1"}`))
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL)
+	checkErr(t, err)
+	sessionURL, _ := url.Parse(server.URL + "/session/S1")
+	it := newQuery(c, sessionURL, sessionURL).Type("Cell").Iter(context.Background())
+	defer func() {
+		_ = it.Close()
+	}()
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Element().UID)
+	}
+	if len(ids) != 1 || it.Err() == nil {
+		t.Fatalf("the iteration must fail at the element without id, got %v, %v", ids, it.Err())
+	}
+}
+
+func TestQuery_wdaLocator(t *testing.T) {
+	q := newQuery(nil, nil, nil).Type("XCUIElementTypeCell").Label("Wi-Fi").Descendant().Type("Switch").Index(1)
+	want := "**/XCUIElementTypeCell[`label == 'Wi-Fi'`]/**/XCUIElementTypeSwitch[1]"
+	if using, value := q.wdaLocator().getUsingAndValue(); using != "class chain" || value != want {
+		t.Fatalf("got %s %s\nwant %s", using, value, want)
+	}
+
+	q = newQuery(nil, nil, nil).Type("Table").Child().Where(NewWDAPredicate().Is(WDAPredicateVisible, true)).Name("a")
+	want = "**/XCUIElementTypeTable/*[`visible == 1 AND name == 'a'`]"
+	if _, value := q.wdaLocator().getUsingAndValue(); value != want {
+		t.Fatalf("got %s\nwant %s", value, want)
+	}
+
+	if using, _ := q.By(ByName("a")).wdaLocator().getUsingAndValue(); using != "name" {
+		t.Fatalf("By must replace the chained calls, got %s", using)
+	}
+}
+
+func TestQuery_First(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	checkErr(t, s.AppLaunch("com.apple.Preferences"))
+
+	text, err := s.Query().Type("Cell").Index(1).Descendant().Type("StaticText").First()
+	checkErr(t, err)
+	t.Log(text.Label())
+	cells, err := s.Query().Type("Table").Child().Type("Cell").All()
+	checkErr(t, err)
+	t.Log(len(cells))
+}
@@ -0,0 +1,136 @@
+package gwda
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// WDARequest
+//
+// Describes a request to any WDA endpoint, for those without a method of their own (see Session.Execute).
+// `:name` segments of the pattern are path parameters, their values are escaped, so they may contain `/` or spaces.
+//
+//	req := NewWDARequest(http.MethodGet, "/element/:uuid/attribute/:name").
+//		SetPathParam("uuid", elem.UID).
+//		SetPathParam("name", "label")
+type WDARequest struct {
+	method     string
+	pattern    string
+	pathParams map[string]string
+	query      url.Values
+	body       wdaBody
+}
+
+// NewWDARequest
+//
+// `pattern` is relative to the session (Session.Execute) or to the root of WDA (Client.Execute)
+func NewWDARequest(method, pattern string) *WDARequest {
+	return &WDARequest{method: method, pattern: pattern, pathParams: make(map[string]string), query: make(url.Values)}
+}
+
+// SetPathParam the value of the `:name` segment
+func (r *WDARequest) SetPathParam(name, value string) *WDARequest {
+	r.pathParams[name] = value
+	return r
+}
+
+// AddQuery appends a query parameter, `key` may be repeated
+func (r *WDARequest) AddQuery(key, value string) *WDARequest {
+	r.query.Add(key, value)
+	return r
+}
+
+// SetBody the JSON body, usually of a POST
+func (r *WDARequest) SetBody(body map[string]interface{}) *WDARequest {
+	r.body = body
+	return r
+}
+
+// url checks every path parameter of the pattern is set and every set one is used
+func (r *WDARequest) url(baseUrl *url.URL) (sURL string, err error) {
+	if r.method == "" {
+		return "", errors.New("'WDARequest' has no method")
+	}
+	segments := strings.Split(strings.Trim(r.pattern, "/"), "/")
+	used := 0
+	for i, seg := range segments {
+		if !strings.HasPrefix(seg, ":") {
+			continue
+		}
+		value, ok := r.pathParams[seg[1:]]
+		if !ok || value == "" {
+			return "", fmt.Errorf("'WDARequest' %s: missing path parameter '%s'", r.pattern, seg[1:])
+		}
+		if value == "." || value == ".." {
+			return "", fmt.Errorf("'WDARequest' %s: invalid path parameter '%s': %s", r.pattern, seg[1:], value)
+		}
+		segments[i] = value
+		used++
+	}
+	if used != len(r.pathParams) {
+		for name := range r.pathParams {
+			if !strings.Contains("/"+r.pattern+"/", "/:"+name+"/") {
+				return "", fmt.Errorf("'WDARequest' %s: unknown path parameter '%s'", r.pattern, name)
+			}
+		}
+	}
+	return joinURL(baseUrl, r.query, segments...), nil
+}
+
+// joinURL
+//
+// Appends the segments to the path of `baseUrl` and the query parameters to its query.
+// Every segment is escaped on its own, `/` inside a segment doesn't start a new one.
+func joinURL(baseUrl *url.URL, query url.Values, segments ...string) string {
+	tmp := *baseUrl
+	unescaped := []string{baseUrl.Path}
+	escaped := []string{baseUrl.EscapedPath()}
+	for _, seg := range segments {
+		if seg == "" {
+			continue
+		}
+		unescaped = append(unescaped, seg)
+		escaped = append(escaped, url.PathEscape(seg))
+	}
+	tmp.Path, tmp.RawPath = path.Join(unescaped...), path.Join(escaped...)
+	if len(query) != 0 {
+		q := tmp.Query()
+		for key, values := range query {
+			q[key] = append(q[key], values...)
+		}
+		tmp.RawQuery = q.Encode()
+	}
+	return tmp.String()
+}
+
+// Execute
+//
+// sends `req` relative to the root of WDA and returns the raw `value` of the response
+func (c *Client) Execute(req *WDARequest) (value json.RawMessage, err error) {
+	return execute(c, c.deviceURL, req)
+}
+
+// Execute
+//
+// sends `req` relative to the session and returns the raw `value` of the response
+//
+//	value, err := s.Execute(NewWDARequest(http.MethodGet, "/wda/screen"))
+func (s *Session) Execute(req *WDARequest) (value json.RawMessage, err error) {
+	return execute(s.client, s.sessionURL(), req)
+}
+
+func execute(c *Client, baseUrl *url.URL, req *WDARequest) (value json.RawMessage, err error) {
+	var sURL string
+	if sURL, err = req.url(baseUrl); err != nil {
+		return nil, err
+	}
+	var wdaResp wdaResponse
+	if wdaResp, err = executeHTTP(c, "Execute", req.method, sURL, req.body); err != nil {
+		return nil, err
+	}
+	return rawPath(wdaResp, "value"), nil
+}
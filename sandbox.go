@@ -0,0 +1,187 @@
+package gwda
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// AppContainer
+//
+// Accesses the container of an application through the house_arrest service, WDA has no endpoint for it.
+// `remotePath` is relative to the container, e.g. `Documents/fixture.json` or `tmp/output.log`.
+type AppContainer interface {
+	PushFile(udid, bundleId, localPath, remotePath string) error
+	PullFile(udid, bundleId, remotePath, localPath string) error
+	ListFiles(udid, bundleId, remoteDir string) ([]string, error)
+}
+
+// DefaultAppContainer used by the clients without WDAClientOption.SetAppContainer
+var DefaultAppContainer AppContainer = Afcclient{}
+
+// Afcclient
+//
+// runs `afcclient` of libimobiledevice, which must be in the PATH unless `Path` is set.
+// Only the applications signed for development expose their container.
+type Afcclient struct {
+	Path string
+}
+
+func (ac Afcclient) PushFile(udid, bundleId, localPath, remotePath string) (err error) {
+	_, err = ac.output(udid, bundleId, "put", "-f", localPath, remotePath)
+	return
+}
+
+func (ac Afcclient) PullFile(udid, bundleId, remotePath, localPath string) (err error) {
+	_, err = ac.output(udid, bundleId, "get", "-f", remotePath, localPath)
+	return
+}
+
+func (ac Afcclient) ListFiles(udid, bundleId, remoteDir string) (files []string, err error) {
+	var output []byte
+	if output, err = ac.output(udid, bundleId, "ls", remoteDir); err != nil {
+		return nil, err
+	}
+	for _, name := range strings.Split(string(output), "\n") {
+		if name = strings.TrimSpace(name); name != "" && name != "." && name != ".." {
+			files = append(files, path.Join(remoteDir, name))
+		}
+	}
+	return
+}
+
+func (ac Afcclient) output(udid, bundleId string, args ...string) ([]byte, error) {
+	name := ac.Path
+	if name == "" {
+		name = "afcclient"
+	}
+	args = append([]string{"-u", udid, "--container", bundleId}, args...)
+	output, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return output, nil
+}
+
+// containerPath cleans `remotePath`, which must stay in `Documents`, `Library` or `tmp` of the container
+func containerPath(remotePath string) (string, error) {
+	for _, elem := range strings.Split(remotePath, "/") {
+		if elem == ".." {
+			return "", fmt.Errorf("invalid container path: %s", remotePath)
+		}
+	}
+	cleaned := path.Clean("/" + remotePath)
+	root := strings.SplitN(strings.TrimPrefix(cleaned, "/"), "/", 2)[0]
+	switch root {
+	case "Documents", "Library", "tmp":
+		return cleaned, nil
+	}
+	return "", fmt.Errorf("container path must be in Documents, Library or tmp: %s", remotePath)
+}
+
+func (c *Client) container(bundleId string) (container AppContainer, udid string, err error) {
+	if bundleId == "" {
+		return nil, "", errors.New("'bundleId' is empty")
+	}
+	if container = c.appContainer; container == nil {
+		container = DefaultAppContainer
+	}
+	if container == nil {
+		return nil, "", errors.New("no app container")
+	}
+	if udid = c.UDID(); udid == "" {
+		return nil, "", errors.New("the UDID of the device is unknown, see WDAClientOption.SetUDID")
+	}
+	return
+}
+
+// PushFile
+//
+// Copies a local file into the container of the application with the AppContainer of the Client,
+// e.g. to seed a fixture before launching it.
+//
+//	err := c.PushFile("com.example.demo", "testdata/user.json", "Documents/user.json")
+func (c *Client) PushFile(bundleId, localPath, remotePath string) (err error) {
+	if remotePath, err = containerPath(remotePath); err != nil {
+		return err
+	}
+	var info os.FileInfo
+	if info, err = os.Stat(localPath); err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("not a file: %s", localPath)
+	}
+	var container AppContainer
+	var udid string
+	if container, udid, err = c.container(bundleId); err != nil {
+		return err
+	}
+	if err = container.PushFile(udid, bundleId, localPath, remotePath); err != nil {
+		return &WDAOriginError{Origin: c.Origin(), Err: fmt.Errorf("push %s to %s: %w", localPath, remotePath, err)}
+	}
+	return
+}
+
+// PullFile
+//
+// copies a file of the container of the application to `localPath`, e.g. an artifact produced by it
+func (c *Client) PullFile(bundleId, remotePath, localPath string) (err error) {
+	if remotePath, err = containerPath(remotePath); err != nil {
+		return err
+	}
+	var container AppContainer
+	var udid string
+	if container, udid, err = c.container(bundleId); err != nil {
+		return err
+	}
+	if err = container.PullFile(udid, bundleId, remotePath, localPath); err != nil {
+		return &WDAOriginError{Origin: c.Origin(), Err: fmt.Errorf("pull %s: %w", remotePath, err)}
+	}
+	return
+}
+
+// ListFiles
+//
+// the entries of a directory of the container of the application, as container paths
+func (c *Client) ListFiles(bundleId, remoteDir string) (files []string, err error) {
+	if remoteDir, err = containerPath(remoteDir); err != nil {
+		return nil, err
+	}
+	var container AppContainer
+	var udid string
+	if container, udid, err = c.container(bundleId); err != nil {
+		return nil, err
+	}
+	if files, err = container.ListFiles(udid, bundleId, remoteDir); err != nil {
+		return nil, &WDAOriginError{Origin: c.Origin(), Err: fmt.Errorf("list %s: %w", remoteDir, err)}
+	}
+	return
+}
+
+// PushFile see Client.PushFile
+func (s *Session) PushFile(bundleId, localPath, remotePath string) error {
+	if s.client == nil {
+		return errors.New("session without client")
+	}
+	return s.client.PushFile(bundleId, localPath, remotePath)
+}
+
+// PullFile see Client.PullFile
+func (s *Session) PullFile(bundleId, remotePath, localPath string) error {
+	if s.client == nil {
+		return errors.New("session without client")
+	}
+	return s.client.PullFile(bundleId, remotePath, localPath)
+}
+
+// ListFiles see Client.ListFiles
+func (s *Session) ListFiles(bundleId, remoteDir string) ([]string, error) {
+	if s.client == nil {
+		return nil, errors.New("session without client")
+	}
+	return s.client.ListFiles(bundleId, remoteDir)
+}
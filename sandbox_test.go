@@ -0,0 +1,79 @@
+package gwda
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type recordingContainer struct {
+	calls []string
+}
+
+func (rc *recordingContainer) PushFile(udid, bundleId, localPath, remotePath string) error {
+	rc.calls = append(rc.calls, "push "+bundleId+" "+filepath.Base(localPath)+" "+remotePath)
+	return nil
+}
+
+func (rc *recordingContainer) PullFile(udid, bundleId, remotePath, localPath string) error {
+	rc.calls = append(rc.calls, "pull "+bundleId+" "+remotePath)
+	return nil
+}
+
+func (rc *recordingContainer) ListFiles(udid, bundleId, remoteDir string) ([]string, error) {
+	rc.calls = append(rc.calls, "ls "+bundleId+" "+remoteDir)
+	return []string{remoteDir + "/a.txt"}, nil
+}
+
+func Test_containerPath(t *testing.T) {
+	for remote, want := range map[string]string{
+		"Documents":            "/Documents",
+		"Documents/a/./b.json": "/Documents/a/b.json",
+		"/tmp/out.log":         "/tmp/out.log",
+		"Library/Caches/":      "/Library/Caches",
+		"Documents/../etc":     "",
+		"../Documents/a.json":  "",
+		"Demo.app/Info.plist":  "",
+		"":                     "",
+	} {
+		got, err := containerPath(remote)
+		if got != want || (err == nil) != (want != "") {
+			t.Errorf("containerPath(%q) = %q, %v", remote, got, err)
+		}
+	}
+}
+
+func TestClient_PushFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gwda")
+	checkErr(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	local := filepath.Join(dir, "user.json")
+	checkErr(t, ioutil.WriteFile(local, []byte("{}"), 0644))
+
+	container := new(recordingContainer)
+	c := &Client{deviceURL: &url.URL{Scheme: "http", Host: "192.168.1.2:8100"}, udid: "00008030-001A2B3C4D5E6F"}
+	c.appContainer = container
+
+	checkErr(t, c.PushFile("com.example.demo", local, "Documents/user.json"))
+	checkErr(t, c.PullFile("com.example.demo", "tmp/out.log", filepath.Join(dir, "out.log")))
+	files, err := c.ListFiles("com.example.demo", "Documents")
+	checkErr(t, err)
+	if len(files) != 1 || files[0] != "/Documents/a.txt" {
+		t.Fatalf("unexpected files: %q", files)
+	}
+	if err := c.PushFile("com.example.demo", local, "../user.json"); err == nil {
+		t.Fatal("expected an error outside of the container")
+	}
+
+	want := []string{
+		"push com.example.demo user.json /Documents/user.json",
+		"pull com.example.demo /tmp/out.log",
+		"ls com.example.demo /Documents",
+	}
+	if !reflect.DeepEqual(container.calls, want) {
+		t.Fatalf("unexpected calls: %q", container.calls)
+	}
+}
@@ -0,0 +1,58 @@
+package gwda
+
+import "fmt"
+
+// WDAScrollNotFoundError returned by ScrollUntil
+type WDAScrollNotFoundError struct {
+	Locator WDALocator
+	Swipes  int
+	// the last response of WDA: `no such element`, or `nil` when the element was found but not hittable
+	LastErr error
+}
+
+func (e *WDAScrollNotFoundError) Error() string {
+	using, value := e.Locator.getUsingAndValue()
+	msg := fmt.Sprintf("element using '%s', value '%s' not hittable after %d swipes", using, value, e.Swipes)
+	if e.LastErr != nil {
+		msg += ": " + e.LastErr.Error()
+	}
+	return msg
+}
+
+func (e *WDAScrollNotFoundError) Unwrap() error {
+	return e.LastErr
+}
+
+// ScrollUntil
+//
+// Looks for a hittable element among the descendants of `container`, swiping the container in `direction` up to
+// `maxSwipes` times (WDASwipeDirectionUp reveals the content below). The implicit timeout applies to each look-up,
+// set it low (see SetTimeouts) to keep the swipes quick. Fails with a WDAScrollNotFoundError.
+//
+//	elem, err := s.ScrollUntil(elemTable, ByName("Privacy"), WDASwipeDirectionUp, 10)
+func (s *Session) ScrollUntil(container *Element, wdaLocator WDALocator, direction WDASwipeDirection, maxSwipes int) (element *Element, err error) {
+	for swipes := 0; ; swipes++ {
+		var lastErr error
+		if element, lastErr = findHittable(container, wdaLocator); element != nil {
+			return element, nil
+		}
+		if lastErr != nil && !isNoSuchElement(lastErr) && !isStaleElement(lastErr) {
+			return nil, lastErr
+		}
+		if swipes >= maxSwipes {
+			return nil, &WDAScrollNotFoundError{Locator: wdaLocator, Swipes: swipes, LastErr: lastErr}
+		}
+		if err = container.SwipeDirection(direction); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// findHittable the first hittable element among the matching descendants of `container`
+func findHittable(container *Element, wdaLocator WDALocator) (element *Element, err error) {
+	var elements []*Element
+	if elements, err = pollElements(container.client, container.endpoint, container._withFormatToUrl(), wdaLocator, false, []WDAElementCondition{ElementHittable}); len(elements) != 0 {
+		return elements[0], nil
+	}
+	return nil, err
+}
@@ -0,0 +1,31 @@
+package gwda
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWDAScrollNotFoundError(t *testing.T) {
+	err := error(&WDAScrollNotFoundError{Locator: ByName("Privacy"), Swipes: 3})
+	if want := "element using 'name', value 'Privacy' not hittable after 3 swipes"; err.Error() != want {
+		t.Fatal(err)
+	}
+	var errNotFound *WDAScrollNotFoundError
+	if !errors.As(err, &errNotFound) || errNotFound.Swipes != 3 {
+		t.Fatal("expected a WDAScrollNotFoundError")
+	}
+}
+
+func TestSession_ScrollUntil(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	checkErr(t, s.AppLaunch("com.apple.Preferences"))
+
+	table, err := s.FindElement(WDALocator{ClassName: WDAElementType{Table: true}})
+	checkErr(t, err)
+	element, err := s.ScrollUntil(table, ByName("Privacy"), WDASwipeDirectionUp, 10)
+	checkErr(t, err)
+	checkErr(t, element.Click())
+}
@@ -0,0 +1,155 @@
+package gwda
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DefaultSendKeysChunkSize number of characters typed per request by SendKeysWithOption
+const DefaultSendKeysChunkSize = 200
+
+// WDASendKeysOption
+//
+// Types long texts in chunks, each one is a separate request and can be verified before the next one is typed.
+//
+//	opt := NewWDASendKeysOption().SetChunkSize(100).SetVerify(true)
+//	err := elemTextView.SendKeysWithOption(article, opt)
+type WDASendKeysOption struct {
+	frequency int
+	chunkSize int
+	verify    bool
+	interval  time.Duration
+}
+
+func NewWDASendKeysOption() *WDASendKeysOption {
+	return &WDASendKeysOption{chunkSize: DefaultSendKeysChunkSize}
+}
+
+// SetFrequency
+//
+// typing frequency sent with every chunk, see SendKeys
+func (o *WDASendKeysOption) SetFrequency(frequency int) *WDASendKeysOption {
+	o.frequency = frequency
+	return o
+}
+
+// SetChunkSize
+//
+// Default is DefaultSendKeysChunkSize, `<= 0` types the whole text at once
+func (o *WDASendKeysOption) SetChunkSize(n int) *WDASendKeysOption {
+	o.chunkSize = n
+	return o
+}
+
+// SetVerify
+//
+// After each chunk the value of the field must end with it, otherwise the typing stops.
+// Chunks with control characters (e.g. `\n`, WDATextBackspaceSequence) and secure fields are not verifiable.
+func (o *WDASendKeysOption) SetVerify(b bool) *WDASendKeysOption {
+	o.verify = b
+	return o
+}
+
+// SetChunkInterval
+//
+// pause between two chunks, e.g. for apps formatting their input
+func (o *WDASendKeysOption) SetChunkInterval(d time.Duration) *WDASendKeysOption {
+	o.interval = d
+	return o
+}
+
+// SetCharacterDelay
+//
+// Types one character per request with a pause of `d` between them, for fields too slow even
+// for a low frequency (e.g. validating or auto-completing on every keystroke). Overrides SetChunkSize and SetChunkInterval.
+func (o *WDASendKeysOption) SetCharacterDelay(d time.Duration) *WDASendKeysOption {
+	o.chunkSize = 1
+	o.interval = d
+	return o
+}
+
+// SendKeysWithOption
+//
+// SendKeys in chunks, the verification reads the focused element (ActiveElement)
+func (s *Session) SendKeysWithOption(text string, opt *WDASendKeysOption) error {
+	return sendKeysInChunks(text, opt,
+		func(chunk string, frequency ...int) error {
+			return s.SendKeys(chunk, frequency...)
+		},
+		func() (string, error) {
+			elem, err := s.ActiveElement()
+			if err != nil {
+				return "", err
+			}
+			return elem.Value()
+		},
+	)
+}
+
+// SendKeysWithOption
+//
+// SendKeys in chunks
+func (e *Element) SendKeysWithOption(text string, opt *WDASendKeysOption) error {
+	return sendKeysInChunks(text, opt, e.SendKeys, e.Value)
+}
+
+func sendKeysInChunks(text string, opt *WDASendKeysOption, send func(chunk string, frequency ...int) error, value func() (string, error)) (err error) {
+	if opt == nil {
+		opt = NewWDASendKeysOption()
+	}
+	var frequency []int
+	if opt.frequency > 0 {
+		frequency = []int{opt.frequency}
+	}
+	chunks := splitKeys(text, opt.chunkSize)
+	for i, chunk := range chunks {
+		if i != 0 && opt.interval > 0 {
+			time.Sleep(opt.interval)
+		}
+		if err = send(chunk, frequency...); err != nil {
+			return fmt.Errorf("chunk %d/%d: %w", i+1, len(chunks), err)
+		}
+		if !opt.verify || !isVerifiableKeys(chunk) {
+			continue
+		}
+		var v string
+		if v, err = value(); err != nil {
+			return fmt.Errorf("chunk %d/%d: verify: %w", i+1, len(chunks), err)
+		}
+		if !strings.HasSuffix(v, chunk) {
+			return fmt.Errorf("chunk %d/%d: value does not end with the typed text: %q", i+1, len(chunks), chunk)
+		}
+	}
+	return
+}
+
+// splitKeys splits `text` into chunks of at most `size` characters, `\r\n` is never split
+func splitKeys(text string, size int) []string {
+	runes := []rune(text)
+	if size <= 0 || len(runes) <= size {
+		return []string{text}
+	}
+	chunks := make([]string, 0, len(runes)/size+1)
+	for len(runes) != 0 {
+		n := size
+		if n >= len(runes) {
+			n = len(runes)
+		} else if n > 1 && runes[n-1] == '\r' && runes[n] == '\n' {
+			n--
+		}
+		chunks = append(chunks, string(runes[:n]))
+		runes = runes[n:]
+	}
+	return chunks
+}
+
+func isVerifiableKeys(chunk string) bool {
+	for _, r := range chunk {
+		if unicode.IsControl(r) {
+			return false
+		}
+	}
+	return true
+}
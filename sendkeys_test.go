@@ -0,0 +1,77 @@
+package gwda
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_splitKeys(t *testing.T) {
+	tests := []struct {
+		text string
+		size int
+		want []string
+	}{
+		{"abc", 0, []string{"abc"}},
+		{"abc", 3, []string{"abc"}},
+		{"abcdefg", 3, []string{"abc", "def", "g"}},
+		{"音乐音乐", 3, []string{"音乐音", "乐"}},
+		{"ab\r\ncd", 3, []string{"ab", "\r\nc", "d"}},
+	}
+	for _, tt := range tests {
+		if got := splitKeys(tt.text, tt.size); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitKeys(%q, %d) = %q, want %q", tt.text, tt.size, got, tt.want)
+		}
+	}
+}
+
+func Test_sendKeysInChunks(t *testing.T) {
+	var typed strings.Builder
+	send := func(chunk string, frequency ...int) error {
+		if len(frequency) != 1 || frequency[0] != 30 {
+			t.Fatalf("unexpected frequency: %v", frequency)
+		}
+		typed.WriteString(chunk)
+		return nil
+	}
+	value := func() (string, error) { return typed.String(), nil }
+
+	opt := NewWDASendKeysOption().SetChunkSize(2).SetFrequency(30).SetVerify(true)
+	if err := sendKeysInChunks("hello\n", opt, send, value); err != nil {
+		t.Fatal(err)
+	}
+	if typed.String() != "hello\n" {
+		t.Fatalf("typed %q", typed.String())
+	}
+
+	lost := func() (string, error) { return "", nil }
+	if err := sendKeysInChunks("hello", opt, send, lost); err == nil || !strings.HasPrefix(err.Error(), "chunk 1/3") {
+		t.Fatalf("expected a verification error, got %v", err)
+	}
+
+	errSend := errors.New("send")
+	failing := func(string, ...int) error { return errSend }
+	if err := sendKeysInChunks("hello", opt, failing, value); !errors.Is(err, errSend) {
+		t.Fatalf("expected %v, got %v", errSend, err)
+	}
+}
+
+func TestWDASendKeysOption_SetCharacterDelay(t *testing.T) {
+	var chunks []string
+	var last time.Time
+	send := func(chunk string, frequency ...int) error {
+		if !last.IsZero() && time.Since(last) < 10*time.Millisecond {
+			t.Fatal("no delay between the characters")
+		}
+		last = time.Now()
+		chunks = append(chunks, chunk)
+		return nil
+	}
+	opt := NewWDASendKeysOption().SetChunkSize(100).SetCharacterDelay(10 * time.Millisecond)
+	checkErr(t, sendKeysInChunks("好的ok", opt, send, nil))
+	if strings.Join(chunks, "|") != "好|的|o|k" {
+		t.Fatalf("unexpected chunks: %q", chunks)
+	}
+}
@@ -2,27 +2,249 @@ package gwda
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"image"
 	"io/ioutil"
+	"math"
+	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode"
+
+	"github.com/tidwall/gjson"
 )
 
+// Session is safe for concurrent use
 type Session struct {
-	sessionURL *url.URL
+	endpoint   atomic.Value // *url.URL, swapped by recover, see sessionURL
+	sid        string
+	formerSIDs []string // replaced by recover, still used by the elements and queries created before
+	client     *Client
+
+	serialRequests int32
+	requestMutex   sync.Mutex
+
+	capabilities wdaBody // as sent by NewSession, to recover
+	autoRecover  int32
+	recoverMutex sync.Mutex
+
+	history      *commandHistory
+	historyMutex sync.Mutex
+
+	infoCache    infoCache
+	elementCache elementCache
+
+	pasteboardCompanion  atomic.Value // string
+	pasteboardRetryEmpty int32
+	imageMatcher         atomic.Value // imageMatcherValue
+	ocrEngine            atomic.Value // ocrEngineValue
+
+	inRequestError int32 // while the OnRequestError hooks run for this session
+
+	closed        int32
+	suspended     int32
+	inflight      map[*wdaCall]context.CancelFunc
+	inflightMutex sync.Mutex
+
+	apps      sessionApps
+	appsMutex sync.Mutex
+
+	timeouts      *WDATimeouts // nil until SetTimeouts
+	implicitByWDA bool
+	timeoutsMutex sync.Mutex
 }
 
+// ErrSessionClosed is returned (wrapped) by the requests cancelled or refused because DeleteSession was called
+var ErrSessionClosed = errors.New("session closed")
+
 func newSession(deviceURL *url.URL, sid string) (s *Session) {
 	s = new(Session)
-	s.sessionURL, _ = url.Parse(deviceURL.String() + "/session/" + sid)
+	sessionURL, _ := url.Parse(deviceURL.String() + "/session/" + sid)
+	s.endpoint.Store(sessionURL)
+	s.sid = sid
 	return
 }
 
+// sessionURL `/session/:sessionId` of the current session id, read it again for each request as recover replaces it
+func (s *Session) sessionURL() *url.URL {
+	return s.endpoint.Load().(*url.URL)
+}
+
+// ID
+//
+// the WDA session id
+func (s *Session) ID() string {
+	if s.client != nil {
+		// changed by recover
+		s.client.sessionsMutex.RLock()
+		defer s.client.sessionsMutex.RUnlock()
+	}
+	return s.sid
+}
+
+// Client
+//
+// the client which created this session, for the resources shared by all sessions of a device (e.g. MJPEG stream)
+func (s *Session) Client() *Client {
+	return s.client
+}
+
+// SetSerialRequests
+//
+// WDA misbehaves with overlapping requests for some endpoints (gestures especially).
+// When enabled, the requests of this session (including its elements) are sent one at a time,
+// goroutines sharing the session wait for their turn.
+//
+// Default is `false`
+func (s *Session) SetSerialRequests(b bool) {
+	var v int32
+	if b {
+		v = 1
+	}
+	atomic.StoreInt32(&s.serialRequests, v)
+}
+
+// acquire returns `nil` if the requests are not serialized
+func (s *Session) acquire() (release func()) {
+	if atomic.LoadInt32(&s.serialRequests) == 0 {
+		return nil
+	}
+	s.requestMutex.Lock()
+	return s.requestMutex.Unlock
+}
+
+// track
+//
+// Returns the context of the request, cancelled by close. Once closed,
+// only DeleteSession is let through and every other request fails with ErrSessionClosed,
+// while suspended (see Suspend) they fail with ErrSessionSuspended.
+func (s *Session) track(ctx context.Context, call *wdaCall) (context.Context, error) {
+	s.inflightMutex.Lock()
+	defer s.inflightMutex.Unlock()
+	if s.isClosed() && call.actionName != "DeleteSession" {
+		return nil, ErrSessionClosed
+	}
+	if s.isSuspended() {
+		return nil, ErrSessionSuspended
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	if s.inflight == nil {
+		s.inflight = make(map[*wdaCall]context.CancelFunc)
+	}
+	s.inflight[call] = cancel
+	return ctx, nil
+}
+
+func (s *Session) untrack(call *wdaCall) {
+	s.inflightMutex.Lock()
+	defer s.inflightMutex.Unlock()
+	if cancel, ok := s.inflight[call]; ok {
+		cancel()
+		delete(s.inflight, call)
+	}
+}
+
+// close cancels the requests in flight, including the ones waiting for their turn (see SetSerialRequests)
+func (s *Session) close() {
+	s.inflightMutex.Lock()
+	defer s.inflightMutex.Unlock()
+	atomic.StoreInt32(&s.closed, 1)
+	for call, cancel := range s.inflight {
+		cancel()
+		delete(s.inflight, call)
+	}
+}
+
+func (s *Session) isClosed() bool {
+	return atomic.LoadInt32(&s.closed) == 1
+}
+
+// SetAutoRecover
+//
+// After WDA restarts or reaps the session, every request fails with `invalid session id`.
+// When enabled, such a request creates a new session with the original capabilities,
+// moves this Session (and its elements and queries) over to it and is retried once.
+// Elements found before are stale afterwards, so only the retry of element requests fails differently.
+// The configuration (see SetConfig), the timeouts set with SetTimeouts and the OnSessionCreated hooks
+// are applied to the new session again.
+//
+// Default is `false`
+func (s *Session) SetAutoRecover(b bool) {
+	var v int32
+	if b {
+		v = 1
+	}
+	atomic.StoreInt32(&s.autoRecover, v)
+}
+
+func (s *Session) isAutoRecover() bool {
+	return atomic.LoadInt32(&s.autoRecover) == 1 && s.client != nil
+}
+
+// recover replaces `staleSID`, requests failed concurrently reuse the first new session
+func (s *Session) recover(staleSID string) (sid string, err error) {
+	var recovered bool
+	if sid, recovered, err = s.replaceSession(staleSID); err != nil || !recovered {
+		return
+	}
+	// the cached elements belong to the stale session
+	s.InvalidateCache()
+	// not holding recoverMutex, these requests may have to recover too
+	if err = s.restore(); err != nil {
+		return "", err
+	}
+	return
+}
+
+func (s *Session) replaceSession(staleSID string) (sid string, recovered bool, err error) {
+	s.recoverMutex.Lock()
+	defer s.recoverMutex.Unlock()
+
+	if sid = s.ID(); sid != staleSID {
+		return sid, false, nil
+	}
+	var wdaResp wdaResponse
+	if wdaResp, err = executePost(s.client, "NewSession", urlJoin(s.client.deviceURL, "/session"), s.capabilities); err != nil {
+		return "", false, err
+	}
+	if sid = wdaResp.getSessionID(); sid == "" {
+		return "", false, errors.New("not find sessionId")
+	}
+	debugLog(fmt.Sprintf("session %s recovered as %s", staleSID, sid))
+
+	s.client.sessionsMutex.Lock()
+	defer s.client.sessionsMutex.Unlock()
+	s.formerSIDs = append(s.formerSIDs, s.sid)
+	s.sid = sid
+	s.endpoint.Store(newSession(s.client.deviceURL, sid).sessionURL())
+	return sid, true, nil
+}
+
+// restore applies to a recovered session what NewSession and SetTimeouts applied to the stale one
+func (s *Session) restore() (err error) {
+	if err = currentConfig().applyToSession(s); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	s.timeoutsMutex.Lock()
+	timeouts := s.timeouts
+	s.timeoutsMutex.Unlock()
+	if timeouts != nil {
+		if err = s.SetTimeouts(*timeouts); err != nil {
+			return err
+		}
+	}
+	s.client.sessionCreated(s)
+	return nil
+}
+
 type WDASessionInfo struct {
 	Capabilities struct {
 		CFBundleIdentifier string `json:"CFBundleIdentifier"`
@@ -31,11 +253,15 @@ type WDASessionInfo struct {
 		SdkVersion         string `json:"sdkVersion"`
 	} `json:"capabilities"`
 	SessionID string `json:"sessionId"`
-	_string   string
+	raw       json.RawMessage
 }
 
 func (si WDASessionInfo) String() string {
-	return si._string
+	return rawString(si.raw, si)
+}
+
+func (si WDASessionInfo) RawJSON() json.RawMessage {
+	return rawJSON(si.raw, si)
 }
 
 // GetActiveSession
@@ -43,12 +269,11 @@ func (si WDASessionInfo) String() string {
 // get current session information
 func (s *Session) GetActiveSession() (wdaSessionInfo WDASessionInfo, err error) {
 	var wdaResp wdaResponse
-	if wdaResp, err = executeGet("GetActiveSession", urlJoin(s.sessionURL, "")); err != nil {
+	if wdaResp, err = executeGet(s.client, "GetActiveSession", urlJoin(s.sessionURL(), "")); err != nil {
 		return WDASessionInfo{}, err
 	}
 
-	wdaSessionInfo._string = wdaResp.getValue().String()
-	err = json.Unmarshal([]byte(wdaSessionInfo._string), &wdaSessionInfo)
+	wdaSessionInfo.raw, err = wdaResp.unmarshalValue(&wdaSessionInfo)
 	return
 }
 
@@ -58,8 +283,15 @@ func (s *Session) GetActiveSession() (wdaSessionInfo WDASessionInfo, err error)
 //
 //	1. alertsMonitor disable
 //	2. testedApplicationBundleId terminate
+//
+// The requests of this session still in flight are cancelled and fail with ErrSessionClosed, so do the ones sent afterwards.
 func (s *Session) DeleteSession() (err error) {
-	_, err = executeDelete("DeleteSession", s.sessionURL.String())
+	s.close()
+	_, err = executeDelete(s.client, "DeleteSession", s.sessionURL().String())
+	if s.client != nil {
+		s.client.removeSession(s)
+		s.client.sessionDeleted(s)
+	}
 	return
 }
 
@@ -100,17 +332,65 @@ func (alo WDAAppLaunchOption) SetEnvironment(env map[string]string) WDAAppLaunch
 //
 //	1. registerApplicationWithBundleId
 //	2. launch OR activate
+//
+// An application which is not installed fails with ErrAppNotInstalled (wrapped) before WDA is asked to launch it.
 func (s *Session) AppLaunch(bundleId string, opt ...WDAAppLaunchOption) (err error) {
 	// BundleId is required 如果是不存在的 bundleId 会导致 wda 内部报错导致接下来的操作都无法接收处理
+	return s.appLaunch(context.Background(), bundleId, opt...)
+}
+
+func (s *Session) appLaunch(ctx context.Context, bundleId string, opt ...WDAAppLaunchOption) (err error) {
+	if bundleId == "" {
+		return errors.New("'bundleId' is empty")
+	}
+	if len(opt) != 0 {
+		if err = opt[0].Validate(); err != nil {
+			return err
+		}
+	}
+	var state WDAAppRunState
+	if state, err = s.AppState(bundleId); err != nil {
+		return err
+	}
+	if state == WDAAppStateUnknown {
+		return fmt.Errorf("launch '%s': %w", bundleId, ErrAppNotInstalled)
+	}
+
 	if len(opt) == 0 {
 		opt = []WDAAppLaunchOption{NewWDAAppLaunchOption().SetShouldWaitForQuiescence(true)}
 	}
 	body := newWdaBody().setBundleID(bundleId)
 	body.setAppLaunchOption(opt[0])
-	_, err = executePost("AppLaunch", urlJoin(s.sessionURL, "/wda/apps/launch"), body)
+	_, err = executeHTTPContext(ctx, s.client, "AppLaunch", http.MethodPost, urlJoin(s.sessionURL(), "/wda/apps/launch"), body)
+	return appNotInstalledError(bundleId, err)
+}
+
+// AppLaunchUnattached
+//
+// see Client.AppLaunchUnattached, the session stays on its application
+func (s *Session) AppLaunchUnattached(bundleId string) (err error) {
+	if bundleId == "" {
+		return errors.New("'bundleId' is empty")
+	}
+	// a route without session
+	body := newWdaBody().setBundleID(bundleId)
+	_, err = executePost(s.client, "AppLaunchUnattached", urlJoin(s.deviceURL(), "/wda/apps/launchUnattached"), body)
 	return
 }
 
+// deviceURL the session URL without `/session/:sid`
+func (s *Session) deviceURL() *url.URL {
+	if s.client != nil {
+		return s.client.deviceURL
+	}
+	u := *s.sessionURL()
+	if i := strings.LastIndex(u.Path, "/session/"); i >= 0 {
+		u.Path = u.Path[:i]
+	}
+	u.RawPath = ""
+	return &u
+}
+
 // AppTerminate
 //
 // Close the application by bundleId
@@ -118,7 +398,7 @@ func (s *Session) AppLaunch(bundleId string, opt ...WDAAppLaunchOption) (err err
 //	1. unregisterApplicationWithBundleId
 func (s *Session) AppTerminate(bundleId string) (err error) {
 	body := newWdaBody().setBundleID(bundleId)
-	_, err = executePost("AppTerminate", urlJoin(s.sessionURL, "/wda/apps/terminate"), body)
+	_, err = executePost(s.client, "AppTerminate", urlJoin(s.sessionURL(), "/wda/apps/terminate"), body)
 	// "value" : true,
 	// "value" : false,
 	return
@@ -131,7 +411,7 @@ func (s *Session) AppTerminate(bundleId string) (err error) {
 // This method is only supported since Xcode9.
 func (s *Session) AppActivate(bundleId string) (err error) {
 	body := newWdaBody().setBundleID(bundleId)
-	_, err = executePost("AppActivate", urlJoin(s.sessionURL, "/wda/apps/activate"), body)
+	_, err = executePost(s.client, "AppActivate", urlJoin(s.sessionURL(), "/wda/apps/activate"), body)
 	return
 }
 
@@ -143,7 +423,7 @@ func (s *Session) AppDeactivate(seconds ...float64) (err error) {
 		seconds = []float64{3.0}
 	}
 	body := newWdaBody().set("duration", seconds[0])
-	wdaResp, err := executePost("AppDeactivate", urlJoin(s.sessionURL, "/wda/deactivateApp"), body)
+	wdaResp, err := executePost(s.client, "AppDeactivate", urlJoin(s.sessionURL(), "/wda/deactivateApp"), body)
 	if err != nil {
 		return err
 	}
@@ -156,12 +436,12 @@ const (
 )
 
 // sendKeys
-func sendKeys(url string, text string, typingFrequency ...int) (err error) {
+func sendKeys(c *Client, url string, text string, typingFrequency ...int) (err error) {
 	body := newWdaBody().set("value", strings.Split(text, ""))
 	if len(typingFrequency) != 0 {
 		body.set("frequency", typingFrequency[0])
 	}
-	_, err = executePost("SendKeys", url, body)
+	_, err = executePost(c, "SendKeys", url, body)
 	return
 }
 
@@ -169,10 +449,10 @@ func sendKeys(url string, text string, typingFrequency ...int) (err error) {
 //
 // static NSUInteger FBMaxTypingFrequency = 60;
 func (s *Session) SendKeys(text string, typingFrequency ...int) error {
-	return sendKeys(urlJoin(s.sessionURL, "/wda/keys"), text, typingFrequency...)
+	return sendKeys(s.client, urlJoin(s.sessionURL(), "/wda/keys"), text, typingFrequency...)
 }
 
-func tap(baseUrl *url.URL, x, y interface{}, elemUID ...string) (err error) {
+func tap(c *Client, baseUrl *url.URL, x, y interface{}, elemUID ...string) (err error) {
 	body := newWdaBody().setXY(x, y)
 	// [FBRoute POST:@"/wda/tap/:uuid"]
 	tmpPath := "/wda/tap"
@@ -181,30 +461,30 @@ func tap(baseUrl *url.URL, x, y interface{}, elemUID ...string) (err error) {
 	} else {
 		tmpPath += "/" + elemUID[0]
 	}
-	_, err = executePost("Tap", urlJoin(baseUrl, tmpPath), body)
+	_, err = executePost(c, "Tap", urlJoin(baseUrl, tmpPath), body)
 	return
 }
 
 // Tap
 func (s *Session) Tap(x, y int) error {
-	return tap(s.sessionURL, x, y)
+	return tap(s.client, s.sessionURL(), x, y)
 }
 
 // TapFloat
 func (s *Session) TapFloat(x, y float64) error {
-	return tap(s.sessionURL, x, y)
+	return tap(s.client, s.sessionURL(), x, y)
 }
 
 // TapCoordinate
 func (s *Session) TapCoordinate(wdaCoordinate WDACoordinate) error {
-	return tap(s.sessionURL, wdaCoordinate.X, wdaCoordinate.Y)
+	return tap(s.client, s.sessionURL(), wdaCoordinate.X, wdaCoordinate.Y)
 }
 
 // doubleTap
 //
 // [FBRoute POST:@"/wda/doubleTap"]
 // [FBRoute POST:@"/wda/element/:uuid/doubleTap"]
-func doubleTap(baseUrl *url.URL, x, y interface{}, elemPrefixPath ...string) (err error) {
+func doubleTap(c *Client, baseUrl *url.URL, x, y interface{}, elemPrefixPath ...string) (err error) {
 	body := newWdaBody()
 	tmpPath := "/doubleTap"
 	if len(elemPrefixPath) == 0 {
@@ -212,7 +492,7 @@ func doubleTap(baseUrl *url.URL, x, y interface{}, elemPrefixPath ...string) (er
 	} else {
 		tmpPath = elemPrefixPath[0] + tmpPath
 	}
-	_, err = executePost("DoubleTap", urlJoin(baseUrl, tmpPath, true), body)
+	_, err = executePost(c, "DoubleTap", urlJoin(baseUrl, tmpPath, true), body)
 	return
 }
 
@@ -220,18 +500,18 @@ func doubleTap(baseUrl *url.URL, x, y interface{}, elemPrefixPath ...string) (er
 //
 // double tap coordinate
 func (s *Session) DoubleTap(x, y int) (err error) {
-	return doubleTap(s.sessionURL, x, y)
+	return doubleTap(s.client, s.sessionURL(), x, y)
 }
 
 func (s *Session) DoubleTapFloat(x, y float64) (err error) {
-	return doubleTap(s.sessionURL, x, y)
+	return doubleTap(s.client, s.sessionURL(), x, y)
 }
 
 // touchAndHold
 //
 // [FBRoute POST:@"/wda/touchAndHold"]
 // [FBRoute POST:@"/wda/element/:uuid/touchAndHold"]
-func touchAndHold(baseUrl *url.URL, x, y, duration interface{}, elemPrefixPath ...string) (err error) {
+func touchAndHold(c *Client, baseUrl *url.URL, x, y, duration interface{}, elemPrefixPath ...string) (err error) {
 	body := newWdaBody().set("duration", duration)
 	tmpPath := "/touchAndHold"
 	if len(elemPrefixPath) == 0 {
@@ -239,7 +519,7 @@ func touchAndHold(baseUrl *url.URL, x, y, duration interface{}, elemPrefixPath .
 	} else {
 		tmpPath = elemPrefixPath[0] + tmpPath
 	}
-	_, err = executePost("TouchAndHold", urlJoin(baseUrl, tmpPath, true), body)
+	_, err = executePost(c, "TouchAndHold", urlJoin(baseUrl, tmpPath, true), body)
 	return
 }
 
@@ -250,20 +530,23 @@ func (s *Session) TouchAndHold(x, y int, duration ...int) (err error) {
 	if len(duration) == 0 {
 		duration = []int{1}
 	}
-	return touchAndHold(s.sessionURL, x, y, duration[0])
+	return touchAndHold(s.client, s.sessionURL(), x, y, duration[0])
 }
 
 func (s *Session) TouchAndHoldFloat(x, y float64, duration ...float64) (err error) {
 	if len(duration) == 0 {
 		duration = []float64{1.0}
 	}
-	return touchAndHold(s.sessionURL, x, y, duration[0])
+	return touchAndHold(s.client, s.sessionURL(), x, y, duration[0])
 }
 
 func (s *Session) _forceTouch(x, y interface{}, pressure float64, duration ...float64) (err error) {
 	if len(duration) == 0 {
 		duration = []float64{1.0}
 	}
+	if err = validateForceTouch(pressure, duration[0]); err != nil {
+		return err
+	}
 	touchActions := NewWDATouchActions().
 		Press(
 			NewWDATouchActionOptionPress().
@@ -274,6 +557,9 @@ func (s *Session) _forceTouch(x, y interface{}, pressure float64, duration ...fl
 	return s.PerformTouchActions(touchActions)
 }
 
+// ForceTouch
+//
+// 3D Touch at the coordinate, see Element.ForceTouch
 func (s *Session) ForceTouch(x, y int, pressure float64, duration ...float64) (err error) {
 	return s._forceTouch(x, y, pressure, duration...)
 }
@@ -290,7 +576,7 @@ func (s *Session) ForceTouchCoordinate(coordinate WDACoordinate, pressure float6
 //
 // [FBRoute POST:@"/wda/dragfromtoforduration"]
 // [FBRoute POST:@"/wda/element/:uuid/dragfromtoforduration"]
-func drag(baseUrl *url.URL, fromX, fromY, toX, toY, pressForDuration interface{}, elemPrefixPath ...string) (err error) {
+func drag(c *Client, baseUrl *url.URL, fromX, fromY, toX, toY, pressForDuration interface{}, elemPrefixPath ...string) (err error) {
 	body := newWdaBody().set("duration", pressForDuration)
 	body.set("fromX", fromX).set("fromY", fromY)
 	body.set("toX", toX).set("toY", toY)
@@ -298,7 +584,7 @@ func drag(baseUrl *url.URL, fromX, fromY, toX, toY, pressForDuration interface{}
 	if len(elemPrefixPath) != 0 {
 		tmpPath = elemPrefixPath[0] + tmpPath
 	}
-	_, err = executePost("Drag", urlJoin(baseUrl, tmpPath, true), body)
+	_, err = executePost(c, "Drag", urlJoin(baseUrl, tmpPath, true), body)
 	return
 }
 
@@ -309,26 +595,26 @@ func (s *Session) Drag(fromX, fromY, toX, toY int, pressForDuration ...int) (err
 	if len(pressForDuration) == 0 {
 		pressForDuration = []int{1}
 	}
-	return drag(s.sessionURL, fromX, fromY, toX, toY, pressForDuration[0])
+	return drag(s.client, s.sessionURL(), fromX, fromY, toX, toY, pressForDuration[0])
 }
 
 func (s *Session) DragFloat(fromX, fromY, toX, toY float64, pressForDuration ...float64) (err error) {
 	if len(pressForDuration) == 0 {
 		pressForDuration = []float64{1}
 	}
-	return drag(s.sessionURL, fromX, fromY, toX, toY, pressForDuration[0])
+	return drag(s.client, s.sessionURL(), fromX, fromY, toX, toY, pressForDuration[0])
 }
 
 func (s *Session) Swipe(fromX, fromY, toX, toY int) (err error) {
-	return drag(s.sessionURL, fromX, fromY, toX, toY, 0)
+	return drag(s.client, s.sessionURL(), fromX, fromY, toX, toY, 0)
 }
 
 func (s *Session) SwipeFloat(fromX, fromY, toX, toY float64) (err error) {
-	return drag(s.sessionURL, fromX, fromY, toX, toY, 0)
+	return drag(s.client, s.sessionURL(), fromX, fromY, toX, toY, 0)
 }
 
 func (s *Session) SwipeCoordinate(fromCoordinate, toCoordinate WDACoordinate) (err error) {
-	return drag(s.sessionURL, fromCoordinate.X, fromCoordinate.Y, toCoordinate.X, toCoordinate.Y, 0)
+	return drag(s.client, s.sessionURL(), fromCoordinate.X, fromCoordinate.Y, toCoordinate.X, toCoordinate.Y, 0)
 }
 
 func (s *Session) _getCenterCoordinates() (c WDACoordinate, err error) {
@@ -406,7 +692,7 @@ func (s *Session) SetPasteboard(contentType WDAContentType, content string) (err
 	body.set("contentType", contentType)
 	body.set("content", base64.StdEncoding.EncodeToString([]byte(content)))
 
-	_, err = executePost("SetPasteboard", urlJoin(s.sessionURL, "/wda/setPasteboard"), body)
+	_, err = executePost(s.client, "SetPasteboard", urlJoin(s.sessionURL(), "/wda/setPasteboard"), body)
 	return
 }
 
@@ -431,13 +717,39 @@ func (s *Session) SetPasteboardForUrl(url string) (err error) {
 
 // GetPasteboard
 //
-// It might work when `WebDriverAgentRunner` is in foreground on real devices.
+// It only works when `WebDriverAgentRunner` is in foreground on real devices.
 // https://github.com/appium/WebDriverAgent/issues/330
+//
+// See SetPasteboardCompanion to bring it to the foreground automatically. Without a companion the read has no side effect,
+// unless SetPasteboardRetryEmpty: an empty pasteboard is then read once more with DefaultPasteboardCompanion in the foreground.
 func (s *Session) GetPasteboard(contentType WDAContentType) (raw *bytes.Buffer, err error) {
+	read := func() error {
+		raw, err = getPasteboard(s.client, s.sessionURL(), contentType)
+		return err
+	}
+	companion := s.getPasteboardCompanion()
+	if err = s.withPasteboardCompanion(companion, read); err != nil || companion != "" || raw.Len() != 0 {
+		return
+	}
+	if atomic.LoadInt32(&s.pasteboardRetryEmpty) == 0 || DefaultPasteboardCompanion == "" {
+		return
+	}
+	debugLog(fmt.Sprintf("pasteboard is empty, reading it again with '%s' in the foreground", DefaultPasteboardCompanion))
+	var empty *bytes.Buffer
+	empty, raw = raw, nil
+	if err = s.withPasteboardCompanion(DefaultPasteboardCompanion, read); err != nil {
+		// e.g. WDA was signed with another bundle id, the first read stands
+		debugLog(fmt.Sprintf("pasteboard: %s", err))
+		return empty, nil
+	}
+	return
+}
+
+func getPasteboard(c *Client, baseUrl *url.URL, contentType WDAContentType) (raw *bytes.Buffer, err error) {
 	var wdaResp wdaResponse
 	body := newWdaBody().set("contentType", contentType)
 	// [FBRoute POST:@"/wda/getPasteboard"]
-	if wdaResp, err = executePost("GetPasteboard", urlJoin(s.sessionURL, "/wda/getPasteboard"), body); err != nil {
+	if wdaResp, err = executePost(c, "GetPasteboard", urlJoin(baseUrl, "/wda/getPasteboard"), body); err != nil {
 		return nil, err
 	}
 	if decodeString, err := base64.StdEncoding.DecodeString(wdaResp.getValue().String()); err != nil {
@@ -496,7 +808,7 @@ const (
 // !!! not a synchronous action
 func (s *Session) PressButton(wdaDeviceButton WDADeviceButtonName) (err error) {
 	body := newWdaBody().set("name", wdaDeviceButton)
-	_, err = executePost("PressButton", urlJoin(s.sessionURL, "/wda/pressButton"), body)
+	_, err = executePost(s.client, "PressButton", urlJoin(s.sessionURL(), "/wda/pressButton"), body)
 	return
 }
 
@@ -517,103 +829,221 @@ func (s *Session) PressVolumeDownButton() (err error) {
 // Activates Siri service voice recognition with the given text to parse
 func (s *Session) SiriActivate(text string) (err error) {
 	body := newWdaBody().set("text", text)
-	_, err = executePost("SiriActivate", urlJoin(s.sessionURL, "/wda/siri/activate"), body)
+	_, err = executePost(s.client, "SiriActivate", urlJoin(s.sessionURL(), "/wda/siri/activate"), body)
 	return
 }
 
 // SiriOpenURL Open {%@}
-// It doesn't actually work, right?
+//
+// Deprecated: use OpenURL, which waits for the application and falls back to Siri
 func (s *Session) SiriOpenURL(url string) (err error) {
 	body := newWdaBody().set("url", url)
-	_, err = executePost("SiriOpenURL", urlJoin(s.sessionURL, "/url"), body)
+	_, err = executePost(s.client, "SiriOpenURL", urlJoin(s.sessionURL(), "/url"), body)
 	return
 }
 
-func findUidOfElement(baseUrl *url.URL, wdaLocator WDALocator) (elemUID string, err error) {
+func findUidOfElement(c *Client, baseUrl *url.URL, wdaLocator WDALocator) (elemUID string, err error) {
 	using, value := wdaLocator.getUsingAndValue()
 	body := newWdaBody().set("using", using).set("value", value)
 	var wdaResp wdaResponse
-	if wdaResp, err = executePost("FindElement", urlJoin(baseUrl, "/element"), body); err != nil {
+	if wdaResp, err = executePost(c, "FindElement", urlJoin(baseUrl, "/element"), body); err != nil {
 		return "", err
 	}
-	return wdaResp.getValue().Get("ELEMENT").String(), nil
+	var ref wdaElementRef
+	if _, err = wdaResp.unmarshalValue(&ref); err != nil {
+		return "", err
+	}
+	return ref.uid(), nil
 }
 
 // FindElement
+//
+// waits for the element up to the implicit timeout, see SetTimeouts. See SetElementCache to reuse the found elements
 func (s *Session) FindElement(wdaLocator WDALocator) (element *Element, err error) {
+	if element = s.cachedElement(wdaLocator); element != nil {
+		return element, nil
+	}
 	var elemUID string
-	if elemUID, err = findUidOfElement(s.sessionURL, wdaLocator); err != nil {
+	if err = s.implicitWait(func() (err error) {
+		elemUID, err = findUidOfElement(s.client, s.sessionURL(), wdaLocator)
+		return
+	}); err != nil {
 		return nil, err
 	}
-	return newElement(s.sessionURL, elemUID), nil
+	element = newElement(s.client, s.sessionURL(), elemUID)
+	s.cacheElement(wdaLocator, element)
+	return
 }
 
-func findUidOfElements(baseUrl *url.URL, wdaLocator WDALocator) (elemUIDs []string, err error) {
+func findUidOfElements(c *Client, baseUrl *url.URL, wdaLocator WDALocator) (elemUIDs []string, err error) {
 	using, value := wdaLocator.getUsingAndValue()
 	body := newWdaBody().set("using", using).set("value", value)
 	var wdaResp wdaResponse
-	if wdaResp, err = executePost("FindElements", urlJoin(baseUrl, "/elements"), body); err != nil {
+	if wdaResp, err = executePost(c, "FindElements", urlJoin(baseUrl, "/elements"), body); err != nil {
+		return nil, err
+	}
+	var results []wdaElementRef
+	if _, err = wdaResp.unmarshalValue(&results); err != nil {
 		return nil, err
 	}
-	results := wdaResp.getValue().Array()
 	if len(results) == 0 {
-		return nil, fmt.Errorf("no such element: unable to find an element using '%s', value '%s'", using, value)
+		return nil, fmt.Errorf("%w: unable to find an element using '%s', value '%s'", ErrNoSuchElement, using, value)
 	}
 	elemUIDs = make([]string, len(results))
 	for i := range elemUIDs {
-		elemUIDs[i] = results[i].Get("ELEMENT").String()
+		elemUIDs[i] = results[i].uid()
 	}
 	return
 }
 
 // FindElements
+//
+// waits for the elements up to the implicit timeout, see SetTimeouts
 func (s *Session) FindElements(wdaLocator WDALocator) (elements []*Element, err error) {
 	var elemUIDs []string
-	if elemUIDs, err = findUidOfElements(s.sessionURL, wdaLocator); err != nil {
+	if err = s.implicitWait(func() (err error) {
+		elemUIDs, err = findUidOfElements(s.client, s.sessionURL(), wdaLocator)
+		return
+	}); err != nil {
 		return nil, err
 	}
 	elements = make([]*Element, len(elemUIDs))
 	for i := range elements {
-		elements[i] = newElement(s.sessionURL, elemUIDs[i])
+		elements[i] = newElement(s.client, s.sessionURL(), elemUIDs[i])
 	}
 	return
 }
 
+// CountElements
+//
+// The number of elements matching `wdaLocator`, without creating them. No match is `0`, the implicit timeout is not applied.
+func (s *Session) CountElements(wdaLocator WDALocator) (int, error) {
+	return countElements(s.client, s.sessionURL(), wdaLocator)
+}
+
+func countElements(c *Client, baseUrl *url.URL, wdaLocator WDALocator) (count int, err error) {
+	using, value := wdaLocator.getUsingAndValue()
+	if using == "" {
+		return 0, errors.New("'WDALocator' is empty")
+	}
+	body := newWdaBody().set("using", using).set("value", value)
+	var wdaResp wdaResponse
+	if wdaResp, err = executePost(c, "CountElements", urlJoin(baseUrl, "/elements"), body); err != nil {
+		return 0, err
+	}
+	if !wdaResp.getValue().IsArray() {
+		return 0, fmt.Errorf("CountElements: unexpected value %s", wdaResp.getValue().Raw)
+	}
+	return int(wdaResp.getByPath("value.#").Int()), nil
+}
+
+// FindNth
+//
+// The nth (from 1, negative from the end) element matching `wdaLocator`. A class chain index is used when the locator
+// can be expressed as one (class name, predicate, name, a class chain of a single `**/` segment), so only that element
+// is returned by WDA, otherwise all the matches are fetched.
+func (s *Session) FindNth(wdaLocator WDALocator, nth int) (element *Element, err error) {
+	if nth == 0 {
+		return nil, errors.New("'nth' starts at 1")
+	}
+	if locator, ok := nthLocator(wdaLocator, nth); ok {
+		return s.FindElement(locator)
+	}
+	var elements []*Element
+	if elements, err = s.FindElements(wdaLocator); err != nil {
+		return nil, err
+	}
+	i := nth - 1
+	if nth < 0 {
+		i = len(elements) + nth
+	}
+	if i < 0 || i >= len(elements) {
+		using, value := wdaLocator.getUsingAndValue()
+		return nil, fmt.Errorf("%w: %d matches using '%s', value '%s', not %d", ErrNoSuchElement, len(elements), using, value, nth)
+	}
+	return elements[i], nil
+}
+
+// FindElementAt
+//
+// FindNth with an index from 0
+func (s *Session) FindElementAt(wdaLocator WDALocator, index int) (*Element, error) {
+	if index < 0 {
+		return nil, errors.New("'index' must not be negative")
+	}
+	return s.FindNth(wdaLocator, index+1)
+}
+
+// ErrNoActiveElement no element has the keyboard focus
+var ErrNoActiveElement = errors.New("no active element")
+
 // ActiveElement
 //
-// returns the currently active element
+// returns the currently active element, e.g. the field the keyboard types into.
+// Fails with ErrNoActiveElement (or `no such element`, depending on the version of WDA) when nothing has the focus.
 //
 // [NSPredicate predicateWithFormat:@"hasKeyboardFocus == YES"]
 func (s *Session) ActiveElement() (element *Element, err error) {
 	var wdaResp wdaResponse
-	if wdaResp, err = executeGet("ActiveElement", urlJoin(s.sessionURL, "/element/active")); err != nil {
+	if wdaResp, err = executeGet(s.client, "ActiveElement", urlJoin(s.sessionURL(), "/element/active")); err != nil {
+		return nil, err
+	}
+	elemUID := elementUIDOf(wdaResp.getValue())
+	if elemUID == "" {
+		return nil, ErrNoActiveElement
+	}
+	element = newElement(s.client, s.sessionURL(), elemUID)
+	return
+}
+
+// WaitForActiveElement
+//
+// Polls ActiveElement every `interval` (DefaultWaitInterval when `<= 0`) until an element has the focus,
+// e.g. right after tapping a field, before the keyboard is up.
+//
+//	elem, err := s.WaitForActiveElement(5*time.Second, 0)
+//	err = elem.SendKeys("hello")
+func (s *Session) WaitForActiveElement(timeout, interval time.Duration) (element *Element, err error) {
+	if interval <= 0 {
+		interval = waitInterval()
+	}
+	err = s._waitWithTimeoutAndInterval(func(s *Session) (bool, error) {
+		var err error
+		if element, err = s.ActiveElement(); err != nil {
+			if errors.Is(err, ErrNoActiveElement) || isNoSuchElement(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}, timeout, interval)
+	if err != nil {
 		return nil, err
 	}
-	element = newElement(s.sessionURL, wdaResp.getValue().Get("ELEMENT").String())
 	return
 }
 
 func (s *Session) AlertSendKeys(text string) (err error) {
 	// [FBRoute POST:@"/alert/text"]
-	return sendKeys(urlJoin(s.sessionURL, "/alert/text"), text)
+	return sendKeys(s.client, urlJoin(s.sessionURL(), "/alert/text"), text)
 }
 
 func (s *Session) AlertAccept(label ...string) (err error) {
-	return alertAccept(s.sessionURL, label...)
+	return alertAccept(s.client, s.sessionURL(), label...)
 }
 
 func (s *Session) AlertDismiss(label ...string) (err error) {
-	return alertDismiss(s.sessionURL, label...)
+	return alertDismiss(s.client, s.sessionURL(), label...)
 }
 
 func (s *Session) AlertText() (text string, err error) {
-	return alertText(s.sessionURL)
+	return alertText(s.client, s.sessionURL())
 }
 
 func (s *Session) AlertButtons() (buttons []string, err error) {
 	var wdaResp wdaResponse
 	// [FBRoute GET:@"/wda/alert/buttons"]
-	if wdaResp, err = executeGet("AlertButtons", urlJoin(s.sessionURL, "/wda/alert/buttons")); err != nil {
+	if wdaResp, err = executeGet(s.client, "AlertButtons", urlJoin(s.sessionURL(), "/wda/alert/buttons")); err != nil {
 		return nil, err
 	}
 	results := wdaResp.getValue().Array()
@@ -651,7 +1081,7 @@ func (v WDAOrientation) String() string {
 func (s *Session) Orientation() (orientation WDAOrientation, err error) {
 	var wdaResp wdaResponse
 	// [FBRoute GET:@"/orientation"]
-	if wdaResp, err = executeGet("Orientation", urlJoin(s.sessionURL, "/orientation")); err != nil {
+	if wdaResp, err = executeGet(s.client, "Orientation", urlJoin(s.sessionURL(), "/orientation")); err != nil {
 		return "", err
 	}
 	return WDAOrientation(wdaResp.getValue().String()), nil
@@ -660,29 +1090,33 @@ func (s *Session) Orientation() (orientation WDAOrientation, err error) {
 func (s *Session) SetOrientation(orientation WDAOrientation) (err error) {
 	body := newWdaBody().set("orientation", orientation)
 	// [FBRoute POST:@"/orientation"]
-	_, err = executePost("SetOrientation", urlJoin(s.sessionURL, "/orientation"), body)
+	_, err = executePost(s.client, "SetOrientation", urlJoin(s.sessionURL(), "/orientation"), body)
+	s.RefreshInfoCache()
 	return
 }
 
 type WDARotation struct {
-	X       int `json:"x"`
-	Y       int `json:"y"`
-	Z       int `json:"z"`
-	_string string
+	X   int `json:"x"`
+	Y   int `json:"y"`
+	Z   int `json:"z"`
+	raw json.RawMessage
 }
 
 func (r WDARotation) String() string {
-	return r._string
+	return rawString(r.raw, r)
+}
+
+func (r WDARotation) RawJSON() json.RawMessage {
+	return rawJSON(r.raw, r)
 }
 
 func (s *Session) Rotation() (wdaRotation WDARotation, err error) {
 	var wdaResp wdaResponse
 	// [FBRoute GET:@"/rotation"]
-	if wdaResp, err = executeGet("Rotation", urlJoin(s.sessionURL, "/rotation")); err != nil {
+	if wdaResp, err = executeGet(s.client, "Rotation", urlJoin(s.sessionURL(), "/rotation")); err != nil {
 		return WDARotation{}, err
 	}
-	wdaRotation._string = wdaResp.getValue().String()
-	err = json.Unmarshal([]byte(wdaRotation._string), &wdaRotation)
+	wdaRotation.raw, err = wdaResp.unmarshalValue(&wdaRotation)
 	return
 }
 
@@ -691,7 +1125,8 @@ func (s *Session) SetRotation(wdaRotation WDARotation) (err error) {
 	body.setXY(wdaRotation.X, wdaRotation.Y)
 	body.set("z", wdaRotation.Z)
 	// [FBRoute POST:@"/rotation"]
-	_, err = executePost("SetRotation", urlJoin(s.sessionURL, "/rotation"), body)
+	_, err = executePost(s.client, "SetRotation", urlJoin(s.sessionURL(), "/rotation"), body)
+	s.RefreshInfoCache()
 	return
 }
 
@@ -718,7 +1153,7 @@ func (s *Session) PerformTouchActions(touchActions *WDATouchActions) (err error)
 	body := newWdaBody().set("actions", touchActions)
 	// [FBRoute POST:@"/wda/touch/perform"]
 	// [FBRoute POST:@"/wda/touch/multi/perform"]
-	_, err = executePost("PerformTouchActions", urlJoin(s.sessionURL, "/wda/touch/multi/perform"), body)
+	_, err = executePost(s.client, "PerformTouchActions", urlJoin(s.sessionURL(), "/wda/touch/multi/perform"), body)
 	return
 }
 
@@ -858,10 +1293,10 @@ func (ta *WDATouchActions) Cancel() *WDATouchActions {
 // ██ ███ ██      ██ ██          ██   ██ ██         ██    ██ ██    ██ ██  ██ ██      ██
 //  ███ ███  ██████   ██████     ██   ██  ██████    ██    ██  ██████  ██   ████ ███████
 
-func performActions(baseUrl *url.URL, actions *WDAActions) (err error) {
+func performActions(c *Client, baseUrl *url.URL, actions *WDAActions) (err error) {
 	body := newWdaBody().set("actions", actions)
 	// [FBRoute POST:@"/actions"]
-	_, err = executePost("PerformActions", urlJoin(baseUrl, "/actions"), body)
+	_, err = executePost(c, "PerformActions", urlJoin(baseUrl, "/actions"), body)
 	return
 }
 
@@ -879,7 +1314,7 @@ func NewWDAActions(cap ...int) *WDAActions {
 //
 // fb_performW3CActions
 func (s *Session) PerformActions(actions *WDAActions) (err error) {
-	return performActions(s.sessionURL, actions)
+	return performActions(s.client, s.sessionURL(), actions)
 }
 
 type WDAActionOptionFinger []wdaBody
@@ -913,6 +1348,34 @@ func (ofm WDAActionOptionFingerMove) SetDuration(duration float64) WDAActionOpti
 	return WDAActionOptionFingerMove(wdaBody(ofm).set("duration", duration))
 }
 
+// SetPressure
+//
+// normalized pressure in [0, 1] while the pointer is down, e.g. of an Apple Pencil (see PenActionOption)
+func (ofm WDAActionOptionFingerMove) SetPressure(pressure float64) WDAActionOptionFingerMove {
+	return WDAActionOptionFingerMove(wdaBody(ofm).set("pressure", clampPressure(pressure)))
+}
+
+// SetTilt
+//
+// angles in degrees in [-90, 90] between the pen and the screen, along the X and Y axes
+func (ofm WDAActionOptionFingerMove) SetTilt(tiltX, tiltY int) WDAActionOptionFingerMove {
+	return WDAActionOptionFingerMove(wdaBody(ofm).set("tiltX", clampTilt(tiltX)).set("tiltY", clampTilt(tiltY)))
+}
+
+func clampPressure(pressure float64) float64 {
+	return math.Max(0, math.Min(1, pressure))
+}
+
+func clampTilt(tilt int) int {
+	if tilt < -90 {
+		return -90
+	}
+	if tilt > 90 {
+		return 90
+	}
+	return tilt
+}
+
 func (aof *WDAActionOptionFinger) Move(ofm WDAActionOptionFingerMove) *WDAActionOptionFinger {
 	*aof = append(*aof, wdaBody(ofm))
 	return aof
@@ -921,6 +1384,14 @@ func (aof *WDAActionOptionFinger) Down() *WDAActionOptionFinger {
 	*aof = append(*aof, newWdaBody().set("type", "pointerDown"))
 	return aof
 }
+
+// DownWithPressure
+//
+// see WDAActionOptionFingerMove.SetPressure
+func (aof *WDAActionOptionFinger) DownWithPressure(pressure float64) *WDAActionOptionFinger {
+	*aof = append(*aof, newWdaBody().set("type", "pointerDown").set("pressure", clampPressure(pressure)))
+	return aof
+}
 func (aof *WDAActionOptionFinger) Up() *WDAActionOptionFinger {
 	*aof = append(*aof, newWdaBody().set("type", "pointerUp"))
 	return aof
@@ -969,6 +1440,31 @@ func (act *WDAActions) FingerActionOption(actOptFinger *WDAActionOptionFinger) *
 	return act
 }
 
+func (act *WDAActions) _newTypeForPen() wdaBody {
+	pointer := newWdaBody().set("type", "pointer")
+	pointer.set("id", "pen"+strconv.FormatInt(int64(len(*act)+1), 10))
+	pointer.set("parameters", newWdaBody().set("pointerType", "pen"))
+	return pointer
+}
+
+// PenActionOption
+//
+// Same as FingerActionOption with an Apple Pencil on iPad (`pointerType` `pen`), the pressure and tilt of its moves
+// and presses are sent along. Only WDA builds supporting the pen accept it, the others reject the whole actions.
+//
+//	pen := NewWDAActionOptionFinger().
+//		Move(NewWWDAActionOptionFingerMove().SetXY(100, 100)).
+//		DownWithPressure(0.3).
+//		Move(NewWWDAActionOptionFingerMove().SetXY(300, 120).SetPressure(0.8).SetTilt(30, 0).SetDuration(500)).
+//		Up()
+//	err := s.PerformActions(NewWDAActions().PenActionOption(pen))
+func (act *WDAActions) PenActionOption(actOptPen *WDAActionOptionFinger) *WDAActions {
+	pointer := act._newTypeForPen()
+	pointer.set("actions", *actOptPen)
+	*act = append(*act, pointer)
+	return act
+}
+
 func (act *WDAActions) Tap(x, y int, element ...*Element) *WDAActions {
 	optMove := NewWWDAActionOptionFingerMove().SetXY(x, y)
 	if len(element) != 0 {
@@ -1051,7 +1547,7 @@ func (act *WDAActions) SwipeCoordinate(fromCoordinate, toCoordinate WDACoordinat
 func (s *Session) MatchTouchID(isMatch bool) (bool, error) {
 	body := newWdaBody().set("match", isMatch)
 	// [FBRoute POST:@"/wda/touch_id"]
-	wdaResp, err := executePost("MatchTouchID", urlJoin(s.sessionURL, "/wda/touch_id"), body)
+	wdaResp, err := executePost(s.client, "MatchTouchID", urlJoin(s.sessionURL(), "/wda/touch_id"), body)
 	return wdaResp.getValue().Bool(), err
 }
 
@@ -1059,7 +1555,7 @@ func (s *Session) MatchTouchID(isMatch bool) (bool, error) {
 //
 // get current active application
 func (s *Session) ActiveAppInfo() (wdaActiveAppInfo WDAActiveAppInfo, err error) {
-	return activeAppInfo(s.sessionURL)
+	return activeAppInfo(s.client, s.sessionURL())
 }
 
 // ActiveAppsList
@@ -1067,11 +1563,11 @@ func (s *Session) ActiveAppInfo() (wdaActiveAppInfo WDAActiveAppInfo, err error)
 // use multitasking on iPad
 func (s *Session) ActiveAppsList() (appsList []WDAAppBaseInfo, err error) {
 	var wdaResp wdaResponse
-	if wdaResp, err = executeGet("ActiveAppsList", urlJoin(s.sessionURL, "/wda/apps/list")); err != nil {
+	if wdaResp, err = executeGet(s.client, "ActiveAppsList", urlJoin(s.sessionURL(), "/wda/apps/list")); err != nil {
 		return nil, err
 	}
 	appsList = make([]WDAAppBaseInfo, 0)
-	err = json.Unmarshal([]byte(wdaResp.getValue().String()), &appsList)
+	_, err = wdaResp.unmarshalValue(&appsList)
 	return
 }
 
@@ -1083,6 +1579,9 @@ const (
 	WDAAppRunningFront
 )
 
+// WDAAppStateUnknown e.g. the application is not installed
+const WDAAppStateUnknown WDAAppRunState = 0
+
 func (v WDAAppRunState) String() string {
 	switch v {
 	case WDAAppNotRunning:
@@ -1103,7 +1602,7 @@ func (v WDAAppRunState) String() string {
 func (s *Session) AppState(bundleId string) (appRunState WDAAppRunState, err error) {
 	body := newWdaBody().setBundleID(bundleId)
 	var wdaResp wdaResponse
-	if wdaResp, err = executePost("AppState", urlJoin(s.sessionURL, "/wda/apps/state"), body); err != nil {
+	if wdaResp, err = executePost(s.client, "AppState", urlJoin(s.sessionURL(), "/wda/apps/state"), body); err != nil {
 		return -1, err
 	}
 	return WDAAppRunState(wdaResp.getValue().Int()), nil
@@ -1118,26 +1617,106 @@ type WDADeviceInfo struct {
 	UserInterfaceStyle string `json:"userInterfaceStyle"`
 	Name               string `json:"name"`
 	IsSimulator        bool   `json:"isSimulator"`
-	_string            string
+	// only reported by newer WDA builds, otherwise `WDAThermalStateUnknown`
+	ThermalState WDAThermalState `json:"thermalState"`
+	Origin       WDAOrigin       `json:"-"`
+	raw          json.RawMessage
 }
 
 func (di WDADeviceInfo) String() string {
-	return di._string
+	return rawString(di.raw, di)
+}
+
+func (di WDADeviceInfo) RawJSON() json.RawMessage {
+	return rawJSON(di.raw, di)
+}
+
+// Field
+//
+// Returns a field which is not mapped by WDADeviceInfo (e.g. carrier info reported by customized WDA builds),
+// `ok` is `false` when the WDA build does not provide it.
+func (di WDADeviceInfo) Field(key string) (value string, ok bool) {
+	result := gjson.GetBytes(di.raw, key)
+	return result.String(), result.Exists()
+}
+
+// TimeZoneOffset
+//
+// current UTC offset of the device time zone, the host needs the IANA time zone database
+func (di WDADeviceInfo) TimeZoneOffset() (offset time.Duration, err error) {
+	if di.TimeZone == "" {
+		return 0, errors.New("time zone is not provided")
+	}
+	var loc *time.Location
+	if loc, err = time.LoadLocation(di.TimeZone); err != nil {
+		return 0, err
+	}
+	_, seconds := time.Now().In(loc).Zone()
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// LocaleScript
+//
+// the script subtag of `currentLocale`, e.g. `Hans` of `zh-Hans_CN`, empty when the locale has none
+func (di WDADeviceInfo) LocaleScript() string {
+	subtags := strings.FieldsFunc(di.CurrentLocale, func(r rune) bool {
+		return r == '_' || r == '-' || r == '@'
+	})
+	for i := 1; i < len(subtags); i++ {
+		if len(subtags[i]) == 4 && unicode.IsUpper(rune(subtags[i][0])) {
+			return subtags[i]
+		}
+	}
+	return ""
+}
+
+// WDAThermalState NSProcessInfoThermalState
+type WDAThermalState int
+
+const (
+	WDAThermalStateUnknown  WDAThermalState = -1
+	WDAThermalStateNominal  WDAThermalState = 0
+	WDAThermalStateFair     WDAThermalState = 1
+	WDAThermalStateSerious  WDAThermalState = 2
+	WDAThermalStateCritical WDAThermalState = 3
+)
+
+func (v WDAThermalState) String() string {
+	switch v {
+	case WDAThermalStateNominal:
+		return "Nominal"
+	case WDAThermalStateFair:
+		return "Fair"
+	case WDAThermalStateSerious:
+		return "Serious"
+	case WDAThermalStateCritical:
+		return "Critical"
+	default:
+		return "UNKNOWN"
+	}
 }
 
 // DeviceInfo
 func (s *Session) DeviceInfo() (wdaDeviceInfo WDADeviceInfo, err error) {
-	return deviceInfo(s.sessionURL)
+	if wdaDeviceInfo, err = s.cachedDeviceInfo(); err == nil {
+		wdaDeviceInfo.Origin = s.Origin()
+	}
+	return
 }
 
 type WDABatteryInfo struct {
-	Level   float64         `json:"level"` // Battery level in range [0.0, 1.0], where 1.0 means 100% charge.
-	State   WDABatteryState `json:"state"` // Battery state ( 1: on battery, discharging; 2: plugged in, less than 100%, 3: plugged in, at 100% )
-	_string string
+	Level  float64         `json:"level"` // Battery level in range [0.0, 1.0], where 1.0 means 100% charge.
+	State  WDABatteryState `json:"state"` // Battery state ( 1: on battery, discharging; 2: plugged in, less than 100%, 3: plugged in, at 100% )
+	Origin WDAOrigin       `json:"-"`
+	raw    json.RawMessage
 }
 
 func (bi WDABatteryInfo) String() string {
-	return bi._string
+	return rawString(bi.raw, bi)
+}
+
+func (bi WDABatteryInfo) RawJSON() json.RawMessage {
+	return rawJSON(bi.raw, bi)
 }
 
 type WDABatteryState int
@@ -1171,13 +1750,12 @@ func (v WDABatteryState) String() string {
 //	UIDeviceBatteryStateFull = 3       // plugged in, at 100%
 func (s *Session) BatteryInfo() (wdaBatteryInfo WDABatteryInfo, err error) {
 	var wdaResp wdaResponse
-	if wdaResp, err = executeGet("BatteryInfo", urlJoin(s.sessionURL, "/wda/batteryInfo")); err != nil {
+	if wdaResp, err = executeGet(s.client, "BatteryInfo", urlJoin(s.sessionURL(), "/wda/batteryInfo")); err != nil {
 		return
 	}
 
-	wdaBatteryInfo._string = wdaResp.getValue().String()
-	err = json.Unmarshal([]byte(wdaBatteryInfo._string), &wdaBatteryInfo)
-	// err = json.Unmarshal(wdaResp.getValue2Bytes(), &wdaBatteryInfo)
+	wdaBatteryInfo.raw, err = wdaResp.unmarshalValue(&wdaBatteryInfo)
+	wdaBatteryInfo.Origin = s.Origin()
 	return
 }
 
@@ -1186,47 +1764,55 @@ func (s *Session) BatteryInfo() (wdaBatteryInfo WDABatteryInfo, err error) {
 // CGRect frame = request.session.activeApplication.wdFrame;
 func (s *Session) WindowSize() (wdaSize WDASize, err error) {
 	var wdaResp wdaResponse
-	if wdaResp, err = executeGet("WindowSize", urlJoin(s.sessionURL, "/window/size")); err != nil {
+	if wdaResp, err = executeGet(s.client, "WindowSize", urlJoin(s.sessionURL(), "/window/size")); err != nil {
 		return
 	}
 
-	wdaSize._string = wdaResp.getValue().String()
-	err = json.Unmarshal([]byte(wdaSize._string), &wdaSize)
-	// err = json.Unmarshal(wdaResp.getValue2Bytes(), &wdaSize)
+	wdaSize.raw, err = wdaResp.unmarshalValue(&wdaSize)
 	return
 }
 
 type WDASize struct {
-	Width   int `json:"width"`
-	Height  int `json:"height"`
-	_string string
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	raw    json.RawMessage
 }
 
 func (s WDASize) String() string {
-	return s._string
+	return rawString(s.raw, s)
+}
+
+func (s WDASize) RawJSON() json.RawMessage {
+	return rawJSON(s.raw, s)
 }
 
 type WDAScreen struct {
 	StatusBarSize WDASize `json:"statusBarSize"`
 	Scale         float64 `json:"scale"`
-	_string       string
+	raw           json.RawMessage
 }
 
 func (s WDAScreen) String() string {
-	return s._string
+	return rawString(s.raw, s)
+}
+
+func (s WDAScreen) RawJSON() json.RawMessage {
+	return rawJSON(s.raw, s)
 }
 
 // Screen
 func (s *Session) Screen() (wdaScreen WDAScreen, err error) {
+	return s.cachedScreen()
+}
+
+func screen(c *Client, baseUrl *url.URL) (wdaScreen WDAScreen, err error) {
 	var wdaResp wdaResponse
-	if wdaResp, err = executeGet("Screen", urlJoin(s.sessionURL, "/wda/screen")); err != nil {
+	if wdaResp, err = executeGet(c, "Screen", urlJoin(baseUrl, "/wda/screen")); err != nil {
 		return
 	}
 
-	wdaScreen.StatusBarSize._string = wdaResp.getValue().Get("statusBarSize").String()
-	wdaScreen._string = wdaResp.getValue().String()
-	err = json.Unmarshal([]byte(wdaScreen._string), &wdaScreen)
-	// err = json.Unmarshal(wdaResp.getValue2Bytes(), &wdaScreen)
+	wdaScreen.raw, err = wdaResp.unmarshalValue(&wdaScreen)
+	wdaScreen.StatusBarSize.raw = rawPath(wdaScreen.raw, "statusBarSize")
 	return
 }
 
@@ -1246,7 +1832,7 @@ func (s *Session) StatusBarSize() (wdaStatusBarSize WDASize, err error) {
 //
 // Checks if the screen is locked or not.
 func (s *Session) IsLocked() (bool, error) {
-	return isLocked(s.sessionURL)
+	return isLocked(s.client, s.sessionURL())
 }
 
 // Unlock
@@ -1254,7 +1840,7 @@ func (s *Session) IsLocked() (bool, error) {
 // Forces the device under test to unlock.
 // An immediate return will happen if the device is already unlocked and an error is going to be thrown if the screen has not been unlocked after the timeout.
 func (s *Session) Unlock() (err error) {
-	return unlock(s.sessionURL)
+	return unlock(s.client, s.sessionURL())
 }
 
 // Lock
@@ -1262,29 +1848,29 @@ func (s *Session) Unlock() (err error) {
 // Forces the device under test to switch to the lock screen.
 // An immediate return will happen if the device is already locked and an error is going to be thrown if the screen has not been locked after the timeout.
 func (s *Session) Lock() (err error) {
-	return lock(s.sessionURL)
+	return lock(s.client, s.sessionURL())
 }
 
 // Screenshot
 //
 // OR takes a screenshot of the specified element
 func (s *Session) Screenshot(element ...*Element) (raw *bytes.Buffer, err error) {
-	return screenshot(s.sessionURL, element...)
+	return screenshot(s.client, s.sessionURL(), element...)
 }
 
 // ScreenshotToDisk
 func (s *Session) ScreenshotToDisk(filename string, element ...*Element) (err error) {
-	return screenshotToDisk(s.sessionURL, filename, element...)
+	return screenshotToDisk(s.client, s.sessionURL(), filename, element...)
 }
 
 // ScreenshotToImage
 func (s *Session) ScreenshotToImage(element ...*Element) (img image.Image, format string, err error) {
-	return screenshotToImage(s.sessionURL, element...)
+	return screenshotToImage(s.client, s.sessionURL(), element...)
 }
 
 // Source
 func (s *Session) Source(srcOpt ...WDASourceOption) (sTree string, err error) {
-	return source(s.sessionURL, srcOpt...)
+	return source(s.client, s.sessionURL(), srcOpt...)
 }
 
 // AccessibleSource
@@ -1293,12 +1879,15 @@ func (s *Session) Source(srcOpt ...WDASourceOption) (sTree string, err error) {
 //
 // ignore all elements except for the main window for accessibility tree
 func (s *Session) AccessibleSource() (sJson string, err error) {
-	return accessibleSource(s.sessionURL)
+	return accessibleSource(s.client, s.sessionURL())
 }
 
+// GetAppiumSettings
+//
+// as raw JSON, see Settings for the typed ones
 func (s *Session) GetAppiumSettings() (sJson string, err error) {
 	var wdaResp wdaResponse
-	if wdaResp, err = executeGet("GetAppiumSettings", urlJoin(s.sessionURL, "/appium/settings")); err != nil {
+	if wdaResp, err = executeGet(s.client, "GetAppiumSettings", urlJoin(s.sessionURL(), "/appium/settings")); err != nil {
 		return "", err
 	}
 	return wdaResp.getValue().String(), nil
@@ -1311,12 +1900,26 @@ func (s *Session) SetAppiumSetting(key string, value interface{}) (sJson string,
 func (s *Session) SetAppiumSettings(settings map[string]interface{}) (sJson string, err error) {
 	body := newWdaBody().set("settings", settings)
 	var wdaResp wdaResponse
-	if wdaResp, err = executePost("SetAppiumSettings", urlJoin(s.sessionURL, "/appium/settings"), body); err != nil {
+	if wdaResp, err = executePost(s.client, "SetAppiumSettings", urlJoin(s.sessionURL(), "/appium/settings"), body); err != nil {
 		return "", err
 	}
 	return wdaResp.getValue().String(), nil
 }
 
+// SetDefaultAlertAction
+//
+// Accepts or dismisses every alert (e.g. permission pop-ups) as soon as it appears, so unattended runs don't get stuck.
+// `""` stops it. Same as the `defaultAlertAction` capability (WDASessionCapability.SetDefaultAlertAction), for a running session.
+func (s *Session) SetDefaultAlertAction(action WDASessionDefaultAlertAction) (err error) {
+	switch action {
+	case "", WDASessionAlertActionAccept, WDASessionAlertActionDismiss:
+	default:
+		return fmt.Errorf("unknown default alert action '%s'", action)
+	}
+	_, err = s.SetAppiumSetting("defaultAlertAction", action)
+	return
+}
+
 type WDACondition func(s *Session) (bool, error)
 
 func (s *Session) _waitWithTimeoutAndInterval(condition WDACondition, timeout, interval time.Duration) (err error) {
@@ -1347,17 +1950,64 @@ func (s *Session) WaitWithTimeoutAndInterval(condition WDACondition, timeout, in
 // WaitWithTimeout works like WaitWithTimeoutAndInterval, but with default polling interval.
 func (s *Session) WaitWithTimeout(condition WDACondition, timeout float64) error {
 	dTimeout := time.Millisecond * time.Duration(timeout*1000)
-	return s._waitWithTimeoutAndInterval(condition, dTimeout, DefaultWaitInterval)
+	return s._waitWithTimeoutAndInterval(condition, dTimeout, waitInterval())
 }
 
 // Wait works like WaitWithTimeoutAndInterval, but using the default timeout and polling interval.
 func (s *Session) Wait(condition WDACondition) error {
-	return s._waitWithTimeoutAndInterval(condition, DefaultWaitTimeout, DefaultWaitInterval)
+	return s._waitWithTimeoutAndInterval(condition, waitTimeout(), waitInterval())
 }
 
-// It's not working
-// /timeouts
-// /wda/keyboard/dismiss
+// SearchTypingFrequency used by SearchAndSelect, slow enough for search fields that query on every keystroke
+var SearchTypingFrequency = 10
+
+// SearchAndSelect
+//
+// Taps the search field, replaces its text, waits until the (asynchronously loaded) results
+// stop changing and taps the first one, which is returned.
+//
+// The results are considered stable once two consecutive polls (DefaultWaitInterval apart) find the same non-empty set.
+// `timeout` is in seconds.
+func (s *Session) SearchAndSelect(searchField WDALocator, text string, result WDALocator, timeout float64) (element *Element, err error) {
+	var field *Element
+	if field, err = s.FindElement(searchField); err != nil {
+		return nil, err
+	}
+	if err = field.Click(); err != nil {
+		return nil, err
+	}
+	if err = field.Clear(); err != nil {
+		return nil, err
+	}
+	if err = field.SendKeys(text, SearchTypingFrequency); err != nil {
+		return nil, err
+	}
+
+	var previous []string
+	condition := func(s *Session) (bool, error) {
+		var elemUIDs []string
+		it := s.Query().By(result).Iter(context.Background())
+		for it.Next() {
+			elemUIDs = append(elemUIDs, it.Element().UID)
+		}
+		if err := it.Err(); err != nil {
+			return false, err
+		}
+		stable := len(elemUIDs) != 0 && strings.Join(elemUIDs, ",") == strings.Join(previous, ",")
+		previous = elemUIDs
+		return stable, nil
+	}
+	dTimeout := time.Millisecond * time.Duration(timeout*1000)
+	if err = s._waitWithTimeoutAndInterval(condition, dTimeout, waitInterval()); err != nil {
+		return nil, fmt.Errorf("search results of '%s': %w", text, err)
+	}
+
+	element = newElement(s.client, s.sessionURL(), previous[0])
+	if err = element.Click(); err != nil {
+		return nil, err
+	}
+	return
+}
 
 func (s *Session) tttTmp() {
 	body := newWdaBody()
@@ -1370,7 +2020,7 @@ func (s *Session) tttTmp() {
 	body.set("match", true)
 
 	// [FBRoute POST:@"/wda/touch_id"]
-	wdaResp, err := executePost("###############", urlJoin(s.sessionURL, "/wda/touch_id"), body)
+	wdaResp, err := executePost(s.client, "###############", urlJoin(s.sessionURL(), "/wda/touch_id"), body)
 	_, _ = err, wdaResp
 	// fmt.Println(err, wdaResp)
 }
@@ -0,0 +1,142 @@
+package gwda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrSessionPoolClosed returned by Checkout once the pool is closed
+var ErrSessionPoolClosed = errors.New("session pool closed")
+
+// SessionPool
+//
+// Lends at most one session per device to concurrent borrowers, a session which does not answer anymore
+// is recreated on checkout.
+//
+//	pool := dm.NewSessionPool(capabilities)
+//	defer pool.Close()
+//	s, err := pool.Checkout(ctx)
+//	...
+//	pool.Checkin(s)
+type SessionPool struct {
+	capabilities []WDASessionCapability
+
+	idle chan *poolSlot
+	done chan struct{}
+
+	mutex    sync.Mutex
+	lent     map[*Session]*poolSlot
+	closed   bool
+	slotsLen int
+}
+
+type poolSlot struct {
+	client  *Client
+	session *Session
+}
+
+// NewSessionPool
+//
+// the sessions are created on demand with `capabilities`
+func (dm *DeviceManager) NewSessionPool(capabilities ...WDASessionCapability) *SessionPool {
+	p := &SessionPool{
+		capabilities: capabilities,
+		idle:         make(chan *poolSlot, len(dm.clients)),
+		done:         make(chan struct{}),
+		lent:         make(map[*Session]*poolSlot),
+		slotsLen:     len(dm.clients),
+	}
+	for _, c := range dm.clients {
+		p.idle <- &poolSlot{client: c}
+	}
+	return p
+}
+
+// Size number of devices
+func (p *SessionPool) Size() int {
+	return p.slotsLen
+}
+
+// Checkout
+//
+// Waits for a free device until `ctx` is done. The session must be given back with Checkin.
+func (p *SessionPool) Checkout(ctx context.Context) (s *Session, err error) {
+	var slot *poolSlot
+	select {
+	case slot = <-p.idle:
+	case <-p.done:
+		return nil, ErrSessionPoolClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if slot.session != nil && slot.session.Ping() != nil {
+		_ = slot.session.DeleteSession()
+		slot.session = nil
+	}
+	if slot.session == nil {
+		if slot.session, err = slot.client.NewSession(p.capabilities...); err != nil {
+			slot.session = nil
+			p.idle <- slot
+			return nil, fmt.Errorf("device %s: %w", deviceName(slot.client), err)
+		}
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.closed {
+		_ = slot.session.DeleteSession()
+		return nil, ErrSessionPoolClosed
+	}
+	p.lent[slot.session] = slot
+	return slot.session, nil
+}
+
+// Checkin
+//
+// gives back a session of Checkout, it is deleted if the pool is closed meanwhile
+func (p *SessionPool) Checkin(s *Session) {
+	p.mutex.Lock()
+	slot, ok := p.lent[s]
+	delete(p.lent, s)
+	closed := p.closed
+	if ok && !closed {
+		// under the mutex, else Close could drain `idle` before; it never blocks, there is room for every slot
+		p.idle <- slot
+	}
+	p.mutex.Unlock()
+
+	if ok && closed {
+		_ = s.DeleteSession()
+	}
+}
+
+// Close
+//
+// Deletes the idle sessions, the lent ones are deleted on Checkin.
+func (p *SessionPool) Close() (err error) {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.done)
+	p.mutex.Unlock()
+
+	for {
+		select {
+		case slot := <-p.idle:
+			if slot.session == nil {
+				continue
+			}
+			if errDel := slot.session.DeleteSession(); errDel != nil && err == nil {
+				err = errDel
+			}
+		default:
+			return
+		}
+	}
+}
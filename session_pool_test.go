@@ -0,0 +1,52 @@
+package gwda
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSessionPool(t *testing.T) {
+	dm, err := NewDeviceManager(nil, deviceURL)
+	checkErr(t, err)
+
+	pool := dm.NewSessionPool()
+	s, err := pool.Checkout(context.Background())
+	checkErr(t, err)
+
+	// the only device is lent
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err = pool.Checkout(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	// a dead session is recreated
+	checkErr(t, s.DeleteSession())
+	pool.Checkin(s)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s, err := pool.Checkout(context.Background())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer pool.Checkin(s)
+			if _, err = s.ActiveAppInfo(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	checkErr(t, pool.Close())
+	if _, err = pool.Checkout(context.Background()); !errors.Is(err, ErrSessionPoolClosed) {
+		t.Fatalf("expected %v, got %v", ErrSessionPoolClosed, err)
+	}
+}
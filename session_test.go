@@ -1,11 +1,18 @@
 package gwda
 
 import (
+	"context"
+	"errors"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestSession_GetActiveSession(t *testing.T) {
@@ -48,6 +55,120 @@ func TestSession_DeleteSession(t *testing.T) {
 	// t.Log(s.SiriOpenURL("weixin://"))
 }
 
+func TestSession_SetAutoRecover(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	WDADebug(true)
+
+	// reaped behind the back of the Session
+	_, err = executeDelete(c, "DeleteSession", s.sessionURL().String())
+	checkErr(t, err)
+
+	s.SetAutoRecover(true)
+	staleSID := s.ID()
+	_, err = s.GetActiveSession()
+	checkErr(t, err)
+	if s.ID() == staleSID {
+		t.Fatal("session not recovered")
+	}
+}
+
+func TestSession_SetAutoRecover_state(t *testing.T) {
+	var mutex sync.Mutex
+	live := map[string]bool{"S1": true}
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch sid := sessionIDFromPath(r.URL.Path); {
+		case r.URL.Path == "/health":
+			_, _ = w.Write([]byte("I-AM-ALIVE"))
+		case r.URL.Path == "/session":
+			live["S2"] = true
+			_, _ = w.Write([]byte(`{"value":{"sessionId":"S2","capabilities":{}},"sessionId":"S2"}`))
+		case !live[sid]:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"value":{"error":"invalid session id","message":"gone"}}`))
+		case strings.HasSuffix(r.URL.Path, "/text"):
+			_, _ = w.Write([]byte(`{"value":"Wi-Fi"}`))
+		default:
+			_, _ = w.Write([]byte(`{"value":null}`))
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL)
+	checkErr(t, err)
+	var created int
+	c.OnSessionCreated(func(s *Session) { created++ })
+	s, err := c.AttachSession("S1")
+	checkErr(t, err)
+	timeouts := NewWDATimeouts()
+	timeouts.Implicit = time.Second
+	checkErr(t, s.SetTimeouts(timeouts))
+	element := NewElement(s, "E1")
+	s.SetElementCache(true)
+	s.cacheElement(ByName("General"), element)
+	s.SetAutoRecover(true)
+
+	// reaped behind the back of the Session
+	mutex.Lock()
+	delete(live, "S1")
+	requests = nil
+	mutex.Unlock()
+
+	for i := 0; i < 2; i++ {
+		text, err := element.Text()
+		checkErr(t, err)
+		if text != "Wi-Fi" {
+			t.Fatal(text)
+		}
+	}
+	if s.ID() != "S2" || created != 2 {
+		t.Fatalf("session %s, created %d times", s.ID(), created)
+	}
+	if s.cachedElement(ByName("General")) != nil {
+		t.Fatal("the element of the stale session is still cached")
+	}
+	want := []string{
+		"GET /session/S1/element/E1/text",
+		"POST /session",
+		"POST /session/S2/timeouts",
+		"GET /session/S2/timeouts",
+		"GET /session/S2/element/E1/text",
+		"GET /session/S2/element/E1/text",
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("requests:\n%s", strings.Join(requests, "\n"))
+	}
+}
+
+func TestSession_SetSerialRequests(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	WDADebug(true)
+	s.SetSerialRequests(true)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.SwipeUp(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
 func TestSession_DeviceInfo(t *testing.T) {
 	c, err := NewClient(deviceURL)
 	checkErr(t, err)
@@ -59,6 +180,18 @@ func TestSession_DeviceInfo(t *testing.T) {
 	t.Log(dInfo)
 	t.Log(dInfo.Name)
 	t.Log(dInfo.CurrentLocale)
+	t.Log(dInfo.LocaleScript())
+	t.Log(dInfo.TimeZoneOffset())
+	t.Log(dInfo.ThermalState)
+	t.Log(dInfo.Field("carrierName"))
+}
+
+func TestWDADeviceInfo_LocaleScript(t *testing.T) {
+	for locale, script := range map[string]string{"zh-Hans_CN": "Hans", "zh_CN": "", "sr-Latn": "Latn", "en_US@calendar=gregorian": "", "": ""} {
+		if got := (WDADeviceInfo{CurrentLocale: locale}).LocaleScript(); got != script {
+			t.Fatalf("%s: expected %q, got %q", locale, script, got)
+		}
+	}
 }
 
 func TestSession_BatteryInfo(t *testing.T) {
@@ -120,6 +253,44 @@ func TestSession_StatusBarSize(t *testing.T) {
 	t.Log(statusBarSize.Height)
 }
 
+func TestSession_SetInfoCache(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	WDADebug(true)
+	s.SetInfoCache(true)
+	for i := 0; i < 3; i++ {
+		// only the first one is requested
+		scale, err := s.Scale()
+		checkErr(t, err)
+		t.Log(scale)
+	}
+	s.RefreshInfoCache()
+	screen, err := s.Screen()
+	checkErr(t, err)
+	t.Log(screen)
+}
+
+func TestSession_SetElementCache(t *testing.T) {
+	s := newSession(&url.URL{Scheme: "http", Host: "192.168.1.2:8100"}, "S")
+	element := newElement(nil, s.sessionURL(), "A")
+	s.cacheElement(ByName("General"), element)
+	if s.cachedElement(ByName("General")) != nil {
+		t.Fatal("the cache is disabled by default")
+	}
+
+	s.SetElementCache(true)
+	s.cacheElement(ByName("General"), element)
+	if s.cachedElement(ByName("General")) != element || s.cachedElement(ByLabel("General")) != nil {
+		t.Fatal("unexpected cached elements")
+	}
+	s.InvalidateCache()
+	if s.cachedElement(ByName("General")) != nil {
+		t.Fatal("the cache was not invalidated")
+	}
+}
+
 func TestSession_ActiveAppInfo(t *testing.T) {
 	c, err := NewClient(deviceURL)
 	checkErr(t, err)
@@ -357,6 +528,16 @@ func TestSession_ActiveElement(t *testing.T) {
 	t.Log(element.Rect())
 }
 
+func TestSession_WaitForActiveElement(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	element, err := s.WaitForActiveElement(5*time.Second, 0)
+	checkErr(t, err)
+	checkErr(t, element.SendKeys("gwda"))
+}
+
 func TestSession_AlertSendKeys(t *testing.T) {
 	c, err := NewClient(deviceURL)
 	checkErr(t, err)
@@ -802,6 +983,22 @@ func TestSession_GetAppiumSettings(t *testing.T) {
 	t.Log(settings)
 }
 
+func TestSession_SetDefaultAlertAction(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession(NewWDASessionCapability(bundleId).SetDefaultAlertAction(WDASessionAlertActionDismiss))
+	checkErr(t, err)
+	WDADebug(true)
+	checkErr(t, s.SetDefaultAlertAction(WDASessionAlertActionAccept))
+	settings, err := s.GetAppiumSettings()
+	checkErr(t, err)
+	t.Log(settings)
+	checkErr(t, s.SetDefaultAlertAction(""))
+	if err = s.SetDefaultAlertAction("ignore"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
 func TestSession_Wait(t *testing.T) {
 	c, err := NewClient(deviceURL)
 	checkErr(t, err)
@@ -823,6 +1020,21 @@ func TestSession_Wait(t *testing.T) {
 	checkErr(t, element.Click())
 }
 
+func TestSession_SearchAndSelect(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	WDADebug(true)
+
+	checkErr(t, s.AppLaunch("com.apple.Preferences"))
+	searchField := WDALocator{ClassName: WDAElementType{SearchField: true}}
+	result := WDALocator{Predicate: "type == 'XCUIElementTypeCell' AND label CONTAINS '蓝牙'"}
+	element, err := s.SearchAndSelect(searchField, "蓝牙", result, 10)
+	checkErr(t, err)
+	t.Log(element.Label())
+}
+
 func TestTmpSession(t *testing.T) {
 	c, err := NewClient(deviceURL)
 	checkErr(t, err)
@@ -856,3 +1068,87 @@ func TestTmpSession(t *testing.T) {
 	s.tttTmp()
 	// _ = s
 }
+
+func TestSession_close(t *testing.T) {
+	s := new(Session)
+	ctx, err := s.track(context.Background(), &wdaCall{actionName: "FindElements"})
+	checkErr(t, err)
+	s.close()
+	if ctx.Err() == nil {
+		t.Error("the request in flight is not cancelled")
+	}
+	if _, err = s.track(context.Background(), &wdaCall{actionName: "Source"}); !errors.Is(err, ErrSessionClosed) {
+		t.Error("a closed session must refuse requests:", err)
+	}
+	if _, err = s.track(context.Background(), &wdaCall{actionName: "DeleteSession"}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWDAActions_PenActionOption(t *testing.T) {
+	pen := NewWDAActionOptionFinger().
+		Move(NewWWDAActionOptionFingerMove().SetXY(10, 10)).
+		DownWithPressure(1.5).
+		Move(NewWWDAActionOptionFingerMove().SetXY(50, 10).SetPressure(0.4).SetTilt(30, -120)).
+		Up()
+	actions := NewWDAActions().FingerActionOption(NewWDAActionOptionFinger().Down().Up()).PenActionOption(pen)
+	if len(*actions) != 2 {
+		t.Fatalf("expected 2 pointers, got %d", len(*actions))
+	}
+	pointer := (*actions)[1]
+	if pointer["id"] != "pen2" || pointer["parameters"].(wdaBody)["pointerType"] != "pen" {
+		t.Fatalf("unexpected pointer: %v", pointer)
+	}
+	steps := pointer["actions"].(WDAActionOptionFinger)
+	if steps[1]["pressure"] != 1.0 {
+		t.Fatalf("pressure should be clamped to 1, got %v", steps[1]["pressure"])
+	}
+	move := steps[2]
+	if move["pressure"] != 0.4 || move["tiltX"] != 30 || move["tiltY"] != -90 {
+		t.Fatalf("unexpected move: %v", move)
+	}
+}
+
+func TestSession_AppLaunchUnattached(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	checkErr(t, s.AppLaunchUnattached("com.apple.camera"))
+}
+
+func TestSession_FindNth(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	checkErr(t, s.AppLaunch("com.apple.Preferences"))
+
+	cells, err := s.FindElements(WDALocator{ClassName: WDAElementType{Cell: true}})
+	checkErr(t, err)
+	third, err := s.FindNth(WDALocator{ClassName: WDAElementType{Cell: true}}, 3)
+	checkErr(t, err)
+	if third.UID != cells[2].UID {
+		t.Fatalf("expected %s, got %s", cells[2].UID, third.UID)
+	}
+	last, err := s.FindElementAt(ByXPath("//XCUIElementTypeCell"), len(cells)-1)
+	checkErr(t, err)
+	t.Log(last.Label())
+}
+
+func TestSession_CountElements(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	checkErr(t, s.AppLaunch("com.apple.Preferences"))
+
+	count, err := s.CountElements(WDALocator{ClassName: WDAElementType{Cell: true}})
+	checkErr(t, err)
+	if count == 0 {
+		t.Fatal("expected some cells")
+	}
+	if count, err = s.CountElements(ByName("gwda-missing")); err != nil || count != 0 {
+		t.Fatalf("expected no match, got %d, %v", count, err)
+	}
+}
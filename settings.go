@@ -0,0 +1,336 @@
+package gwda
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"sort"
+	"time"
+)
+
+// WDAScreenshotQuality of the `screenshotQuality` setting
+type WDAScreenshotQuality int
+
+const (
+	WDAScreenshotQualityHigh   WDAScreenshotQuality = 0
+	WDAScreenshotQualityMedium WDAScreenshotQuality = 1
+	WDAScreenshotQualityLow    WDAScreenshotQuality = 2
+)
+
+// WDASettings
+//
+// Typed view of `/appium/settings`. The settings unknown to it are kept as is (see Get and Set),
+// only the changed ones are sent by Session.ApplySettings.
+//
+//	settings, err := s.Settings()
+//	timeout, _ := settings.SnapshotTimeout()
+//	err = s.ApplySettings(settings.SetSnapshotTimeout(2 * timeout).SetScreenshotQuality(WDAScreenshotQualityLow))
+type WDASettings struct {
+	values  map[string]json.RawMessage
+	changed map[string]bool
+}
+
+// NewWDASettings
+//
+// no settings, to only send a few changes
+func NewWDASettings() *WDASettings {
+	return &WDASettings{values: make(map[string]json.RawMessage), changed: make(map[string]bool)}
+}
+
+func (ws *WDASettings) String() string {
+	return string(ws.RawJSON())
+}
+
+func (ws *WDASettings) RawJSON() json.RawMessage {
+	bs, _ := json.Marshal(ws.values)
+	return bs
+}
+
+func (ws *WDASettings) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ws.values)
+}
+
+// Keys of the settings, sorted
+func (ws *WDASettings) Keys() []string {
+	keys := make([]string, 0, len(ws.values))
+	for k := range ws.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Get
+//
+// Decodes the setting into `v`, `ok` is `false` when the WDA build does not provide it.
+func (ws *WDASettings) Get(key string, v interface{}) (ok bool, err error) {
+	raw, ok := ws.values[key]
+	if !ok {
+		return false, nil
+	}
+	if err = jsonUnmarshal(raw, v); err != nil {
+		return true, fmt.Errorf("setting '%s': %w", key, err)
+	}
+	return true, nil
+}
+
+// Set
+//
+// any setting, `value` must be encodable to JSON
+func (ws *WDASettings) Set(key string, value interface{}) *WDASettings {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		raw, _ = json.Marshal(fmt.Sprint(value))
+	}
+	ws.values[key] = raw
+	ws.changed[key] = true
+	return ws
+}
+
+// changes since the last Settings or ApplySettings
+func (ws *WDASettings) changes() map[string]interface{} {
+	changes := make(map[string]interface{}, len(ws.changed))
+	for k := range ws.changed {
+		changes[k] = ws.values[k]
+	}
+	return changes
+}
+
+func (ws *WDASettings) getSeconds(key string) (d time.Duration, ok bool) {
+	var sec float64
+	if ok, _ = ws.Get(key, &sec); ok {
+		d = time.Duration(sec * float64(time.Second))
+	}
+	return
+}
+
+func (ws *WDASettings) getInt(key string) (n int, ok bool) {
+	var f float64
+	ok, _ = ws.Get(key, &f)
+	return int(f), ok
+}
+
+func (ws *WDASettings) getBool(key string) (b bool, ok bool) {
+	ok, _ = ws.Get(key, &b)
+	return
+}
+
+func (ws *WDASettings) getString(key string) (s string, ok bool) {
+	ok, _ = ws.Get(key, &s)
+	return
+}
+
+// SnapshotTimeout
+//
+// how long WDA waits for the accessibility snapshot of a query (`snapshotTimeout`)
+func (ws *WDASettings) SnapshotTimeout() (time.Duration, bool) {
+	return ws.getSeconds("snapshotTimeout")
+}
+
+func (ws *WDASettings) SetSnapshotTimeout(d time.Duration) *WDASettings {
+	return ws.Set("snapshotTimeout", d.Seconds())
+}
+
+// CustomSnapshotTimeout
+//
+// how long WDA waits for the snapshot of the page source (`customSnapshotTimeout`)
+func (ws *WDASettings) CustomSnapshotTimeout() (time.Duration, bool) {
+	return ws.getSeconds("customSnapshotTimeout")
+}
+
+func (ws *WDASettings) SetCustomSnapshotTimeout(d time.Duration) *WDASettings {
+	return ws.Set("customSnapshotTimeout", d.Seconds())
+}
+
+// SnapshotMaxDepth of the page source (`snapshotMaxDepth`)
+func (ws *WDASettings) SnapshotMaxDepth() (int, bool) {
+	return ws.getInt("snapshotMaxDepth")
+}
+
+func (ws *WDASettings) SetSnapshotMaxDepth(depth int) *WDASettings {
+	return ws.Set("snapshotMaxDepth", depth)
+}
+
+// MjpegServerFramerate
+//
+// frames per second of the MJPEG stream (`mjpegServerFramerate`)
+func (ws *WDASettings) MjpegServerFramerate() (int, bool) {
+	return ws.getInt("mjpegServerFramerate")
+}
+
+func (ws *WDASettings) SetMjpegServerFramerate(fps int) *WDASettings {
+	return ws.Set("mjpegServerFramerate", fps)
+}
+
+// MjpegServerScreenshotQuality
+//
+// JPEG quality of the MJPEG stream in [1, 100] (`mjpegServerScreenshotQuality`)
+func (ws *WDASettings) MjpegServerScreenshotQuality() (int, bool) {
+	return ws.getInt("mjpegServerScreenshotQuality")
+}
+
+func (ws *WDASettings) SetMjpegServerScreenshotQuality(quality int) *WDASettings {
+	return ws.Set("mjpegServerScreenshotQuality", quality)
+}
+
+// MjpegScalingFactor
+//
+// percentage of the screen size of the MJPEG stream in [1, 100] (`mjpegScalingFactor`)
+func (ws *WDASettings) MjpegScalingFactor() (float64, bool) {
+	var f float64
+	ok, _ := ws.Get("mjpegScalingFactor", &f)
+	return f, ok
+}
+
+func (ws *WDASettings) SetMjpegScalingFactor(percentage float64) *WDASettings {
+	return ws.Set("mjpegScalingFactor", percentage)
+}
+
+// ScreenshotQuality of Session.Screenshot (`screenshotQuality`)
+func (ws *WDASettings) ScreenshotQuality() (WDAScreenshotQuality, bool) {
+	n, ok := ws.getInt("screenshotQuality")
+	return WDAScreenshotQuality(n), ok
+}
+
+func (ws *WDASettings) SetScreenshotQuality(quality WDAScreenshotQuality) *WDASettings {
+	return ws.Set("screenshotQuality", int(quality))
+}
+
+// ActiveAppDetectionPoint
+//
+// the app at this point is considered active when several apps are (`activeAppDetectionPoint`, e.g. `64.00,640.00`)
+func (ws *WDASettings) ActiveAppDetectionPoint() (p image.Point, ok bool) {
+	var s string
+	if s, ok = ws.getString("activeAppDetectionPoint"); !ok {
+		return
+	}
+	var x, y float64
+	if _, err := fmt.Sscanf(s, "%g,%g", &x, &y); err != nil {
+		return image.Point{}, false
+	}
+	return image.Pt(int(x), int(y)), true
+}
+
+func (ws *WDASettings) SetActiveAppDetectionPoint(p image.Point) *WDASettings {
+	return ws.Set("activeAppDetectionPoint", fmt.Sprintf("%d,%d", p.X, p.Y))
+}
+
+// ShouldUseCompactResponses `shouldUseCompactResponses`
+func (ws *WDASettings) ShouldUseCompactResponses() (bool, bool) {
+	return ws.getBool("shouldUseCompactResponses")
+}
+
+func (ws *WDASettings) SetShouldUseCompactResponses(b bool) *WDASettings {
+	return ws.Set("shouldUseCompactResponses", b)
+}
+
+// ElementResponseAttributes
+//
+// attributes returned with the found elements when the responses are not compact (`elementResponseAttributes`)
+func (ws *WDASettings) ElementResponseAttributes() (string, bool) {
+	return ws.getString("elementResponseAttributes")
+}
+
+func (ws *WDASettings) SetElementResponseAttributes(attrs string) *WDASettings {
+	return ws.Set("elementResponseAttributes", attrs)
+}
+
+// UseFirstMatch
+//
+// finds the first matching element only, faster but the order may differ (`useFirstMatch`)
+func (ws *WDASettings) UseFirstMatch() (bool, bool) {
+	return ws.getBool("useFirstMatch")
+}
+
+func (ws *WDASettings) SetUseFirstMatch(b bool) *WDASettings {
+	return ws.Set("useFirstMatch", b)
+}
+
+// ReduceMotion `reduceMotion`
+func (ws *WDASettings) ReduceMotion() (bool, bool) {
+	return ws.getBool("reduceMotion")
+}
+
+func (ws *WDASettings) SetReduceMotion(b bool) *WDASettings {
+	return ws.Set("reduceMotion", b)
+}
+
+// DefaultAlertAction `defaultAlertAction`, see Session.SetDefaultAlertAction
+func (ws *WDASettings) DefaultAlertAction() (WDASessionDefaultAlertAction, bool) {
+	action, ok := ws.getString("defaultAlertAction")
+	return WDASessionDefaultAlertAction(action), ok
+}
+
+func (ws *WDASettings) SetDefaultAlertAction(action WDASessionDefaultAlertAction) *WDASettings {
+	return ws.Set("defaultAlertAction", action)
+}
+
+// Settings
+//
+// GetAppiumSettings decoded
+func (s *Session) Settings() (settings *WDASettings, err error) {
+	var wdaResp wdaResponse
+	if wdaResp, err = executeGet(s.client, "GetAppiumSettings", urlJoin(s.sessionURL(), "/appium/settings")); err != nil {
+		return nil, err
+	}
+	settings = NewWDASettings()
+	if _, err = wdaResp.unmarshalValue(&settings.values); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// ApplySettings
+//
+// Sends the changed settings, `settings` is then updated with the ones WDA reports.
+func (s *Session) ApplySettings(settings *WDASettings) (err error) {
+	if len(settings.changed) == 0 {
+		return nil
+	}
+	body := newWdaBody().set("settings", settings.changes())
+	var wdaResp wdaResponse
+	if wdaResp, err = executePost(s.client, "SetAppiumSettings", urlJoin(s.sessionURL(), "/appium/settings"), body); err != nil {
+		return err
+	}
+	values := make(map[string]json.RawMessage)
+	if _, err = wdaResp.unmarshalValue(&values); err != nil {
+		return err
+	}
+	settings.values, settings.changed = values, make(map[string]bool)
+	return
+}
+
+// WithSettings
+//
+// Applies `overrides`, runs `fn`, then restores the previous values of the overridden settings,
+// also when `fn` fails or panics, so the settings of a test case don't leak to the next ones sharing the session.
+// The settings WDA did not report before are left as `fn` set them.
+//
+//	err := s.WithSettings(map[string]interface{}{"snapshotMaxDepth": 100}, func() error {
+//		_, err := s.Source()
+//		return err
+//	})
+func (s *Session) WithSettings(overrides map[string]interface{}, fn func() error) (err error) {
+	var previous *WDASettings
+	if previous, err = s.Settings(); err != nil {
+		return err
+	}
+	restore := NewWDASettings()
+	changes := NewWDASettings()
+	for k, v := range overrides {
+		changes.Set(k, v)
+		if raw, ok := previous.values[k]; ok {
+			restore.Set(k, raw)
+		}
+	}
+
+	if err = s.ApplySettings(changes); err != nil {
+		return err
+	}
+	defer func() {
+		if errRestore := s.ApplySettings(restore); errRestore != nil && err == nil {
+			err = fmt.Errorf("restore settings: %w", errRestore)
+		}
+	}()
+	return fn()
+}
@@ -0,0 +1,92 @@
+package gwda
+
+import (
+	"encoding/json"
+	"errors"
+	"image"
+	"testing"
+	"time"
+)
+
+func TestWDASettings(t *testing.T) {
+	settings := NewWDASettings()
+	checkErr(t, json.Unmarshal([]byte(`{"snapshotTimeout":15,"mjpegServerFramerate":10,"screenshotQuality":1,
+"activeAppDetectionPoint":"64.00,640.00","useFirstMatch":false,"customFlag":"x"}`), &settings.values))
+
+	if d, ok := settings.SnapshotTimeout(); !ok || d != 15*time.Second {
+		t.Errorf("snapshotTimeout: %v %v", d, ok)
+	}
+	if fps, ok := settings.MjpegServerFramerate(); !ok || fps != 10 {
+		t.Errorf("mjpegServerFramerate: %v %v", fps, ok)
+	}
+	if q, ok := settings.ScreenshotQuality(); !ok || q != WDAScreenshotQualityMedium {
+		t.Errorf("screenshotQuality: %v %v", q, ok)
+	}
+	if p, ok := settings.ActiveAppDetectionPoint(); !ok || p != image.Pt(64, 640) {
+		t.Errorf("activeAppDetectionPoint: %v %v", p, ok)
+	}
+	if _, ok := settings.CustomSnapshotTimeout(); ok {
+		t.Error("customSnapshotTimeout is not provided")
+	}
+	var flag string
+	if ok, err := settings.Get("customFlag", &flag); !ok || err != nil || flag != "x" {
+		t.Errorf("customFlag: %v %v %v", flag, ok, err)
+	}
+	if len(settings.changes()) != 0 {
+		t.Fatalf("unexpected changes: %v", settings.changes())
+	}
+
+	settings.SetSnapshotTimeout(1500 * time.Millisecond).SetActiveAppDetectionPoint(image.Pt(10, 20))
+	bs, err := json.Marshal(settings.changes())
+	checkErr(t, err)
+	if want := `{"activeAppDetectionPoint":"10,20","snapshotTimeout":1.5}`; string(bs) != want {
+		t.Errorf("changes: got %s, want %s", bs, want)
+	}
+}
+
+func TestSession_ApplySettings(t *testing.T) {
+	client, err := NewClient(deviceURL)
+	checkErr(t, err)
+	session, err := client.NewSession()
+	checkErr(t, err)
+
+	settings, err := session.Settings()
+	checkErr(t, err)
+	timeout, _ := settings.SnapshotTimeout()
+	defer func() { _ = session.ApplySettings(NewWDASettings().SetSnapshotTimeout(timeout)) }()
+
+	checkErr(t, session.ApplySettings(settings.SetSnapshotTimeout(timeout+time.Second)))
+	if got, _ := settings.SnapshotTimeout(); got != timeout+time.Second {
+		t.Fatalf("snapshotTimeout: got %v, want %v", got, timeout+time.Second)
+	}
+}
+
+func TestSession_WithSettings(t *testing.T) {
+	client, err := NewClient(deviceURL)
+	checkErr(t, err)
+	session, err := client.NewSession()
+	checkErr(t, err)
+
+	settings, err := session.Settings()
+	checkErr(t, err)
+	depth, _ := settings.SnapshotMaxDepth()
+
+	errFn := errors.New("fn")
+	err = session.WithSettings(map[string]interface{}{"snapshotMaxDepth": depth + 10}, func() error {
+		inside, err := session.Settings()
+		checkErr(t, err)
+		if got, _ := inside.SnapshotMaxDepth(); got != depth+10 {
+			t.Errorf("snapshotMaxDepth inside: got %d, want %d", got, depth+10)
+		}
+		return errFn
+	})
+	if !errors.Is(err, errFn) {
+		t.Fatalf("expected %v, got %v", errFn, err)
+	}
+
+	settings, err = session.Settings()
+	checkErr(t, err)
+	if got, _ := settings.SnapshotMaxDepth(); got != depth {
+		t.Fatalf("snapshotMaxDepth after: got %d, want %d", got, depth)
+	}
+}
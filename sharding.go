@@ -0,0 +1,195 @@
+package gwda
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Scenario
+//
+// `Run` gets a new session and a directory of its own for screenshots, logs, etc.
+type Scenario struct {
+	Name string
+	Run  func(s *Session, artifactDir string) error
+}
+
+// ScenarioResult
+type ScenarioResult struct {
+	Name        string
+	Device      string // where the last attempt ran
+	ArtifactDir string // of the last attempt
+	Attempts    int
+	Duration    time.Duration // of all attempts
+	Err         error
+}
+
+func (sr ScenarioResult) Passed() bool {
+	return sr.Err == nil && sr.Attempts != 0
+}
+
+// ShardOption
+type ShardOption struct {
+	capabilities WDASessionCapability
+	retries      int
+	artifactDir  string
+	isInfraError func(err error) bool
+}
+
+// NewShardOption
+//
+// Default retries an infrastructure error once on the same device and writes the artifacts to `artifacts`,
+// unless the config (see LoadConfig) says otherwise
+func NewShardOption() *ShardOption {
+	so := &ShardOption{retries: 1, artifactDir: "artifacts", isInfraError: IsInfrastructureError}
+	if cfg := currentConfig(); cfg != nil {
+		if cfg.Shard.Retries != nil {
+			so.retries = *cfg.Shard.Retries
+		}
+		if cfg.Shard.ArtifactDir != "" {
+			so.artifactDir = cfg.Shard.ArtifactDir
+		}
+	}
+	return so
+}
+
+// SetCapabilities of the session created for every attempt
+func (so *ShardOption) SetCapabilities(capabilities WDASessionCapability) *ShardOption {
+	so.capabilities = capabilities
+	return so
+}
+
+// SetRetries
+//
+// Attempts on the same device after an infrastructure error. Once they are exhausted the device is given up,
+// its scenario goes to the remaining devices.
+func (so *ShardOption) SetRetries(n int) *ShardOption {
+	so.retries = n
+	return so
+}
+
+// SetArtifactDir
+//
+// root of `<dir>/<scenario>/<device>/<attempt>`
+func (so *ShardOption) SetArtifactDir(dir string) *ShardOption {
+	so.artifactDir = dir
+	return so
+}
+
+// SetInfraErrorFunc
+//
+// tells infrastructure errors (retried) from test failures (reported), default is IsInfrastructureError
+func (so *ShardOption) SetInfraErrorFunc(fn func(err error) bool) *ShardOption {
+	so.isInfraError = fn
+	return so
+}
+
+// IsInfrastructureError
+//
+// WDA is unreachable or lost the session
+func IsInfrastructureError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return strings.Contains(err.Error(), "invalid session id")
+}
+
+// RunScenarios
+//
+// Runs the scenarios in parallel, every device runs one at a time, and returns the results in the order of `scenarios`.
+// `opt` can be `nil`.
+func (dm *DeviceManager) RunScenarios(scenarios []Scenario, opt *ShardOption) []ScenarioResult {
+	if opt == nil {
+		opt = NewShardOption()
+	}
+	results := make([]ScenarioResult, len(scenarios))
+	for i := range scenarios {
+		results[i].Name = scenarios[i].Name
+	}
+
+	pending := make(chan int, len(scenarios))
+	for i := range scenarios {
+		pending <- i
+	}
+	if len(scenarios) == 0 {
+		close(pending)
+	}
+	remaining := int32(len(scenarios))
+
+	var wg sync.WaitGroup
+	for _, c := range dm.clients {
+		wg.Add(1)
+		go func(c *Client) {
+			defer wg.Done()
+			for i := range pending {
+				result := &results[i]
+				if broken := opt.runOnDevice(c, scenarios[i], result); broken {
+					// someone else may take it
+					pending <- i
+					return
+				}
+				if atomic.AddInt32(&remaining, -1) == 0 {
+					close(pending)
+				}
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	for i := range results {
+		if results[i].Err == nil && results[i].Attempts == 0 {
+			results[i].Err = errors.New("no device left")
+		}
+	}
+	return results
+}
+
+// runOnDevice returns `true` if the device failed every attempt with an infrastructure error
+func (so *ShardOption) runOnDevice(c *Client, scenario Scenario, result *ScenarioResult) (broken bool) {
+	for attempt := 0; attempt <= so.retries; attempt++ {
+		result.Attempts++
+		result.Device = deviceName(c)
+		result.ArtifactDir = filepath.Join(so.artifactDir, scenario.Name, result.Device, fmt.Sprint(result.Attempts))
+
+		start := time.Now()
+		result.Err = so.runOnce(c, scenario, result.ArtifactDir)
+		result.Duration += time.Since(start)
+
+		if result.Err == nil || !so.isInfraError(result.Err) {
+			return false
+		}
+	}
+	return true
+}
+
+func (so *ShardOption) runOnce(c *Client, scenario Scenario, artifactDir string) (err error) {
+	if err = os.MkdirAll(artifactDir, 0755); err != nil {
+		return err
+	}
+	var s *Session
+	if so.capabilities != nil {
+		s, err = c.NewSession(so.capabilities)
+	} else {
+		s, err = c.NewSession()
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = s.DeleteSession()
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return scenario.Run(s, artifactDir)
+}
@@ -0,0 +1,54 @@
+package gwda
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsInfrastructureError(t *testing.T) {
+	_, errDial := net.Dial("tcp", "127.0.0.1:0")
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("no such element: unable to find an element"), false},
+		{errors.New("invalid session id: Session does not exist"), true},
+		{fmt.Errorf("Tap: failed to send request %w", errDial), true},
+	} {
+		if got := IsInfrastructureError(tc.err); got != tc.want {
+			t.Errorf("IsInfrastructureError(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestDeviceManager_RunScenarios(t *testing.T) {
+	dm, err := NewDeviceManager(nil, deviceURL)
+	checkErr(t, err)
+
+	scenarios := []Scenario{
+		{Name: "home", Run: func(s *Session, artifactDir string) error {
+			if err := s.PressHomeButton(); err != nil {
+				return err
+			}
+			return s.ScreenshotToDisk(filepath.Join(artifactDir, "home.png"))
+		}},
+		{Name: "settings", Run: func(s *Session, artifactDir string) error {
+			return s.AppLaunch("com.apple.Preferences")
+		}},
+	}
+	dir, err := ioutil.TempDir("", "gwda")
+	checkErr(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	for _, result := range dm.RunScenarios(scenarios, NewShardOption().SetArtifactDir(dir)) {
+		t.Log(result.Name, result.Device, result.Attempts, result.Duration, result.Err)
+		if !result.Passed() {
+			t.Error(result.Err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package gwda
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SliderValueTolerance how far (in percent) the value of a slider may be from the one set by SetSliderValue
+var SliderValueTolerance = 3.0
+
+// SetSliderValue
+//
+// Moves a slider to `percent` (0 to 100) of its range and checks its `value` attribute.
+// WDA adjusts the slider itself where it supports it, otherwise the thumb is dragged, its position computed from the rect.
+// A slider whose value is not a percentage (a custom accessibility value) can not be verified.
+func (e *Element) SetSliderValue(percent float64) (err error) {
+	if percent < 0 || percent > 100 {
+		return errors.New("'percent' must be in [0, 100]")
+	}
+	// appium WDA: `adjustToNormalizedSliderPosition`
+	if err = sendKeys(e.client, urlJoin(e.endpoint, e._withFormat("/value")), strconv.FormatFloat(percent/100, 'f', -1, 64)); err == nil {
+		if err = e.verifySliderValue(percent); err == nil {
+			return nil
+		}
+	}
+	debugLog(fmt.Sprintf("slider: %s, dragging the thumb", err))
+
+	var current float64
+	var ok bool
+	if current, ok, err = e.sliderValue(); err != nil {
+		return err
+	}
+	if !ok {
+		current = 50
+	}
+	var rect WDARect
+	if rect, err = e.Rect(); err != nil {
+		return err
+	}
+	width, y := float64(rect.Width), float64(rect.Height)/2
+	if err = e.DragFloat(width*current/100, y, width*percent/100, y, 0.5); err != nil {
+		return err
+	}
+	return e.verifySliderValue(percent)
+}
+
+func (e *Element) verifySliderValue(percent float64) (err error) {
+	var value float64
+	var ok bool
+	if value, ok, err = e.sliderValue(); err != nil || !ok {
+		return err
+	}
+	if diff := value - percent; diff > SliderValueTolerance || diff < -SliderValueTolerance {
+		return fmt.Errorf("slider value is %v%% instead of %v%%", value, percent)
+	}
+	return nil
+}
+
+// sliderValue the percentage of the `value` attribute, e.g. `50%`, `ok` is false when it is something else
+func (e *Element) sliderValue() (percent float64, ok bool, err error) {
+	var value string
+	if value, err = e.Value(); err != nil {
+		return 0, false, err
+	}
+	percent, ok = parseSliderValue(value)
+	return
+}
+
+func parseSliderValue(value string) (percent float64, ok bool) {
+	value = strings.TrimSpace(value)
+	if !strings.HasSuffix(value, "%") {
+		return 0, false
+	}
+	// e.g. `50 %` depending on the locale
+	var err error
+	if percent, err = strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(value, "%")), 64); err != nil {
+		return 0, false
+	}
+	return percent, true
+}
@@ -0,0 +1,30 @@
+package gwda
+
+import "testing"
+
+func Test_parseSliderValue(t *testing.T) {
+	for value, want := range map[string]float64{"50%": 50, " 12.5 %": 12.5, "0%": 0} {
+		if got, ok := parseSliderValue(value); !ok || got != want {
+			t.Errorf("parseSliderValue(%q) = %v, %v", value, got, ok)
+		}
+	}
+	for _, value := range []string{"", "medium", "x%"} {
+		if _, ok := parseSliderValue(value); ok {
+			t.Errorf("parseSliderValue(%q): expected no percentage", value)
+		}
+	}
+}
+
+func TestElement_SetSliderValue(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+
+	slider, err := s.FindElement(WDALocator{ClassName: WDAElementType{Slider: true}})
+	checkErr(t, err)
+	checkErr(t, slider.SetSliderValue(30))
+	if err = slider.SetSliderValue(120); err == nil {
+		t.Fatal("expected an error out of range")
+	}
+}
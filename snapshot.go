@@ -0,0 +1,420 @@
+package gwda
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// WDASnapshot
+//
+// The source of a screen fetched once, the finds are resolved locally against it without loading WDA.
+// Useful for read-heavy checks of a static screen, the found nodes carry their rect to tap by coordinate.
+//
+//	snapshot, err := s.Snapshot()
+//	node, err := snapshot.Find(WDALocator{Predicate: "type == 'XCUIElementTypeButton' AND label BEGINSWITH[c] 'general'"})
+//	err = s.TapCoordinate(node.Center())
+//
+// Supported locators are class name, name, id, accessibility id, link text, partial link text and predicate
+// (comparisons with ==, !=, BEGINSWITH, ENDSWITH, CONTAINS, LIKE, MATCHES and IN, the [c] modifier, AND, OR and NOT).
+type WDASnapshot struct {
+	root  *WDASourceNode
+	nodes []*WDASourceNode // depth first
+}
+
+// Snapshot
+//
+// fetches the source once, see WDASnapshot
+func (s *Session) Snapshot() (snapshot *WDASnapshot, err error) {
+	var root *WDASourceNode
+	if root, err = s.SourceTree(); err != nil {
+		return nil, err
+	}
+	return NewWDASnapshot(root), nil
+}
+
+func NewWDASnapshot(root *WDASourceNode) *WDASnapshot {
+	snapshot := &WDASnapshot{root: root}
+	root.Walk(func(node *WDASourceNode) bool {
+		snapshot.nodes = append(snapshot.nodes, node)
+		return true
+	})
+	return snapshot
+}
+
+func (snap *WDASnapshot) Root() *WDASourceNode {
+	return snap.root
+}
+
+// FindAll
+//
+// the matching nodes in document order, no matches is not an error
+func (snap *WDASnapshot) FindAll(wdaLocator WDALocator) (nodes []*WDASourceNode, err error) {
+	var match func(node *WDASourceNode) bool
+	if match, err = snapshotMatcher(wdaLocator); err != nil {
+		return nil, err
+	}
+	for _, node := range snap.nodes {
+		if match(node) {
+			nodes = append(nodes, node)
+		}
+	}
+	return
+}
+
+// Find the first matching node
+func (snap *WDASnapshot) Find(wdaLocator WDALocator) (node *WDASourceNode, err error) {
+	var nodes []*WDASourceNode
+	if nodes, err = snap.FindAll(wdaLocator); err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		using, value := wdaLocator.getUsingAndValue()
+		return nil, fmt.Errorf("%w: unable to find an element using '%s', value '%s'", ErrNoSuchElement, using, value)
+	}
+	return nodes[0], nil
+}
+
+// Center of the rect, to tap the node
+func (n *WDASourceNode) Center() WDACoordinate {
+	return n.Rect.Center()
+}
+
+// attribute as WDA compares it in predicates, flags are `1` or `0`
+func (n *WDASourceNode) attribute(name string) (value string, ok bool) {
+	switch name {
+	case "type", "elementType", "wdType":
+		return n.ElementType(), true
+	case "name", "identifier", "wdName":
+		return n.Name, true
+	case "label", "wdLabel":
+		return n.Label, true
+	case "value", "wdValue":
+		return n.Value, true
+	case "enabled", "isEnabled", "wdEnabled":
+		return boolFlag(n.IsEnabled), true
+	case "visible", "isVisible", "wdVisible", "displayed":
+		return boolFlag(n.IsVisible), true
+	default:
+		return "", false
+	}
+}
+
+func boolFlag(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+func snapshotMatcher(wdaLocator WDALocator) (match func(node *WDASourceNode) bool, err error) {
+	using, value := wdaLocator.getUsingAndValue()
+	switch using {
+	case "class name":
+		return func(node *WDASourceNode) bool { return node.ElementType() == value }, nil
+	case "name", "id", "accessibility id":
+		return func(node *WDASourceNode) bool { return node.Name == value }, nil
+	case "link text", "partial link text":
+		// `label=General`
+		i := strings.Index(value, "=")
+		if i == -1 {
+			return nil, fmt.Errorf("invalid %s: %s", using, value)
+		}
+		attr, text := value[:i], value[i+1:]
+		partial := using == "partial link text"
+		return func(node *WDASourceNode) bool {
+			v, _ := node.attribute(attr)
+			if partial {
+				return strings.Contains(v, text)
+			}
+			return v == text
+		}, nil
+	case "predicate string":
+		return compilePredicate(value)
+	case "":
+		return nil, fmt.Errorf("'WDALocator' is empty")
+	default:
+		return nil, fmt.Errorf("'%s' is not supported by snapshots", using)
+	}
+}
+
+// compilePredicate the subset of NSPredicate used to find elements
+func compilePredicate(predicate string) (match func(node *WDASourceNode) bool, err error) {
+	p := &predicateParser{input: predicate}
+	if err = p.tokenize(); err != nil {
+		return nil, err
+	}
+	if match, err = p.parseOr(); err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("predicate %q: unexpected '%s'", predicate, p.tokens[p.pos].text)
+	}
+	return match, nil
+}
+
+type predicateToken struct {
+	text     string
+	isString bool // quoted, never a keyword
+}
+
+type predicateParser struct {
+	input  string
+	tokens []predicateToken
+	pos    int
+}
+
+func (p *predicateParser) tokenize() error {
+	runes := []rune(p.input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'' || r == '"':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != r; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				sb.WriteRune(runes[j])
+			}
+			if j == len(runes) {
+				return fmt.Errorf("predicate %q: unterminated string", p.input)
+			}
+			p.tokens = append(p.tokens, predicateToken{text: sb.String(), isString: true})
+			i = j + 1
+		case strings.ContainsRune("(){},", r):
+			p.tokens = append(p.tokens, predicateToken{text: string(r)})
+			i++
+		case strings.ContainsRune("=!<>&|", r):
+			j := i
+			for j < len(runes) && strings.ContainsRune("=!<>&|", runes[j]) {
+				j++
+			}
+			p.tokens = append(p.tokens, predicateToken{text: string(runes[i:j])})
+			i = j
+		case r == '[':
+			// modifier of the previous operator, e.g. `BEGINSWITH[c]`
+			j := i
+			for j < len(runes) && runes[j] != ']' {
+				j++
+			}
+			if j == len(runes) || len(p.tokens) == 0 {
+				return fmt.Errorf("predicate %q: invalid modifier", p.input)
+			}
+			p.tokens[len(p.tokens)-1].text += string(runes[i : j+1])
+			i = j + 1
+		default:
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_' || runes[j] == '.' || runes[j] == '-') {
+				j++
+			}
+			if j == i {
+				return fmt.Errorf("predicate %q: unexpected '%c'", p.input, r)
+			}
+			p.tokens = append(p.tokens, predicateToken{text: string(runes[i:j])})
+			i = j
+		}
+	}
+	return nil
+}
+
+func (p *predicateParser) peekKeyword(keywords ...string) bool {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].isString {
+		return false
+	}
+	for _, keyword := range keywords {
+		if strings.EqualFold(p.tokens[p.pos].text, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *predicateParser) next() (tok predicateToken, err error) {
+	if p.pos >= len(p.tokens) {
+		return predicateToken{}, fmt.Errorf("predicate %q: unexpected end", p.input)
+	}
+	p.pos++
+	return p.tokens[p.pos-1], nil
+}
+
+func (p *predicateParser) parseOr() (match func(node *WDASourceNode) bool, err error) {
+	if match, err = p.parseAnd(); err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("OR", "||") {
+		p.pos++
+		var right func(node *WDASourceNode) bool
+		if right, err = p.parseAnd(); err != nil {
+			return nil, err
+		}
+		left := match
+		match = func(node *WDASourceNode) bool { return left(node) || right(node) }
+	}
+	return
+}
+
+func (p *predicateParser) parseAnd() (match func(node *WDASourceNode) bool, err error) {
+	if match, err = p.parseNot(); err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("AND", "&&") {
+		p.pos++
+		var right func(node *WDASourceNode) bool
+		if right, err = p.parseNot(); err != nil {
+			return nil, err
+		}
+		left := match
+		match = func(node *WDASourceNode) bool { return left(node) && right(node) }
+	}
+	return
+}
+
+func (p *predicateParser) parseNot() (match func(node *WDASourceNode) bool, err error) {
+	if p.peekKeyword("NOT", "!") {
+		p.pos++
+		var inner func(node *WDASourceNode) bool
+		if inner, err = p.parseNot(); err != nil {
+			return nil, err
+		}
+		return func(node *WDASourceNode) bool { return !inner(node) }, nil
+	}
+	if p.peekKeyword("(") {
+		p.pos++
+		if match, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+		if !p.peekKeyword(")") {
+			return nil, fmt.Errorf("predicate %q: missing ')'", p.input)
+		}
+		p.pos++
+		return match, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *predicateParser) parseComparison() (match func(node *WDASourceNode) bool, err error) {
+	var attr, op predicateToken
+	if attr, err = p.next(); err != nil {
+		return nil, err
+	}
+	if _, ok := new(WDASourceNode).attribute(attr.text); !ok || attr.isString {
+		return nil, fmt.Errorf("predicate %q: unknown attribute '%s'", p.input, attr.text)
+	}
+	if op, err = p.next(); err != nil {
+		return nil, err
+	}
+	name, modifier := op.text, ""
+	if i := strings.Index(name, "["); i != -1 {
+		name, modifier = name[:i], strings.ToLower(name[i:])
+	}
+	caseInsensitive := strings.Contains(modifier, "c")
+	name = strings.ToUpper(name)
+
+	var values []string
+	if name == "IN" {
+		if values, err = p.parseList(); err != nil {
+			return nil, err
+		}
+	} else {
+		var v string
+		if v, err = p.parseValue(); err != nil {
+			return nil, err
+		}
+		values = []string{v}
+	}
+
+	var compare func(v, want string) bool
+	switch name {
+	case "==", "=", "IN":
+		compare = func(v, want string) bool { return v == want }
+	case "!=", "<>":
+		compare = func(v, want string) bool { return v != want }
+	case "BEGINSWITH":
+		compare = strings.HasPrefix
+	case "ENDSWITH":
+		compare = strings.HasSuffix
+	case "CONTAINS":
+		compare = strings.Contains
+	case "LIKE", "MATCHES":
+		pattern := values[0]
+		if caseInsensitive {
+			pattern = strings.ToLower(pattern)
+		}
+		if name == "LIKE" {
+			pattern = strings.NewReplacer(`\*`, ".*", `\?`, ".").Replace(regexp.QuoteMeta(pattern))
+		}
+		var re *regexp.Regexp
+		if re, err = regexp.Compile("^(?:" + pattern + ")$"); err != nil {
+			return nil, fmt.Errorf("predicate %q: %w", p.input, err)
+		}
+		compare = func(v, _ string) bool { return re.MatchString(v) }
+	default:
+		return nil, fmt.Errorf("predicate %q: unsupported operator '%s'", p.input, op.text)
+	}
+
+	if caseInsensitive {
+		for i := range values {
+			values[i] = strings.ToLower(values[i])
+		}
+	}
+	return func(node *WDASourceNode) bool {
+		v, _ := node.attribute(attr.text)
+		if caseInsensitive {
+			v = strings.ToLower(v)
+		}
+		for _, want := range values {
+			if compare(v, want) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func (p *predicateParser) parseList() (values []string, err error) {
+	if !p.peekKeyword("{") {
+		return nil, fmt.Errorf("predicate %q: IN expects '{'", p.input)
+	}
+	p.pos++
+	for {
+		var v string
+		if v, err = p.parseValue(); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+		if p.peekKeyword(",") {
+			p.pos++
+			continue
+		}
+		if p.peekKeyword("}") {
+			p.pos++
+			return values, nil
+		}
+		return nil, fmt.Errorf("predicate %q: missing '}'", p.input)
+	}
+}
+
+// parseValue booleans are turned into the flags of attribute
+func (p *predicateParser) parseValue() (v string, err error) {
+	var tok predicateToken
+	if tok, err = p.next(); err != nil {
+		return "", err
+	}
+	if tok.isString {
+		return tok.text, nil
+	}
+	switch strings.ToUpper(tok.text) {
+	case "TRUE", "YES":
+		return "1", nil
+	case "FALSE", "NO":
+		return "0", nil
+	case "(", ")", "{", "}", ",":
+		return "", fmt.Errorf("predicate %q: unexpected '%s'", p.input, tok.text)
+	}
+	return tok.text, nil
+}
@@ -0,0 +1,66 @@
+package gwda
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestWDASnapshot_FindAll(t *testing.T) {
+	root := new(WDASourceNode)
+	checkErr(t, json.Unmarshal([]byte(testSourceJSON), root))
+	snapshot := NewWDASnapshot(root)
+
+	for _, tc := range []struct {
+		locator WDALocator
+		want    int
+	}{
+		{WDALocator{ClassName: WDAElementType{Cell: true}}, 2},
+		{WDALocator{AccessibilityId: "General"}, 1},
+		{WDALocator{LinkText: NewWDAElementAttribute().SetLabel("Wi-Fi")}, 1},
+		{WDALocator{PartialLinkText: NewWDAElementAttribute().SetLabel("ett")}, 2},
+		{WDALocator{Predicate: "type == 'XCUIElementTypeButton' AND visible == 1"}, 1},
+		{WDALocator{Predicate: "type IN {'XCUIElementTypeCell', 'XCUIElementTypeButton'} AND NOT (isEnabled == false)"}, 3},
+		{WDALocator{Predicate: "label BEGINSWITH[c] 'wi' OR name ENDSWITH 'ral'"}, 2},
+		{WDALocator{Predicate: `label LIKE 'S*s' && type != "XCUIElementTypeApplication"`}, 1},
+		{WDALocator{Predicate: "label MATCHES '[A-Z][a-z]+'"}, 4},
+		{WDALocator{Predicate: "label CONTAINS 'nothing'"}, 0},
+	} {
+		nodes, err := snapshot.FindAll(tc.locator)
+		checkErr(t, err)
+		if len(nodes) != tc.want {
+			using, value := tc.locator.getUsingAndValue()
+			t.Errorf("%s %s: got %d, want %d", using, value, len(nodes), tc.want)
+		}
+	}
+
+	node, err := snapshot.Find(WDALocator{Name: "General"})
+	checkErr(t, err)
+	if c := node.Center(); c.X != 187 || c.Y != 222 {
+		t.Error("center:", c)
+	}
+
+	for _, locator := range []WDALocator{
+		{XPath: "//XCUIElementTypeButton"},
+		{Predicate: "frame == 1"},
+		{Predicate: "label == 'a"},
+		{Predicate: "(label == 'a'"},
+		{Predicate: "label IN 'a'"},
+	} {
+		if _, err = snapshot.FindAll(locator); err == nil {
+			t.Errorf("%+v: expected an error", locator)
+		}
+	}
+}
+
+func TestSession_Snapshot(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+
+	snapshot, err := s.Snapshot()
+	checkErr(t, err)
+	node, err := snapshot.Find(WDALocator{Predicate: "type == 'XCUIElementTypeCell' AND visible == 1"})
+	checkErr(t, err)
+	checkErr(t, s.TapCoordinate(node.Center()))
+}
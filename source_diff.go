@@ -0,0 +1,284 @@
+package gwda
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WDASourceChangeKind
+type WDASourceChangeKind string
+
+const (
+	WDASourceAdded   WDASourceChangeKind = "added"
+	WDASourceRemoved WDASourceChangeKind = "removed"
+	WDASourceChanged WDASourceChangeKind = "changed"
+)
+
+// WDASourceChange
+//
+// one difference between two sources. An added or removed node counts once, with its descendants.
+type WDASourceChange struct {
+	Kind WDASourceChangeKind
+	// XPath of the node, in the `after` source unless it was removed,
+	// e.g. `/XCUIElementTypeApplication[1]/XCUIElementTypeWindow[1]/XCUIElementTypeCell[3]`
+	Path   string
+	Before *WDASourceNode // nil when added
+	After  *WDASourceNode // nil when removed
+	// the attributes which differ, when changed: `name`, `label`, `value`, `enabled`, `visible` or `rect`
+	Attributes []string
+}
+
+func (c WDASourceChange) String() string {
+	if c.Kind == WDASourceChanged {
+		return fmt.Sprintf("%s %s (%s)", c.Kind, c.Path, strings.Join(c.Attributes, ", "))
+	}
+	return fmt.Sprintf("%s %s", c.Kind, c.Path)
+}
+
+// WDASourceDiff the changes in document order
+type WDASourceDiff []WDASourceChange
+
+func (d WDASourceDiff) filter(kind WDASourceChangeKind) (changes WDASourceDiff) {
+	for _, c := range d {
+		if c.Kind == kind {
+			changes = append(changes, c)
+		}
+	}
+	return
+}
+
+func (d WDASourceDiff) Added() WDASourceDiff {
+	return d.filter(WDASourceAdded)
+}
+
+func (d WDASourceDiff) Removed() WDASourceDiff {
+	return d.filter(WDASourceRemoved)
+}
+
+func (d WDASourceDiff) Changed() WDASourceDiff {
+	return d.filter(WDASourceChanged)
+}
+
+func (d WDASourceDiff) String() string {
+	lines := make([]string, len(d))
+	for i, c := range d {
+		lines[i] = c.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SourceDiff
+//
+// Compares two outputs of Source (or Element.Source), in `xml` or `json`.
+// The children are paired by type and name (or label without name), so a cell inserted in a list
+// is one added node and not a change of all the following ones.
+//
+//	before, _ := s.Source()
+//	err = btnAdd.Click()
+//	after, _ := s.Source()
+//	diff, err := SourceDiff(before, after)
+//	if added := diff.Added(); len(added) != 1 || added[0].After.Type != "Cell" {
+//		...
+//	}
+func SourceDiff(before, after string) (diff WDASourceDiff, err error) {
+	var rootBefore, rootAfter *WDASourceNode
+	if rootBefore, err = ParseSource(before); err != nil {
+		return nil, fmt.Errorf("before: %w", err)
+	}
+	if rootAfter, err = ParseSource(after); err != nil {
+		return nil, fmt.Errorf("after: %w", err)
+	}
+	return DiffSourceTrees(rootBefore, rootAfter), nil
+}
+
+// DiffSourceTrees like SourceDiff, on decoded sources (see SourceTree)
+func DiffSourceTrees(before, after *WDASourceNode) (diff WDASourceDiff) {
+	if before == nil || after == nil || before.Type != after.Type {
+		if before != nil {
+			diff = append(diff, WDASourceChange{Kind: WDASourceRemoved, Path: "/" + before.ElementType() + "[1]", Before: before})
+		}
+		if after != nil {
+			diff = append(diff, WDASourceChange{Kind: WDASourceAdded, Path: "/" + after.ElementType() + "[1]", After: after})
+		}
+		return
+	}
+	return diffSourceNodes(diff, "/"+after.ElementType()+"[1]", before, after)
+}
+
+func diffSourceNodes(diff WDASourceDiff, nodePath string, before, after *WDASourceNode) WDASourceDiff {
+	if attributes := changedAttributes(before, after); len(attributes) != 0 {
+		diff = append(diff, WDASourceChange{Kind: WDASourceChanged, Path: nodePath, Before: before, After: after, Attributes: attributes})
+	}
+
+	pairs := pairSourceNodes(before.Children, after.Children)
+	beforePaths, afterPaths := childPaths(nodePath, before.Children), childPaths(nodePath, after.Children)
+	i, j := 0, 0
+	for _, pair := range append(pairs, [2]int{len(before.Children), len(after.Children)}) {
+		for ; i < pair[0]; i++ {
+			diff = append(diff, WDASourceChange{Kind: WDASourceRemoved, Path: beforePaths[i], Before: before.Children[i]})
+		}
+		for ; j < pair[1]; j++ {
+			diff = append(diff, WDASourceChange{Kind: WDASourceAdded, Path: afterPaths[j], After: after.Children[j]})
+		}
+		if i < len(before.Children) && j < len(after.Children) {
+			diff = diffSourceNodes(diff, afterPaths[j], before.Children[i], after.Children[j])
+			i, j = i+1, j+1
+		}
+	}
+	return diff
+}
+
+// pairSourceNodes the indexes of the children in both lists, by the longest common subsequence of their keys
+func pairSourceNodes(before, after []*WDASourceNode) (pairs [][2]int) {
+	n, m := len(before), len(after)
+	lengths := make([][]int, n+1)
+	for i := range lengths {
+		lengths[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			switch {
+			case sourceNodeKey(before[i]) == sourceNodeKey(after[j]):
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			case lengths[i+1][j] >= lengths[i][j+1]:
+				lengths[i][j] = lengths[i+1][j]
+			default:
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case sourceNodeKey(before[i]) == sourceNodeKey(after[j]):
+			pairs = append(pairs, [2]int{i, j})
+			i, j = i+1, j+1
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return
+}
+
+func sourceNodeKey(n *WDASourceNode) string {
+	if n.Name != "" {
+		return n.Type + "\x00" + n.Name
+	}
+	return n.Type + "\x00\x00" + n.Label
+}
+
+// childPaths the XPath of each child, indexed among the siblings of the same type
+func childPaths(parentPath string, children []*WDASourceNode) []string {
+	paths := make([]string, len(children))
+	counts := make(map[string]int)
+	for i, child := range children {
+		counts[child.Type]++
+		paths[i] = fmt.Sprintf("%s/%s[%d]", parentPath, child.ElementType(), counts[child.Type])
+	}
+	return paths
+}
+
+func changedAttributes(before, after *WDASourceNode) (attributes []string) {
+	if before.Name != after.Name {
+		attributes = append(attributes, "name")
+	}
+	if before.Label != after.Label {
+		attributes = append(attributes, "label")
+	}
+	if before.Value != after.Value {
+		attributes = append(attributes, "value")
+	}
+	if before.IsEnabled != after.IsEnabled {
+		attributes = append(attributes, "enabled")
+	}
+	if before.IsVisible != after.IsVisible {
+		attributes = append(attributes, "visible")
+	}
+	if !sameRect(before.Rect, after.Rect) {
+		attributes = append(attributes, "rect")
+	}
+	return
+}
+
+// ParseSource
+//
+// decodes an output of Source in `json` or `xml` (as sent by WDA, or by WDASourceNode.XML)
+func ParseSource(sTree string) (root *WDASourceNode, err error) {
+	sTree = strings.TrimSpace(sTree)
+	if strings.HasPrefix(sTree, "{") {
+		root = new(WDASourceNode)
+		if err = json.Unmarshal([]byte(sTree), root); err != nil {
+			return nil, err
+		}
+		return root, nil
+	}
+	return parseXMLSource(sTree)
+}
+
+func parseXMLSource(sTree string) (root *WDASourceNode, err error) {
+	decoder := xml.NewDecoder(strings.NewReader(sTree))
+	var stack []*WDASourceNode
+	for {
+		var token xml.Token
+		if token, err = decoder.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			// `AppiumAUT` wraps the application
+			if !strings.HasPrefix(t.Name.Local, "XCUIElementType") {
+				continue
+			}
+			node := xmlSourceNode(t)
+			if len(stack) != 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, node)
+			} else if root == nil {
+				root = node
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			if strings.HasPrefix(t.Name.Local, "XCUIElementType") && len(stack) != 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("no element in the source")
+	}
+	return root, nil
+}
+
+func xmlSourceNode(t xml.StartElement) *WDASourceNode {
+	node := &WDASourceNode{Type: strings.TrimPrefix(t.Name.Local, "XCUIElementType")}
+	for _, attr := range t.Attr {
+		switch attr.Name.Local {
+		case "name":
+			node.Name = attr.Value
+		case "label":
+			node.Label = attr.Value
+		case "value":
+			node.Value = attr.Value
+		case "enabled":
+			node.IsEnabled = attr.Value == "true"
+		case "visible":
+			node.IsVisible = attr.Value == "true"
+		case "x":
+			node.Rect.X, _ = strconv.Atoi(attr.Value)
+		case "y":
+			node.Rect.Y, _ = strconv.Atoi(attr.Value)
+		case "width":
+			node.Rect.Width, _ = strconv.Atoi(attr.Value)
+		case "height":
+			node.Rect.Height, _ = strconv.Atoi(attr.Value)
+		}
+	}
+	return node
+}
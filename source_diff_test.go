@@ -0,0 +1,45 @@
+package gwda
+
+import (
+	"testing"
+)
+
+func TestSourceDiff(t *testing.T) {
+	before := `<?xml version="1.0" encoding="UTF-8"?>
+<AppiumAUT>
+  <XCUIElementTypeApplication type="XCUIElementTypeApplication" name="Notes" enabled="true" visible="true" x="0" y="0" width="375" height="667">
+    <XCUIElementTypeCell type="XCUIElementTypeCell" label="First" enabled="true" visible="true" x="0" y="100" width="375" height="44"/>
+    <XCUIElementTypeCell type="XCUIElementTypeCell" label="Second" enabled="true" visible="true" x="0" y="144" width="375" height="44"/>
+    <XCUIElementTypeButton type="XCUIElementTypeButton" name="Add" enabled="true" visible="true" x="300" y="20" width="44" height="44"/>
+    <XCUIElementTypeStaticText type="XCUIElementTypeStaticText" name="Tip" value="Tap +" enabled="true" visible="true" x="0" y="600" width="375" height="20"/>
+  </XCUIElementTypeApplication>
+</AppiumAUT>`
+	after := `{"type":"Application","name":"Notes","isEnabled":"1","isVisible":"1","rect":{"x":0,"y":0,"width":375,"height":667},"children":[
+		{"type":"Cell","label":"First","isEnabled":"1","isVisible":"1","rect":{"x":0,"y":100,"width":375,"height":44}},
+		{"type":"Cell","label":"New","isEnabled":"1","isVisible":"1","rect":{"x":0,"y":144,"width":375,"height":44}},
+		{"type":"Cell","label":"Second","isEnabled":"1","isVisible":"1","rect":{"x":0,"y":188,"width":375,"height":44}},
+		{"type":"Button","name":"Add","isEnabled":"0","isVisible":"1","rect":{"x":300,"y":20,"width":44,"height":44}}]}`
+
+	diff, err := SourceDiff(before, after)
+	checkErr(t, err)
+	want := "added /XCUIElementTypeApplication[1]/XCUIElementTypeCell[2]\n" +
+		"changed /XCUIElementTypeApplication[1]/XCUIElementTypeCell[3] (rect)\n" +
+		"changed /XCUIElementTypeApplication[1]/XCUIElementTypeButton[1] (enabled)\n" +
+		"removed /XCUIElementTypeApplication[1]/XCUIElementTypeStaticText[1]"
+	if diff.String() != want {
+		t.Fatal(diff)
+	}
+	if added := diff.Added(); len(added) != 1 || added[0].After.Label != "New" {
+		t.Fatal(added)
+	}
+	if removed := diff.Removed(); len(removed) != 1 || removed[0].Before.Value != "Tap +" {
+		t.Fatal(removed)
+	}
+
+	if diff, err = SourceDiff(after, after); err != nil || len(diff) != 0 {
+		t.Fatal(diff, err)
+	}
+	if _, err = SourceDiff("<AppiumAUT/>", after); err == nil {
+		t.Fatal("expected an error without element")
+	}
+}
@@ -0,0 +1,101 @@
+package gwda
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// WDASourceNode
+//
+// an element of the JSON source (see SourceTree), `Type` is the short name, e.g. `Button`
+type WDASourceNode struct {
+	Type          string           `json:"type"`
+	Name          string           `json:"name"`
+	Label         string           `json:"label"`
+	Value         string           `json:"value"`
+	RawIdentifier string           `json:"rawIdentifier"`
+	Rect          WDARect          `json:"rect"`
+	IsEnabled     bool             `json:"isEnabled"`
+	IsVisible     bool             `json:"isVisible"`
+	Children      []*WDASourceNode `json:"children"`
+}
+
+// UnmarshalJSON
+//
+// WDA sends the flags as `"1"`/`"0"`, and `value` is not always a string
+func (n *WDASourceNode) UnmarshalJSON(data []byte) (err error) {
+	type plain WDASourceNode
+	var v struct {
+		*plain
+		Name          json.RawMessage `json:"name"`
+		Label         json.RawMessage `json:"label"`
+		Value         json.RawMessage `json:"value"`
+		RawIdentifier json.RawMessage `json:"rawIdentifier"`
+		IsEnabled     json.RawMessage `json:"isEnabled"`
+		IsVisible     json.RawMessage `json:"isVisible"`
+	}
+	v.plain = (*plain)(n)
+	if err = json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	n.Name, n.Label, n.Value, n.RawIdentifier = sourceText(v.Name), sourceText(v.Label), sourceText(v.Value), sourceText(v.RawIdentifier)
+	n.IsEnabled, n.IsVisible = sourceFlag(v.IsEnabled), sourceFlag(v.IsVisible)
+	return nil
+}
+
+func sourceText(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	if string(raw) == "null" {
+		return ""
+	}
+	return string(raw)
+}
+
+func sourceFlag(raw json.RawMessage) bool {
+	switch strings.Trim(string(raw), `"`) {
+	case "1", "true":
+		return true
+	default:
+		return false
+	}
+}
+
+// ElementType the full name, e.g. `XCUIElementTypeButton`
+func (n *WDASourceNode) ElementType() string {
+	return "XCUIElementType" + n.Type
+}
+
+// Walk
+//
+// visits the node and its descendants depth first, `fn` returning `false` skips the descendants of that node
+func (n *WDASourceNode) Walk(fn func(node *WDASourceNode) bool) {
+	if !fn(n) {
+		return
+	}
+	for _, child := range n.Children {
+		child.Walk(fn)
+	}
+}
+
+// SourceTree
+//
+// the JSON source decoded into a tree
+func (s *Session) SourceTree() (root *WDASourceNode, err error) {
+	return sourceTree(s.client, s.sessionURL())
+}
+
+func sourceTree(c *Client, baseUrl *url.URL) (root *WDASourceNode, err error) {
+	var wdaResp wdaResponse
+	if wdaResp, err = executeGet(c, "Source", sourceURL(baseUrl, NewWDASourceOption().SetFormatAsJson())); err != nil {
+		return nil, err
+	}
+	root = new(WDASourceNode)
+	if _, err = wdaResp.unmarshalValue(root); err != nil {
+		return nil, err
+	}
+	return
+}
@@ -0,0 +1,381 @@
+package gwda
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"unicode/utf8"
+)
+
+// SourceReader
+//
+// same as Source, but the tree is streamed instead of being buffered, the caller must close it
+func (c *Client) SourceReader(srcOpt ...WDASourceOption) (rc io.ReadCloser, err error) {
+	return sourceReader(c, c.deviceURL, srcOpt...)
+}
+
+// AccessibleSourceReader
+//
+// same as AccessibleSource, but streamed, the caller must close it
+func (c *Client) AccessibleSourceReader() (rc io.ReadCloser, err error) {
+	return accessibleSourceReader(c, c.deviceURL)
+}
+
+// ScreenshotReader
+//
+// the decoded (PNG) screenshot, streamed from the response, the caller must close it
+func (c *Client) ScreenshotReader() (rc io.ReadCloser, err error) {
+	return screenshotReader(c, c.deviceURL)
+}
+
+// SourceReader
+//
+// same as Source, but the tree is streamed instead of being buffered, the caller must close it
+func (s *Session) SourceReader(srcOpt ...WDASourceOption) (rc io.ReadCloser, err error) {
+	return sourceReader(s.client, s.sessionURL(), srcOpt...)
+}
+
+// AccessibleSourceReader
+//
+// same as AccessibleSource, but streamed, the caller must close it
+func (s *Session) AccessibleSourceReader() (rc io.ReadCloser, err error) {
+	return accessibleSourceReader(s.client, s.sessionURL())
+}
+
+// ScreenshotReader
+//
+// the decoded (PNG) screenshot, streamed from the response, the caller must close it
+func (s *Session) ScreenshotReader(element ...*Element) (rc io.ReadCloser, err error) {
+	return screenshotReader(s.client, s.sessionURL(), element...)
+}
+
+// ScreenshotReader
+//
+// the decoded (PNG) screenshot, streamed from the response, the caller must close it
+func (e *Element) ScreenshotReader() (rc io.ReadCloser, err error) {
+	return screenshotReader(e.client, e._withFormatToUrl())
+}
+
+func sourceReader(c *Client, baseUrl *url.URL, srcOpt ...WDASourceOption) (rc io.ReadCloser, err error) {
+	if rc, err = executeStream(c, "Source", http.MethodGet, sourceURL(baseUrl, srcOpt...), nil); err != nil {
+		return nil, err
+	}
+	return newValueReader("Source", rc, false)
+}
+
+func accessibleSourceReader(c *Client, baseUrl *url.URL) (rc io.ReadCloser, err error) {
+	if rc, err = executeStream(c, "AccessibleSource", http.MethodGet, urlJoin(baseUrl, "/wda/accessibleSource"), nil); err != nil {
+		return nil, err
+	}
+	return newValueReader("AccessibleSource", rc, false)
+}
+
+func screenshotReader(c *Client, baseUrl *url.URL, element ...*Element) (rc io.ReadCloser, err error) {
+	tmpPath := "/screenshot"
+	if len(element) != 0 && element[0].UID != "" {
+		tmpPath += "/" + element[0].UID
+	}
+	if rc, err = executeStream(c, "Screenshot", http.MethodGet, urlJoin(baseUrl, tmpPath), nil); err != nil {
+		return nil, err
+	}
+	var vr *valueReader
+	if vr, err = newValueReader("Screenshot", rc, true); err != nil {
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{base64.NewDecoder(base64.StdEncoding, vr), vr}, nil
+}
+
+// valueReader
+//
+// Streams the `value` of a WDA response: the content of a string (unescaped), otherwise the raw JSON.
+//
+//	{"value": "<?xml ...", "sessionId": "..."}
+type valueReader struct {
+	actionName string
+	r          *bufio.Reader
+	rc         io.ReadCloser
+
+	isString bool
+	depth    int // of a raw value
+	inString bool
+	escaped  bool
+
+	pending []byte
+	err     error
+}
+
+// newValueReader
+//
+// `wantString` the value must be a string, an object in its place is turned into an error by getErrMsg
+func newValueReader(actionName string, rc io.ReadCloser, wantString bool) (vr *valueReader, err error) {
+	vr = &valueReader{actionName: actionName, r: bufio.NewReader(rc), rc: rc}
+	if err = vr.seekValue(); err != nil {
+		_ = rc.Close()
+		return nil, vr.wrapErr(err)
+	}
+	if wantString && !vr.isString {
+		defer rc.Close()
+		var raw []byte
+		if raw, err = ioutil.ReadAll(io.LimitReader(vr, 1<<20)); err != nil {
+			return nil, vr.wrapErr(err)
+		}
+		bsResp, _ := json.Marshal(newWdaBody().set("value", json.RawMessage(raw)))
+		if err = wdaResponse(bsResp).getErrMsg(); err == nil {
+			err = fmt.Errorf("%s: unexpected value %s", actionName, raw)
+		}
+		return nil, err
+	}
+	return vr, nil
+}
+
+func (vr *valueReader) Close() error {
+	return vr.rc.Close()
+}
+
+func (vr *valueReader) Read(p []byte) (n int, err error) {
+	for n < len(p) {
+		if len(vr.pending) != 0 {
+			c := copy(p[n:], vr.pending)
+			vr.pending = vr.pending[c:]
+			n += c
+			continue
+		}
+		if vr.err != nil {
+			break
+		}
+		if vr.isString {
+			vr.readString()
+		} else {
+			vr.readRaw()
+		}
+	}
+	if n == 0 {
+		return 0, vr.wrapErr(vr.err)
+	}
+	return n, nil
+}
+
+// readString the next run of plain characters or one escape into pending
+func (vr *valueReader) readString() {
+	if _, err := vr.r.Peek(1); err != nil {
+		vr.err = unexpectedEOF(err)
+		return
+	}
+	chunk, _ := vr.r.Peek(vr.r.Buffered())
+	if i := bytes.IndexAny(chunk, `"\`); i != 0 {
+		if i != -1 {
+			chunk = chunk[:i]
+		}
+		vr.pending = append(vr.pending[:0], chunk...)
+		_, _ = vr.r.Discard(len(chunk))
+		return
+	}
+
+	b, _ := vr.r.ReadByte()
+	if b == '"' {
+		vr.err = io.EOF
+		return
+	}
+	r, err := vr.readEscape()
+	if err != nil {
+		vr.err = err
+		return
+	}
+	var buf [utf8.UTFMax]byte
+	vr.pending = append(vr.pending[:0], buf[:utf8.EncodeRune(buf[:], r)]...)
+}
+
+// readRaw copies a chunk of the raw value into pending, until the value is balanced
+func (vr *valueReader) readRaw() {
+	vr.pending = vr.pending[:0]
+	for len(vr.pending) < 4096 {
+		b, err := vr.r.ReadByte()
+		if err != nil {
+			vr.err = unexpectedEOF(err)
+			return
+		}
+		if vr.depth == 0 && !vr.inString && (b == ',' || b == '}') {
+			// end of a scalar
+			_ = vr.r.UnreadByte()
+			vr.err = io.EOF
+			return
+		}
+		vr.pending = append(vr.pending, b)
+		switch {
+		case vr.escaped:
+			vr.escaped = false
+		case vr.inString:
+			if b == '\\' {
+				vr.escaped = true
+			} else if b == '"' {
+				vr.inString = false
+			}
+		case b == '"':
+			vr.inString = true
+		case b == '{' || b == '[':
+			vr.depth++
+		case b == '}' || b == ']':
+			if vr.depth--; vr.depth == 0 {
+				vr.err = io.EOF
+				return
+			}
+		}
+	}
+}
+
+func (vr *valueReader) readEscape() (r rune, err error) {
+	var b byte
+	if b, err = vr.r.ReadByte(); err != nil {
+		return 0, unexpectedEOF(err)
+	}
+	switch b {
+	case '"', '\\', '/':
+		return rune(b), nil
+	case 'b':
+		return '\b', nil
+	case 'f':
+		return '\f', nil
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case 'u':
+		if r, err = vr.readHex4(); err != nil {
+			return 0, err
+		}
+		if r < 0xD800 || r >= 0xDC00 {
+			return r, nil
+		}
+		// surrogate pair
+		var next []byte
+		if next, err = vr.r.Peek(2); err != nil || string(next) != `\u` {
+			return utf8.RuneError, nil
+		}
+		_, _ = vr.r.Discard(2)
+		var r2 rune
+		if r2, err = vr.readHex4(); err != nil {
+			return 0, err
+		}
+		return 0x10000 + (r-0xD800)<<10 + (r2 - 0xDC00), nil
+	default:
+		return 0, fmt.Errorf("invalid escape '\\%c'", b)
+	}
+}
+
+func (vr *valueReader) readHex4() (r rune, err error) {
+	var hex [4]byte
+	if _, err = io.ReadFull(vr.r, hex[:]); err != nil {
+		return 0, unexpectedEOF(err)
+	}
+	var v uint64
+	if v, err = strconv.ParseUint(string(hex[:]), 16, 32); err != nil {
+		return 0, fmt.Errorf("invalid escape '\\u%s'", hex[:])
+	}
+	return rune(v), nil
+}
+
+// seekValue positions the reader at the start of the `value` of the top level object
+func (vr *valueReader) seekValue() (err error) {
+	if err = vr.expect('{'); err != nil {
+		return err
+	}
+	for {
+		var b byte
+		if b, err = vr.skipSpace(); err != nil {
+			return err
+		}
+		switch b {
+		case ',':
+			continue
+		case '}':
+			return errors.New("missing value")
+		case '"':
+		default:
+			return fmt.Errorf("unexpected '%c'", b)
+		}
+		var key []byte
+		if key, err = vr.readKey(); err != nil {
+			return err
+		}
+		if err = vr.expect(':'); err != nil {
+			return err
+		}
+		if b, err = vr.skipSpace(); err != nil {
+			return err
+		}
+		if string(key) == "value" {
+			if b == '"' {
+				vr.isString = true
+			} else {
+				_ = vr.r.UnreadByte()
+			}
+			return nil
+		}
+		// skip it
+		_ = vr.r.UnreadByte()
+		skip := &valueReader{r: vr.r}
+		if _, err = io.Copy(ioutil.Discard, skip); err != nil {
+			return err
+		}
+	}
+}
+
+func (vr *valueReader) readKey() (key []byte, err error) {
+	var raw []byte
+	if raw, err = vr.r.ReadBytes('"'); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	// keys of WDA responses don't contain escapes
+	return bytes.TrimSuffix(raw, []byte{'"'}), nil
+}
+
+func (vr *valueReader) expect(want byte) error {
+	b, err := vr.skipSpace()
+	if err != nil {
+		return err
+	}
+	if b != want {
+		return fmt.Errorf("expected '%c', got '%c'", want, b)
+	}
+	return nil
+}
+
+func (vr *valueReader) skipSpace() (b byte, err error) {
+	for {
+		if b, err = vr.r.ReadByte(); err != nil {
+			return 0, unexpectedEOF(err)
+		}
+		switch b {
+		case ' ', '\t', '\n', '\r':
+		default:
+			return b, nil
+		}
+	}
+}
+
+func (vr *valueReader) wrapErr(err error) error {
+	if err == nil || err == io.EOF || vr.actionName == "" {
+		return err
+	}
+	return fmt.Errorf("%s: failed to read response %w", vr.actionName, err)
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,68 @@
+package gwda
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func Test_valueReader(t *testing.T) {
+	for _, tc := range []struct {
+		resp       string
+		wantString bool
+		want       string
+	}{
+		{`{"value":"<a b=\"1\">\n\u4e2d\ud83d\ude00\/</a>","sessionId":"S"}`, true, "<a b=\"1\">\n中😀/</a>"},
+		{`{ "sessionId" : "S", "other": {"x": [1, "}"]}, "value" : "ok" }`, true, "ok"},
+		{`{"value":{"type":"Application","children":[{"label":"a\"}"}]},"sessionId":"S"}`, false, `{"type":"Application","children":[{"label":"a\"}"}]}`},
+		{`{"value":null,"sessionId":"S"}`, false, `null`},
+	} {
+		vr, err := newValueReader("Test", ioutil.NopCloser(strings.NewReader(tc.resp)), tc.wantString)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// small buffer on purpose
+		got, err := ioutil.ReadAll(io.LimitReader(vr, 1<<20))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("%s:\n got %q\nwant %q", tc.resp, got, tc.want)
+		}
+	}
+
+	_, err := newValueReader("Test", ioutil.NopCloser(strings.NewReader(`{"value":{"error":"unknown error","message":"boom"}}`)), true)
+	if err == nil || err.Error() != "unknown error: boom" {
+		t.Errorf("error value: %v", err)
+	}
+
+	vr, err := newValueReader("Test", ioutil.NopCloser(strings.NewReader(`{"value":"trunc`)), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ioutil.ReadAll(vr); err == nil {
+		t.Error("truncated response: expected an error")
+	}
+}
+
+func TestSession_SourceReader(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+
+	rc, err := s.SourceReader()
+	checkErr(t, err)
+	n, err := io.Copy(ioutil.Discard, rc)
+	checkErr(t, err)
+	checkErr(t, rc.Close())
+	t.Log("source", n, "bytes")
+
+	rc, err = s.ScreenshotReader()
+	checkErr(t, err)
+	n, err = io.Copy(ioutil.Discard, rc)
+	checkErr(t, err)
+	checkErr(t, rc.Close())
+	t.Log("screenshot", n, "bytes")
+}
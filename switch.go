@@ -0,0 +1,56 @@
+package gwda
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetSwitch
+//
+// Turns a switch on or off: taps it only when it is not already in the state, then reads its value again to verify it,
+// so running a step twice does not toggle it back.
+func (e *Element) SetSwitch(on bool) (err error) {
+	var current bool
+	if current, err = e.switchValue(); err != nil {
+		return err
+	}
+	if current == on {
+		return nil
+	}
+	if err = e.Click(); err != nil {
+		return err
+	}
+	if current, err = e.switchValue(); err != nil {
+		return err
+	}
+	if current != on {
+		return fmt.Errorf("switch is still %s after a tap", switchState(current))
+	}
+	return nil
+}
+
+// IsSwitchOn the state of a switch
+func (e *Element) IsSwitchOn() (bool, error) {
+	return e.switchValue()
+}
+
+func (e *Element) switchValue() (on bool, err error) {
+	var value string
+	if value, err = e.Value(); err != nil {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "true", "on":
+		return true, nil
+	case "0", "false", "off", "":
+		return false, nil
+	}
+	return false, fmt.Errorf("not a switch value: '%s'", value)
+}
+
+func switchState(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
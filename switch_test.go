@@ -0,0 +1,22 @@
+package gwda
+
+import "testing"
+
+func TestElement_SetSwitch(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+
+	element, err := s.FindElement(WDALocator{ClassName: WDAElementType{Switch: true}})
+	checkErr(t, err)
+	on, err := element.IsSwitchOn()
+	checkErr(t, err)
+
+	checkErr(t, element.SetSwitch(!on))
+	checkErr(t, element.SetSwitch(!on))
+	if current, _ := element.IsSwitchOn(); current == on {
+		t.Fatal("the switch did not change")
+	}
+	checkErr(t, element.SetSwitch(on))
+}
@@ -0,0 +1,120 @@
+package gwda
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// WDATimeouts
+//
+// W3C session timeouts, the zero value of a new session is NewWDATimeouts
+type WDATimeouts struct {
+	Implicit time.Duration // how long FindElement and FindElements keep looking for the elements
+	PageLoad time.Duration
+	Script   time.Duration
+}
+
+// NewWDATimeouts the W3C defaults
+func NewWDATimeouts() WDATimeouts {
+	return WDATimeouts{PageLoad: 300 * time.Second, Script: 30 * time.Second}
+}
+
+type wdaTimeoutsBody struct {
+	Implicit *int64 `json:"implicit,omitempty"`
+	PageLoad *int64 `json:"pageLoad,omitempty"`
+	Script   *int64 `json:"script,omitempty"`
+}
+
+func (t WDATimeouts) body() wdaBody {
+	return newWdaBody().
+		set("implicit", t.Implicit.Milliseconds()).
+		set("pageLoad", t.PageLoad.Milliseconds()).
+		set("script", t.Script.Milliseconds())
+}
+
+// SetTimeouts
+//
+// Sends the timeouts to `/timeouts`. The WDA builds which accept but ignore them (like the upstream ones),
+// or do not know the endpoint at all, get the implicit wait done by gwda instead.
+func (s *Session) SetTimeouts(timeouts WDATimeouts) (err error) {
+	if _, err = executePost(s.client, "SetTimeouts", urlJoin(s.sessionURL(), "/timeouts"), timeouts.body()); err != nil && !isUnsupportedCommand(err) {
+		return err
+	}
+
+	remote, errGet := s.remoteTimeouts()
+	s.timeoutsMutex.Lock()
+	defer s.timeoutsMutex.Unlock()
+	s.timeouts = &timeouts
+	s.implicitByWDA = errGet == nil && remote.Implicit == timeouts.Implicit
+	return nil
+}
+
+// GetTimeouts
+//
+// as reported by WDA, or as set by SetTimeouts when WDA does not report them
+func (s *Session) GetTimeouts() (timeouts WDATimeouts, err error) {
+	if timeouts, err = s.remoteTimeouts(); err == nil || !isUnsupportedCommand(err) {
+		return
+	}
+	return s.localTimeouts(), nil
+}
+
+func (s *Session) remoteTimeouts() (timeouts WDATimeouts, err error) {
+	var wdaResp wdaResponse
+	if wdaResp, err = executeGet(s.client, "GetTimeouts", urlJoin(s.sessionURL(), "/timeouts")); err != nil {
+		return
+	}
+	var body wdaTimeoutsBody
+	if _, err = wdaResp.unmarshalValue(&body); err != nil {
+		return
+	}
+	timeouts = s.localTimeouts()
+	for _, v := range []struct {
+		ms *int64
+		d  *time.Duration
+	}{{body.Implicit, &timeouts.Implicit}, {body.PageLoad, &timeouts.PageLoad}, {body.Script, &timeouts.Script}} {
+		if v.ms != nil {
+			*v.d = time.Duration(*v.ms) * time.Millisecond
+		}
+	}
+	return
+}
+
+func (s *Session) localTimeouts() WDATimeouts {
+	s.timeoutsMutex.Lock()
+	defer s.timeoutsMutex.Unlock()
+	if s.timeouts == nil {
+		return NewWDATimeouts()
+	}
+	return *s.timeouts
+}
+
+// implicitWait retries `find` until the implicit timeout when WDA does not wait on its own
+func (s *Session) implicitWait(find func() error) (err error) {
+	s.timeoutsMutex.Lock()
+	var implicit time.Duration
+	if s.timeouts != nil && !s.implicitByWDA {
+		implicit = s.timeouts.Implicit
+	}
+	s.timeoutsMutex.Unlock()
+
+	deadline := time.Now().Add(implicit)
+	for {
+		if err = find(); err == nil || !isNoSuchElement(err) || time.Now().Add(waitInterval()).After(deadline) {
+			return
+		}
+		time.Sleep(waitInterval())
+	}
+}
+
+func isNoSuchElement(err error) bool {
+	return errors.Is(err, ErrNoSuchElement) || strings.Contains(err.Error(), "no such element")
+}
+
+// isUnsupportedCommand the endpoint is unknown to the WDA build
+func isUnsupportedCommand(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "unknown command") || strings.Contains(msg, "unknown method") ||
+		strings.Contains(msg, "Unhandled endpoint")
+}
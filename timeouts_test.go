@@ -0,0 +1,35 @@
+package gwda
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWDATimeouts_body(t *testing.T) {
+	body := WDATimeouts{Implicit: 1500 * time.Millisecond, PageLoad: time.Minute}.body()
+	if body["implicit"] != int64(1500) || body["pageLoad"] != int64(60000) || body["script"] != int64(0) {
+		t.Fatalf("unexpected body: %v", body)
+	}
+}
+
+func TestSession_SetTimeouts(t *testing.T) {
+	client, err := NewClient(deviceURL)
+	checkErr(t, err)
+	session, err := client.NewSession()
+	checkErr(t, err)
+
+	checkErr(t, session.SetTimeouts(WDATimeouts{Implicit: 2 * time.Second}))
+	timeouts, err := session.GetTimeouts()
+	checkErr(t, err)
+	if timeouts.Implicit != 2*time.Second {
+		t.Fatalf("implicit: %v", timeouts.Implicit)
+	}
+
+	start := time.Now()
+	if _, err = session.FindElement(WDALocator{Name: "no-such-element"}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if elapsed := time.Since(start); elapsed < 1500*time.Millisecond {
+		t.Fatalf("returned after %v", elapsed)
+	}
+}
@@ -0,0 +1,63 @@
+package gwda
+
+import (
+	"time"
+)
+
+// TapTrackingSampleGap between the two samples of the rect, long enough for a settling list to move measurably
+var TapTrackingSampleGap = 100 * time.Millisecond
+
+// TapTracking
+//
+// Taps an element that may still be moving, e.g. cells animating into place. The rect of the first element matched by
+// `query` is sampled twice, its motion is extrapolated `predictionWindow` ahead of the second sample and the center of
+// the predicted rect is tapped. `0` predicts as far ahead as the second sample took to come back,
+// an estimation of the time until the tap arrives.
+func (s *Session) TapTracking(query *Query, predictionWindow time.Duration) (err error) {
+	var elem *Element
+	if elem, err = query.First(); err != nil {
+		return err
+	}
+
+	var rect1, rect2 WDARect
+	var t1, t2 time.Time
+	var latency time.Duration
+	if rect1, t1, _, err = sampleRect(elem); err != nil {
+		return err
+	}
+	time.Sleep(TapTrackingSampleGap)
+	if rect2, t2, latency, err = sampleRect(elem); err != nil {
+		return err
+	}
+	if predictionWindow == 0 {
+		predictionWindow = latency
+	}
+
+	x, y := predictCenter(rect1, rect2, t2.Sub(t1), predictionWindow)
+	return s.TapFloat(x, y)
+}
+
+// sampleRect `at` is the middle of the request, the best guess of when WDA read the rect
+func sampleRect(elem *Element) (rect WDARect, at time.Time, latency time.Duration, err error) {
+	start := time.Now()
+	if rect, err = elem.Rect(); err != nil {
+		return WDARect{}, time.Time{}, 0, err
+	}
+	latency = time.Since(start)
+	return rect, start.Add(latency / 2), latency, nil
+}
+
+// predictCenter extrapolates the motion of the center linearly
+func predictCenter(rect1, rect2 WDARect, elapsed, ahead time.Duration) (x, y float64) {
+	x1, y1 := rectCenter(rect1)
+	x2, y2 := rectCenter(rect2)
+	if elapsed <= 0 {
+		return x2, y2
+	}
+	factor := float64(ahead) / float64(elapsed)
+	return x2 + (x2-x1)*factor, y2 + (y2-y1)*factor
+}
+
+func rectCenter(rect WDARect) (x, y float64) {
+	return float64(rect.X) + float64(rect.Width)/2, float64(rect.Y) + float64(rect.Height)/2
+}
@@ -0,0 +1,28 @@
+package gwda
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_predictCenter(t *testing.T) {
+	rect1 := WDARect{WDACoordinate{X: 0, Y: 400}, WDASize{Width: 100, Height: 40}}
+	rect2 := WDARect{WDACoordinate{X: 0, Y: 300}, WDASize{Width: 100, Height: 40}}
+	x, y := predictCenter(rect1, rect2, 100*time.Millisecond, 50*time.Millisecond)
+	if x != 50 || y != 270 {
+		t.Error(x, y)
+	}
+	if x, y = predictCenter(rect2, rect2, 100*time.Millisecond, time.Second); x != 50 || y != 320 {
+		t.Error("still:", x, y)
+	}
+}
+
+func TestSession_TapTracking(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	checkErr(t, s.AppLaunch(bundleId))
+
+	checkErr(t, s.TapTracking(s.Query().By(WDALocator{Name: "通用"}), 0))
+}
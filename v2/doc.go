@@ -0,0 +1,12 @@
+// Package gwda is the v2 API of github.com/electricbubble/gwda.
+//
+// It shares the implementation, and so the state, of the v1 package: the types are aliases of the v1 ones,
+// a *Session of either version can be passed to the other. The differences are
+//
+//	NewElement(s, id)      takes an ElementID, see Element.ID
+//	DefaultWaitTimeout...  the package settings are pointers to the v1 ones: *gwda.DefaultWaitTimeout = time.Minute
+//
+// The declarations forwarding to v1 are generated in facade.go, see internal/gen.
+package gwda
+
+//go:generate go run ./internal/gen
@@ -0,0 +1,13 @@
+package gwda
+
+import (
+	v1 "github.com/electricbubble/gwda"
+)
+
+// NewElement
+//
+// Element of the session by its ID, e.g. an `ELEMENT` returned by a raw WDA call or kept by another tool.
+// Nothing is requested, the element may no longer exist.
+func NewElement(s *Session, id ElementID) *Element {
+	return v1.NewElement(s, string(id))
+}
@@ -0,0 +1,52 @@
+package gwda
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	v1 "github.com/electricbubble/gwda"
+)
+
+func TestNewElement(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			_, _ = w.Write([]byte("I-AM-ALIVE"))
+		case "/session/S1":
+			_, _ = w.Write([]byte(`{"value":{"sessionId":"S1","capabilities":{}},"sessionId":"S1"}`))
+		case "/session/S1/element/E1/text":
+			_, _ = w.Write([]byte(`{"value":"Wi-Fi","sessionId":"S1"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := c.AttachSession("S1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	element := NewElement(s, "E1")
+	if element.ID() != "E1" {
+		t.Fatalf("got %s", element.ID())
+	}
+	if text, err := element.Text(); err != nil || text != "Wi-Fi" {
+		t.Fatalf("got %q, %v", text, err)
+	}
+}
+
+func TestSettings(t *testing.T) {
+	timeout := *DefaultOpenURLTimeout
+	defer func() { *DefaultOpenURLTimeout = timeout }()
+
+	*DefaultOpenURLTimeout = time.Minute
+	if v1.DefaultOpenURLTimeout != time.Minute {
+		t.Fatal("the settings must be shared with v1")
+	}
+}
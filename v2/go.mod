@@ -0,0 +1,7 @@
+module github.com/electricbubble/gwda/v2
+
+go 1.13
+
+require github.com/electricbubble/gwda v0.0.0
+
+replace github.com/electricbubble/gwda => ../
@@ -0,0 +1,331 @@
+// Command gen writes facade.go, the v2 declarations forwarding to the exported API of the v1 package.
+//
+// The types and constants are aliased, the functions forward their arguments, the errors are the same values
+// and the other variables, the package settings, are pointers to the v1 ones so that setting them still
+// changes the behaviour of the package. The names declared by hand in v2 are skipped.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const v1Path = "github.com/electricbubble/gwda"
+
+func main() {
+	src, err := generate("..", ".")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = ioutil.WriteFile("facade.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generate facade.go from the v1 package in `v1Dir` for the v2 package in `v2Dir`
+func generate(v1Dir, v2Dir string) ([]byte, error) {
+	v1, err := parsePackage(v1Dir)
+	if err != nil {
+		return nil, err
+	}
+	v2, err := parsePackage(v2Dir)
+	if err != nil {
+		return nil, err
+	}
+	handwritten := make(map[string]bool)
+	for _, f := range v2.files {
+		if f.name == "facade.go" {
+			continue
+		}
+		for name := range declaredNames(f.file) {
+			handwritten[name] = true
+		}
+	}
+
+	g := &generator{types: make(map[string]bool), imports: make(map[string]string)}
+	for _, f := range v1.files {
+		for _, d := range f.file.Decls {
+			if gd, ok := d.(*ast.GenDecl); ok && gd.Tok == token.TYPE {
+				for _, spec := range gd.Specs {
+					g.types[spec.(*ast.TypeSpec).Name.Name] = true
+				}
+			}
+		}
+	}
+
+	var types, consts, errs, settings, funcs []decl
+	for _, f := range v1.files {
+		for _, d := range f.file.Decls {
+			switch d := d.(type) {
+			case *ast.FuncDecl:
+				if d.Recv != nil || !d.Name.IsExported() || handwritten[d.Name.Name] {
+					continue
+				}
+				code, err := g.function(f, d)
+				if err != nil {
+					return nil, err
+				}
+				funcs = append(funcs, decl{d.Name.Name, docOf(d.Doc), code})
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						if !spec.Name.IsExported() || handwritten[spec.Name.Name] {
+							continue
+						}
+						doc := spec.Doc
+						if doc == nil && len(d.Specs) == 1 {
+							doc = d.Doc
+						}
+						name := spec.Name.Name
+						types = append(types, decl{name, docOf(doc), fmt.Sprintf("%s = v1.%s", name, name)})
+					case *ast.ValueSpec:
+						doc := spec.Doc
+						if doc == nil && len(d.Specs) == 1 {
+							doc = d.Doc
+						}
+						for _, ident := range spec.Names {
+							name := ident.Name
+							if !ident.IsExported() || handwritten[name] {
+								continue
+							}
+							switch {
+							case d.Tok == token.CONST:
+								consts = append(consts, decl{name, docOf(doc), fmt.Sprintf("%s = v1.%s", name, name)})
+							case strings.HasPrefix(name, "Err"):
+								errs = append(errs, decl{name, docOf(doc), fmt.Sprintf("%s = v1.%s", name, name)})
+							default:
+								settings = append(settings, decl{name, docOf(doc), fmt.Sprintf("%s = &v1.%s", name, name)})
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by internal/gen; DO NOT EDIT.\n\npackage gwda\n\nimport (\n")
+	var std []string
+	for _, path := range g.imports {
+		std = append(std, path)
+	}
+	sort.Strings(std)
+	for _, path := range std {
+		fmt.Fprintf(&buf, "\t%q\n", path)
+	}
+	fmt.Fprintf(&buf, "\n\tv1 %q\n)\n", v1Path)
+	writeGroup(&buf, "type", "", types)
+	writeGroup(&buf, "const", "", consts)
+	writeGroup(&buf, "var", "", errs)
+	writeGroup(&buf, "var", "// The settings of the v1 package, shared with it.\n", settings)
+	sort.Slice(funcs, func(i, j int) bool { return funcs[i].name < funcs[j].name })
+	for _, fn := range funcs {
+		buf.WriteString("\n" + fn.doc + fn.code + "\n")
+	}
+	return format.Source(buf.Bytes())
+}
+
+type decl struct {
+	name string
+	doc  string
+	code string
+}
+
+func writeGroup(buf *bytes.Buffer, tok, doc string, decls []decl) {
+	if len(decls) == 0 {
+		return
+	}
+	sort.Slice(decls, func(i, j int) bool { return decls[i].name < decls[j].name })
+	fmt.Fprintf(buf, "\n%s%s (\n", doc, tok)
+	for _, d := range decls {
+		buf.WriteString(indent(d.doc) + "\t" + d.code + "\n")
+	}
+	buf.WriteString(")\n")
+}
+
+func docOf(cg *ast.CommentGroup) string {
+	if cg == nil {
+		return ""
+	}
+	var buf strings.Builder
+	for _, c := range cg.List {
+		buf.WriteString(c.Text + "\n")
+	}
+	return buf.String()
+}
+
+func indent(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "\t" + strings.Replace(strings.TrimSuffix(s, "\n"), "\n", "\n\t", -1) + "\n"
+}
+
+type sourceFile struct {
+	name string
+	file *ast.File
+}
+
+type sourcePackage struct {
+	files []sourceFile
+}
+
+func parsePackage(dir string) (*sourcePackage, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	pkg, ok := pkgs["gwda"]
+	if !ok {
+		return nil, fmt.Errorf("%s: no package gwda", dir)
+	}
+	var sp sourcePackage
+	for name, f := range pkg.Files {
+		sp.files = append(sp.files, sourceFile{name: name[strings.LastIndex(name, string(os.PathSeparator))+1:], file: f})
+	}
+	// in a stable order, facade.go is compared by its test
+	sort.Slice(sp.files, func(i, j int) bool { return sp.files[i].name < sp.files[j].name })
+	return &sp, nil
+}
+
+func declaredNames(f *ast.File) map[string]bool {
+	names := make(map[string]bool)
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil {
+				names[d.Name.Name] = true
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					names[spec.Name.Name] = true
+				case *ast.ValueSpec:
+					for _, ident := range spec.Names {
+						names[ident.Name] = true
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+type generator struct {
+	types   map[string]bool   // declared by v1
+	imports map[string]string // name: path
+}
+
+// function forwarding to the v1 function `d`
+func (g *generator) function(f sourceFile, d *ast.FuncDecl) (string, error) {
+	ft := &ast.FuncType{Params: g.fields(f, d.Type.Params, "arg"), Results: g.fields(f, d.Type.Results, "")}
+
+	var args []string
+	variadic := false
+	for _, field := range ft.Params.List {
+		for _, name := range field.Names {
+			args = append(args, name.Name)
+		}
+		_, variadic = field.Type.(*ast.Ellipsis)
+	}
+	call := fmt.Sprintf("v1.%s(%s", d.Name.Name, strings.Join(args, ", "))
+	if variadic {
+		call += "..."
+	}
+	call += ")"
+	if ft.Results != nil && len(ft.Results.List) != 0 {
+		call = "return " + call
+	}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, token.NewFileSet(), ft); err != nil {
+		return "", err
+	}
+	signature := strings.TrimPrefix(buf.String(), "func")
+	return fmt.Sprintf("func %s%s {\n\t%s\n}", d.Name.Name, signature, call), nil
+}
+
+// fields with the types qualified for v2, the unnamed parameters are named with `prefix`
+func (g *generator) fields(f sourceFile, list *ast.FieldList, prefix string) *ast.FieldList {
+	if list == nil {
+		return nil
+	}
+	out := &ast.FieldList{}
+	for i, field := range list.List {
+		var names []*ast.Ident
+		for j, name := range field.Names {
+			if name.Name == "_" || name.Name == "v1" {
+				name = ast.NewIdent(prefix + strconv.Itoa(i) + strconv.Itoa(j))
+			}
+			names = append(names, ast.NewIdent(name.Name))
+		}
+		if len(names) == 0 && prefix != "" {
+			names = []*ast.Ident{ast.NewIdent(prefix + strconv.Itoa(i))}
+		}
+		out.List = append(out.List, &ast.Field{Names: names, Type: g.qualify(f, field.Type)})
+	}
+	return out
+}
+
+// qualify a copy of the type expression `expr`: the types of v1 stay unqualified, they are aliased,
+// the imports it uses are added to the ones of facade.go
+func (g *generator) qualify(f sourceFile, expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if g.types[e.Name] && !e.IsExported() {
+			panic(fmt.Sprintf("%s: unexported type %s", f.name, e.Name))
+		}
+		return ast.NewIdent(e.Name)
+	case *ast.SelectorExpr:
+		pkg := e.X.(*ast.Ident).Name
+		path := importPath(f.file, pkg)
+		if strings.Contains(strings.Split(path, "/")[0], ".") || path[strings.LastIndex(path, "/")+1:] != pkg {
+			panic(fmt.Sprintf("%s: only the standard packages imported by their name are supported, not %s", f.name, path))
+		}
+		g.imports[pkg] = path
+		return &ast.SelectorExpr{X: ast.NewIdent(pkg), Sel: ast.NewIdent(e.Sel.Name)}
+	case *ast.StarExpr:
+		return &ast.StarExpr{X: g.qualify(f, e.X)}
+	case *ast.Ellipsis:
+		return &ast.Ellipsis{Elt: g.qualify(f, e.Elt)}
+	case *ast.ArrayType:
+		return &ast.ArrayType{Len: e.Len, Elt: g.qualify(f, e.Elt)}
+	case *ast.MapType:
+		return &ast.MapType{Key: g.qualify(f, e.Key), Value: g.qualify(f, e.Value)}
+	case *ast.ChanType:
+		return &ast.ChanType{Dir: e.Dir, Value: g.qualify(f, e.Value)}
+	case *ast.FuncType:
+		return &ast.FuncType{Params: g.fields(f, e.Params, ""), Results: g.fields(f, e.Results, "")}
+	case *ast.InterfaceType:
+		if e.Methods == nil || len(e.Methods.List) == 0 {
+			return &ast.InterfaceType{Methods: &ast.FieldList{}}
+		}
+	}
+	panic(fmt.Sprintf("%s: unsupported type %T", f.name, expr))
+}
+
+func importPath(f *ast.File, name string) string {
+	for _, spec := range f.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		if spec.Name != nil && spec.Name.Name == name || spec.Name == nil && path[strings.LastIndex(path, "/")+1:] == name {
+			return path
+		}
+	}
+	panic(fmt.Sprintf("no import of %s", name))
+}
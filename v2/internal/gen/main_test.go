@@ -0,0 +1,21 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func Test_generate(t *testing.T) {
+	src, err := generate("../../..", "../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := ioutil.ReadFile("../../facade.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, current) {
+		t.Fatal("facade.go is out of date with the v1 package, run `go generate` in v2")
+	}
+}
@@ -0,0 +1,84 @@
+package gwda
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// WDAVersion a `major.minor.patch` version, e.g. the iOS version of WDASessionInfo.OSVersion
+//
+//	if version, _ := info.OSVersion(); version.Less(WDAVersion{Major: 15}) {
+//		t.Skip("requires iOS 15")
+//	}
+type WDAVersion struct {
+	Major, Minor, Patch int
+}
+
+// ParseWDAVersion parses `15`, `15.4` or `15.4.1`
+func ParseWDAVersion(s string) (version WDAVersion, err error) {
+	parts := strings.Split(strings.TrimSpace(s), ".")
+	if len(parts) > 3 {
+		return WDAVersion{}, fmt.Errorf("invalid version '%s'", s)
+	}
+	numbers := []*int{&version.Major, &version.Minor, &version.Patch}
+	for i := range parts {
+		if *numbers[i], err = strconv.Atoi(parts[i]); err != nil || *numbers[i] < 0 {
+			return WDAVersion{}, fmt.Errorf("invalid version '%s'", s)
+		}
+	}
+	return
+}
+
+func (v WDAVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Compare returns -1, 0 or 1 when `v` is older, the same or newer than `other`
+func (v WDAVersion) Compare(other WDAVersion) int {
+	for _, d := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		switch {
+		case d < 0:
+			return -1
+		case d > 0:
+			return 1
+		}
+	}
+	return 0
+}
+
+// Less whether `v` is older than `other`
+func (v WDAVersion) Less(other WDAVersion) bool {
+	return v.Compare(other) < 0
+}
+
+// AtLeast whether `v` is `major.minor` or newer
+func (v WDAVersion) AtLeast(major, minor int) bool {
+	return v.Compare(WDAVersion{Major: major, Minor: minor}) >= 0
+}
+
+// WDADeviceClass the `device` capability of a session
+type WDADeviceClass string
+
+const (
+	WDADeviceClassUnknown WDADeviceClass = ""
+	WDADeviceClassIPhone  WDADeviceClass = "iphone"
+	WDADeviceClassIPad    WDADeviceClass = "ipad"
+	WDADeviceClassAppleTV WDADeviceClass = "apple tv"
+)
+
+// OSVersion the parsed `sdkVersion` capability, the iOS version of the device
+func (si WDASessionInfo) OSVersion() (WDAVersion, error) {
+	return ParseWDAVersion(si.Capabilities.SdkVersion)
+}
+
+// DeviceClass the parsed `device` capability, WDADeviceClassUnknown for an unexpected one
+func (si WDASessionInfo) DeviceClass() WDADeviceClass {
+	switch class := WDADeviceClass(strings.ToLower(strings.TrimSpace(si.Capabilities.Device))); class {
+	case WDADeviceClassIPhone, WDADeviceClassIPad, WDADeviceClassAppleTV:
+		return class
+	case "tvos", "appletv":
+		return WDADeviceClassAppleTV
+	}
+	return WDADeviceClassUnknown
+}
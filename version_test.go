@@ -0,0 +1,45 @@
+package gwda
+
+import "testing"
+
+func TestParseWDAVersion(t *testing.T) {
+	for s, want := range map[string]WDAVersion{
+		"15":     {Major: 15},
+		"14.5":   {Major: 14, Minor: 5},
+		"16.4.1": {Major: 16, Minor: 4, Patch: 1},
+	} {
+		got, err := ParseWDAVersion(s)
+		checkErr(t, err)
+		if got != want {
+			t.Errorf("ParseWDAVersion(%q) = %v", s, got)
+		}
+	}
+	for _, s := range []string{"", "15.x", "1.2.3.4", "-1"} {
+		if _, err := ParseWDAVersion(s); err == nil {
+			t.Errorf("ParseWDAVersion(%q): expected an error", s)
+		}
+	}
+
+	v := WDAVersion{Major: 14, Minor: 5}
+	if !v.Less(WDAVersion{Major: 15}) || v.Less(WDAVersion{Major: 14, Minor: 4, Patch: 9}) || v.Compare(v) != 0 {
+		t.Error("unexpected comparison")
+	}
+	if !v.AtLeast(14, 5) || v.AtLeast(14, 6) {
+		t.Error("unexpected AtLeast")
+	}
+}
+
+func TestWDASessionInfo_DeviceClass(t *testing.T) {
+	var info WDASessionInfo
+	for device, want := range map[string]WDADeviceClass{
+		"iphone":   WDADeviceClassIPhone,
+		"iPad":     WDADeviceClassIPad,
+		"apple tv": WDADeviceClassAppleTV,
+		"watch":    WDADeviceClassUnknown,
+	} {
+		info.Capabilities.Device = device
+		if got := info.DeviceClass(); got != want {
+			t.Errorf("DeviceClass(%q) = %q", device, got)
+		}
+	}
+}
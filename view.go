@@ -0,0 +1,106 @@
+package gwda
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FindViews
+//
+// Finds the elements (see ElementInfos) and fills `views`, a pointer to a slice of structs,
+// one per element. The fields tagged `wda` receive the attribute of the element:
+//
+//	`wda:"type"`, `wda:"name"`, `wda:"label"`, `wda:"value"`      string
+//	`wda:"rect"`                                                WDARect
+//	`wda:"enabled"`, `wda:"visible"`                            bool
+//	`wda:"element"`                                             *Element
+//
+// e.g. a page object declaring what it reads instead of requesting it
+//
+//	type cellView struct {
+//		Title string  `wda:"label"`
+//		Frame WDARect `wda:"rect"`
+//	}
+//	var cells []cellView
+//	err := s.FindViews(WDALocator{ClassName: WDAElementType{Cell: true}}, &cells)
+//
+// The module supports Go versions without type parameters, hence the reflection.
+func (s *Session) FindViews(wdaLocator WDALocator, views interface{}) (err error) {
+	rv := reflect.ValueOf(views)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice || rv.Elem().Type().Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("views: expected a pointer to a slice of structs, got %T", views)
+	}
+	var infos []WDAElementInfo
+	if infos, err = s.ElementInfos(wdaLocator); err != nil {
+		return err
+	}
+	slice := reflect.MakeSlice(rv.Elem().Type(), len(infos), len(infos))
+	for i := range infos {
+		if err = fillView(slice.Index(i), infos[i]); err != nil {
+			return err
+		}
+	}
+	rv.Elem().Set(slice)
+	return nil
+}
+
+// FindView
+//
+// like FindViews, fills the struct `view` points to with the first element
+func (s *Session) FindView(wdaLocator WDALocator, view interface{}) (err error) {
+	rv := reflect.ValueOf(view)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("view: expected a pointer to a struct, got %T", view)
+	}
+	var infos []WDAElementInfo
+	if infos, err = s.ElementInfos(wdaLocator); err != nil {
+		return err
+	}
+	return fillView(rv.Elem(), infos[0])
+}
+
+func fillView(view reflect.Value, info WDAElementInfo) error {
+	viewType := view.Type()
+	for i := 0; i < viewType.NumField(); i++ {
+		field := viewType.Field(i)
+		attr, ok := field.Tag.Lookup("wda")
+		if !ok || attr == "-" {
+			continue
+		}
+		value, ok := viewAttribute(info, attr)
+		if !ok {
+			return fmt.Errorf("%s.%s: unknown attribute '%s'", viewType.Name(), field.Name, attr)
+		}
+		if field.PkgPath != "" {
+			return fmt.Errorf("%s.%s: unexported field", viewType.Name(), field.Name)
+		}
+		if !value.Type().AssignableTo(field.Type) {
+			return fmt.Errorf("%s.%s: '%s' is a %s, not %s", viewType.Name(), field.Name, attr, value.Type(), field.Type)
+		}
+		view.Field(i).Set(value)
+	}
+	return nil
+}
+
+func viewAttribute(info WDAElementInfo, attr string) (reflect.Value, bool) {
+	switch attr {
+	case "type":
+		return reflect.ValueOf(info.Type), true
+	case "name":
+		return reflect.ValueOf(info.Name), true
+	case "label":
+		return reflect.ValueOf(info.Label), true
+	case "value":
+		return reflect.ValueOf(info.Value), true
+	case "rect":
+		return reflect.ValueOf(info.Rect), true
+	case "enabled":
+		return reflect.ValueOf(info.IsEnabled), true
+	case "visible":
+		return reflect.ValueOf(info.IsVisible), true
+	case "element":
+		return reflect.ValueOf(info.Element), true
+	default:
+		return reflect.Value{}, false
+	}
+}
@@ -0,0 +1,52 @@
+package gwda
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_fillView(t *testing.T) {
+	info := WDAElementInfo{Element: newElement(nil, nil, "E1"), Type: "XCUIElementTypeCell", Label: "Wi-Fi", Value: "On",
+		Rect: WDARect{WDACoordinate{X: 0, Y: 100}, WDASize{Width: 375, Height: 44}}, IsEnabled: true}
+
+	var view struct {
+		Title   string   `wda:"label"`
+		State   string   `wda:"value"`
+		Frame   WDARect  `wda:"rect"`
+		Enabled bool     `wda:"enabled"`
+		Elem    *Element `wda:"element"`
+		Note    string
+	}
+	checkErr(t, fillView(reflect.ValueOf(&view).Elem(), info))
+	if view.Title != "Wi-Fi" || view.State != "On" || view.Frame.Y != 100 || !view.Enabled || view.Elem.UID != "E1" || view.Note != "" {
+		t.Fatalf("%+v", view)
+	}
+
+	var wrongType struct {
+		Enabled string `wda:"enabled"`
+	}
+	if err := fillView(reflect.ValueOf(&wrongType).Elem(), info); err == nil {
+		t.Fatal("expected an error for a field of the wrong type")
+	}
+	var unknown struct {
+		Hint string `wda:"hint"`
+	}
+	if err := fillView(reflect.ValueOf(&unknown).Elem(), info); err == nil {
+		t.Fatal("expected an error for an unknown attribute")
+	}
+}
+
+func TestSession_FindViews(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	checkErr(t, s.AppLaunch("com.apple.Preferences"))
+
+	var cells []struct {
+		Title string  `wda:"label"`
+		Frame WDARect `wda:"rect"`
+	}
+	checkErr(t, s.FindViews(WDALocator{ClassName: WDAElementType{Cell: true}}, &cells))
+	t.Log(cells)
+}
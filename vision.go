@@ -0,0 +1,264 @@
+package gwda
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// ImageMatcher
+//
+// finds `template` in `screen`, both in pixels.
+// `confidence` is in range [0.0, 1.0], where 1.0 means identical.
+//
+// e.g. an OpenCV based implementation can replace DefaultImageMatcher
+type ImageMatcher interface {
+	Match(screen, template image.Image) (rect image.Rectangle, confidence float64, err error)
+}
+
+// DefaultImageMatcher used by the image based APIs of Session
+var DefaultImageMatcher ImageMatcher = SADImageMatcher{}
+
+// ErrImageNotPresent the best match is below the threshold
+var ErrImageNotPresent = errors.New("image not present")
+
+// WDAImageMatch
+type WDAImageMatch struct {
+	Rect       WDARect         // in points, usable for Tap
+	PixelRect  image.Rectangle // in screenshot pixels
+	Confidence float64
+}
+
+// AssertImagePresent
+//
+// Takes a screenshot and looks for `template` (cut from a screenshot of the same device, so in pixels) with the ImageMatcher of the session.
+// Nothing is tapped, meant for brand assets, map pins and anything without accessibility representation.
+//
+// `threshold` is the minimum confidence, e.g. `0.95`.
+// When the best match is below it, the match is returned together with ErrImageNotPresent.
+func (s *Session) AssertImagePresent(template image.Image, threshold float64) (match WDAImageMatch, err error) {
+	if match, err = s.matchImage(template); err != nil {
+		return WDAImageMatch{}, err
+	}
+	if match.Confidence < threshold {
+		return match, fmt.Errorf("%w: best confidence %.4f < %.4f at %v", ErrImageNotPresent, match.Confidence, threshold, match.Rect)
+	}
+	return match, nil
+}
+
+// SetImageMatcher
+//
+// replaces DefaultImageMatcher for this session, `nil` restores it
+func (s *Session) SetImageMatcher(matcher ImageMatcher) {
+	s.imageMatcher.Store(imageMatcherValue{matcher})
+}
+
+// imageMatcherValue the implementations of ImageMatcher differ in type, atomic.Value needs a consistent one
+type imageMatcherValue struct {
+	ImageMatcher
+}
+
+func (s *Session) getImageMatcher() ImageMatcher {
+	if v, _ := s.imageMatcher.Load().(imageMatcherValue); v.ImageMatcher != nil {
+		return v.ImageMatcher
+	}
+	return DefaultImageMatcher
+}
+
+// WDAImageElement
+//
+// An area of the screen, matching a template (FindByImage) or containing a text (FindTextOnScreen).
+// It is not an element of WDA: there is no UID, it is only a position which may be tapped.
+type WDAImageElement struct {
+	session *Session
+	match   WDAImageMatch
+}
+
+// Rect in points
+func (e *WDAImageElement) Rect() WDARect {
+	return e.match.Rect
+}
+
+func (e *WDAImageElement) Confidence() float64 {
+	return e.match.Confidence
+}
+
+// Center in points
+func (e *WDAImageElement) Center() (x, y float64) {
+	return e.match.Rect.CenterFloat()
+}
+
+// Tap the center
+func (e *WDAImageElement) Tap() error {
+	return e.session.TapFloat(e.Center())
+}
+
+// FindByImage
+//
+// Takes a screenshot and looks for `template` (in pixels, see AssertImagePresent) with the ImageMatcher of the session
+// (DefaultImageMatcher unless SetImageMatcher), for games and custom rendered UIs without accessibility tree.
+//
+// When the best match is below `threshold`, the error wraps ErrImageNotPresent.
+//
+//	btnPlay, err := s.FindByImage(template, 0.9)
+//	err = btnPlay.Tap()
+func (s *Session) FindByImage(template image.Image, threshold float64) (element *WDAImageElement, err error) {
+	var match WDAImageMatch
+	if match, err = s.AssertImagePresent(template, threshold); err != nil {
+		return nil, err
+	}
+	return &WDAImageElement{session: s, match: match}, nil
+}
+
+func (s *Session) matchImage(template image.Image) (match WDAImageMatch, err error) {
+	var screen image.Image
+	if screen, _, err = s.ScreenshotToImage(); err != nil {
+		return WDAImageMatch{}, err
+	}
+	var scale float64
+	if scale, err = s.Scale(); err != nil {
+		return WDAImageMatch{}, err
+	}
+	if match.PixelRect, match.Confidence, err = s.getImageMatcher().Match(screen, template); err != nil {
+		return WDAImageMatch{}, err
+	}
+	match.Rect = RectFromPixels(match.PixelRect, scale)
+	return
+}
+
+// SADImageMatcher
+//
+// Pure Go template matching by the mean absolute difference of gray levels.
+// Searches a downscaled copy first and refines the best candidates in full resolution,
+// it does not handle rotation or a different scale of the template.
+type SADImageMatcher struct{}
+
+func (SADImageMatcher) Match(screen, template image.Image) (rect image.Rectangle, confidence float64, err error) {
+	sg, tg := newGrayPlane(screen), newGrayPlane(template)
+	if tg.w == 0 || tg.h == 0 {
+		return image.Rectangle{}, 0, errors.New("empty template")
+	}
+	if tg.w > sg.w || tg.h > sg.h {
+		return image.Rectangle{}, 0, fmt.Errorf("template %dx%d is larger than the screen %dx%d", tg.w, tg.h, sg.w, sg.h)
+	}
+
+	factor := tg.w
+	if tg.h < factor {
+		factor = tg.h
+	}
+	factor /= 16
+	if factor < 1 {
+		factor = 1
+	}
+
+	candidates := sg.downscale(factor).bestPositions(tg.downscale(factor), 5)
+
+	best := math.MaxFloat64
+	var bestX, bestY int
+	for _, c := range candidates {
+		for y := c.y*factor - factor; y <= c.y*factor+factor; y++ {
+			for x := c.x*factor - factor; x <= c.x*factor+factor; x++ {
+				if x < 0 || y < 0 || x+tg.w > sg.w || y+tg.h > sg.h {
+					continue
+				}
+				if diff := sg.meanAbsDiff(tg, x, y, best); diff < best {
+					best, bestX, bestY = diff, x, y
+				}
+			}
+		}
+	}
+
+	bounds := screen.Bounds()
+	rect = image.Rect(bestX, bestY, bestX+tg.w, bestY+tg.h).Add(bounds.Min)
+	return rect, 1 - best/255, nil
+}
+
+type grayPlane struct {
+	w, h int
+	pix  []float64
+}
+
+func newGrayPlane(img image.Image) *grayPlane {
+	b := img.Bounds()
+	g := &grayPlane{w: b.Dx(), h: b.Dy(), pix: make([]float64, b.Dx()*b.Dy())}
+	for y := 0; y < g.h; y++ {
+		for x := 0; x < g.w; x++ {
+			g.pix[y*g.w+x] = float64(color.GrayModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.Gray).Y)
+		}
+	}
+	return g
+}
+
+// downscale box filter
+func (g *grayPlane) downscale(factor int) *grayPlane {
+	if factor <= 1 {
+		return g
+	}
+	d := &grayPlane{w: g.w / factor, h: g.h / factor}
+	d.pix = make([]float64, d.w*d.h)
+	area := float64(factor * factor)
+	for y := 0; y < d.h; y++ {
+		for x := 0; x < d.w; x++ {
+			var sum float64
+			for yy := y * factor; yy < (y+1)*factor; yy++ {
+				row := g.pix[yy*g.w:]
+				for xx := x * factor; xx < (x+1)*factor; xx++ {
+					sum += row[xx]
+				}
+			}
+			d.pix[y*d.w+x] = sum / area
+		}
+	}
+	return d
+}
+
+// meanAbsDiff of `t` placed at (x, y), gives up once it exceeds `limit`
+func (g *grayPlane) meanAbsDiff(t *grayPlane, x, y int, limit float64) float64 {
+	n := float64(t.w * t.h)
+	maxSum := limit * n
+	var sum float64
+	for ty := 0; ty < t.h; ty++ {
+		row := g.pix[(y+ty)*g.w+x:]
+		tRow := t.pix[ty*t.w:]
+		for tx := 0; tx < t.w; tx++ {
+			sum += math.Abs(row[tx] - tRow[tx])
+		}
+		if sum > maxSum {
+			return math.MaxFloat64
+		}
+	}
+	return sum / n
+}
+
+type matchPosition struct {
+	x, y int
+	diff float64
+}
+
+// bestPositions the `n` positions with the lowest difference
+func (g *grayPlane) bestPositions(t *grayPlane, n int) []matchPosition {
+	positions := make([]matchPosition, 0, n+1)
+	limit := math.MaxFloat64
+	for y := 0; y+t.h <= g.h; y++ {
+		for x := 0; x+t.w <= g.w; x++ {
+			diff := g.meanAbsDiff(t, x, y, limit)
+			if diff == math.MaxFloat64 {
+				continue
+			}
+			positions = append(positions, matchPosition{x: x, y: y, diff: diff})
+			sort.Slice(positions, func(i, j int) bool {
+				return positions[i].diff < positions[j].diff
+			})
+			if len(positions) > n {
+				positions = positions[:n]
+			}
+			if len(positions) == n {
+				limit = positions[n-1].diff
+			}
+		}
+	}
+	return positions
+}
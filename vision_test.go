@@ -0,0 +1,88 @@
+package gwda
+
+import (
+	"image"
+	"image/draw"
+	"math/rand"
+	"testing"
+)
+
+func TestSADImageMatcher_Match(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	screen := image.NewRGBA(image.Rect(0, 0, 750, 1334))
+	for i := range screen.Pix {
+		screen.Pix[i] = uint8(r.Intn(256))
+	}
+	template := image.NewRGBA(image.Rect(0, 0, 96, 64))
+	draw.Draw(template, template.Bounds(), screen, image.Pt(321, 987), draw.Src)
+
+	rect, confidence, err := SADImageMatcher{}.Match(screen, template)
+	checkErr(t, err)
+	if rect != image.Rect(321, 987, 321+96, 987+64) || confidence != 1 {
+		t.Fatal(rect, confidence)
+	}
+
+	_, confidence, err = SADImageMatcher{}.Match(screen, image.NewRGBA(image.Rect(0, 0, 40, 40)))
+	checkErr(t, err)
+	if confidence > 0.9 {
+		t.Fatal("unexpected match:", confidence)
+	}
+}
+
+func TestSession_AssertImagePresent(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	screen, _, err := s.ScreenshotToImage()
+	checkErr(t, err)
+	template := image.NewRGBA(image.Rect(0, 0, 120, 120))
+	draw.Draw(template, template.Bounds(), screen, screen.Bounds().Min.Add(image.Pt(60, 200)), draw.Src)
+
+	WDADebug(true)
+	match, err := s.AssertImagePresent(template, 0.95)
+	checkErr(t, err)
+	t.Log(match.Confidence, match.Rect, match.PixelRect)
+}
+
+type fixedImageMatcher struct{}
+
+func (fixedImageMatcher) Match(screen, template image.Image) (image.Rectangle, float64, error) {
+	return image.Rect(10, 20, 30, 60), 1, nil
+}
+
+func TestSession_SetImageMatcher(t *testing.T) {
+	s := &Session{}
+	if _, ok := s.getImageMatcher().(SADImageMatcher); !ok {
+		t.Fatal("expected DefaultImageMatcher")
+	}
+	s.SetImageMatcher(fixedImageMatcher{})
+	if _, ok := s.getImageMatcher().(fixedImageMatcher); !ok {
+		t.Fatal("expected the matcher of the session")
+	}
+	s.SetImageMatcher(nil)
+	if _, ok := s.getImageMatcher().(SADImageMatcher); !ok {
+		t.Fatal("expected DefaultImageMatcher again")
+	}
+
+	elem := &WDAImageElement{match: WDAImageMatch{Rect: RectFromPixels(image.Rect(10, 20, 30, 60), 2)}}
+	if x, y := elem.Center(); x != 10 || y != 20 {
+		t.Fatal(x, y)
+	}
+}
+
+func TestSession_FindByImage(t *testing.T) {
+	c, err := NewClient(deviceURL)
+	checkErr(t, err)
+	s, err := c.NewSession()
+	checkErr(t, err)
+	screen, _, err := s.ScreenshotToImage()
+	checkErr(t, err)
+	template := image.NewRGBA(image.Rect(0, 0, 120, 120))
+	draw.Draw(template, template.Bounds(), screen, screen.Bounds().Min.Add(image.Pt(60, 200)), draw.Src)
+
+	elem, err := s.FindByImage(template, 0.95)
+	checkErr(t, err)
+	t.Log(elem.Confidence(), elem.Rect())
+	checkErr(t, elem.Tap())
+}